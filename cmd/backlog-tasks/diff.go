@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/miyanaga/backlog-exporter/internal/config"
+	"github.com/miyanaga/backlog-exporter/internal/exporter"
+)
+
+// runDiff は2つのJSONエクスポートの差分を出力する
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+
+	var (
+		format   string
+		output   string
+		showHelp bool
+	)
+	fs.StringVar(&format, "format", "txt", "Output format (txt, markdown, json)")
+	fs.StringVar(&format, "f", "txt", "Output format (shorthand)")
+	fs.StringVar(&output, "output", "", "Write the diff to a file instead of stdout")
+	fs.StringVar(&output, "o", "", "Write the diff to a file (shorthand)")
+	fs.BoolVar(&showHelp, "help", false, "Show help")
+	fs.BoolVar(&showHelp, "h", false, "Show help (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: backlog-tasks diff [options] <old.json> <new.json>\n\n")
+		fmt.Fprintf(os.Stderr, "Show changes between two JSON exports.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -f, --format     Output format: txt, markdown, json (default: txt)\n")
+		fmt.Fprintf(os.Stderr, "  -o, --output     Write the diff to a file instead of stdout\n")
+		fmt.Fprintf(os.Stderr, "  -h, --help       Show this help message\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  backlog-tasks diff MYPROJ_tasks_20241126_090000.json MYPROJ_tasks_20241127_090000.json -f markdown\n")
+	}
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return ExitSuccess
+		}
+		return ExitInvalidArgs
+	}
+	if showHelp {
+		fs.Usage()
+		return ExitSuccess
+	}
+	if len(files) != 2 {
+		fmt.Fprintf(os.Stderr, "Error: diff requires two JSON files\n\n")
+		fs.Usage()
+		return ExitInvalidArgs
+	}
+
+	oldSnap, err := exporter.LoadSnapshot(files[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+	newSnap, err := exporter.LoadSnapshot(files[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+
+	content, err := exporter.FormatDiff(exporter.DiffSnapshots(oldSnap, newSnap), config.OutputFormat(format))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+
+	if output == "" {
+		os.Stdout.Write(content)
+		return ExitSuccess
+	}
+	if err := os.WriteFile(output, content, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write file: %s\n", err)
+		return ExitOutputDirError
+	}
+	return ExitSuccess
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/miyanaga/backlog-exporter/internal/config"
+	"github.com/miyanaga/backlog-exporter/internal/exporter"
+)
+
+// runExport は課題をエクスポートしてファイルに出力する
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+
+	var (
+		conn            connectionFlags
+		filters         exportFlags
+		output          string
+		outputFile      string
+		withAttachments bool
+		incremental     bool
+		showHelp        bool
+		showVersion     bool
+	)
+	conn.register(fs)
+	filters.register(fs)
+	fs.StringVar(&output, "output", "", "Output directory, or - to write the report to stdout (default: ./)")
+	fs.StringVar(&output, "o", "", "Output directory (shorthand)")
+	fs.StringVar(&outputFile, "output-file", "", "Write the report to this file path")
+	fs.BoolVar(&withAttachments, "with-attachments", false, "Download issue attachments into the output directory")
+	fs.BoolVar(&incremental, "incremental", false, "Fetch only issues updated since the last run and merge them into the previous result")
+	fs.BoolVar(&showHelp, "help", false, "Show help")
+	fs.BoolVar(&showHelp, "h", false, "Show help (shorthand)")
+	fs.BoolVar(&showVersion, "version", false, "Show version")
+	fs.BoolVar(&showVersion, "v", false, "Show version (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: backlog-tasks [export] [options]\n")
+		fmt.Fprintf(os.Stderr, "       backlog-tasks <command> [options]\n\n")
+		fmt.Fprintf(os.Stderr, "A CLI tool to export incomplete tasks from Backlog.\n\n")
+		printCommands()
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		conn.printUsage()
+		filters.printUsage()
+		fmt.Fprintf(os.Stderr, "  -o, --output     Output directory, or - to write the report to stdout (default: ./)\n")
+		fmt.Fprintf(os.Stderr, "      --output-file Write the report to this file path instead of an auto-named file\n")
+		fmt.Fprintf(os.Stderr, "      --with-attachments Save attachments under <output>/<ISSUEKEY>/attachments/\n")
+		fmt.Fprintf(os.Stderr, "      --incremental Fetch only issues updated since the last run\n")
+		fmt.Fprintf(os.Stderr, "  -h, --help       Show this help message\n")
+		fmt.Fprintf(os.Stderr, "  -v, --version    Show version\n\n")
+		fmt.Fprintf(os.Stderr, "Environment variables:\n")
+		fmt.Fprintf(os.Stderr, "  BACKLOG_API_KEY  Backlog API key\n")
+		fmt.Fprintf(os.Stderr, "  BACKLOG_SPACE    Backlog space ID\n")
+		fmt.Fprintf(os.Stderr, "  BACKLOG_DOMAIN   Backlog domain\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  # Export to Markdown\n")
+		fmt.Fprintf(os.Stderr, "  backlog-tasks -s mycompany -p MYPROJ -f markdown\n\n")
+		fmt.Fprintf(os.Stderr, "  # Export with API key\n")
+		fmt.Fprintf(os.Stderr, "  backlog-tasks -k YOUR_API_KEY -s mycompany -p MYPROJ\n\n")
+		fmt.Fprintf(os.Stderr, "  # Pipe JSON to another command (progress goes to stderr)\n")
+		fmt.Fprintf(os.Stderr, "  backlog-tasks -s mycompany -p MYPROJ -f json -o - | jq '.issues[].issueKey'\n\n")
+		fmt.Fprintf(os.Stderr, "  # Export several projects into one report\n")
+		fmt.Fprintf(os.Stderr, "  backlog-tasks -s mycompany -p MYPROJ,OTHER --combined -f markdown\n\n")
+		fmt.Fprintf(os.Stderr, "  # Exclude custom closed statuses\n")
+		fmt.Fprintf(os.Stderr, "  backlog-tasks -s mycompany -p MYPROJ --exclude-status Closed --exclude-status \"Won't fix\"\n\n")
+		fmt.Fprintf(os.Stderr, "  # Use a profile from the config file\n")
+		fmt.Fprintf(os.Stderr, "  backlog-tasks --profile client-a\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitSuccess
+		}
+		return ExitInvalidArgs
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected argument %q\n", fs.Arg(0))
+		return ExitInvalidArgs
+	}
+
+	if showHelp {
+		fs.Usage()
+		return ExitSuccess
+	}
+
+	if showVersion {
+		printVersion()
+		return ExitSuccess
+	}
+
+	// 設定ファイル < 環境変数 < コマンドライン引数 の順にマージ
+	cmdCfg := filters.config()
+	cmdCfg.Output = output
+	cmdCfg.OutputFile = outputFile
+	cmdCfg.WithAttachments = withAttachments
+	cmdCfg.Incremental = incremental
+
+	cfg, err := conn.load(cmdCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+
+	// バリデーション
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return validationExitCode(err)
+	}
+
+	if err := exporter.ValidateCSVColumns(cfg.Columns); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+
+	if err := exporter.ValidateWhere(cfg.Where); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+
+	if cfg.Format == config.FormatTemplate {
+		if err := exporter.ValidateTemplate(cfg.Template); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return ExitInvalidArgs
+		}
+	}
+
+	// 出力ディレクトリの確認（--output-file ではファイルのディレクトリ）
+	if cfg.Output != config.OutputStdout {
+		if _, err := os.Stat(cfg.OutputDir()); os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Error: Cannot write to directory '%s'\n", cfg.OutputDir())
+			return ExitOutputDirError
+		}
+	}
+
+	// エクスポーターの作成と実行
+	exp := exporter.NewExporter(newClient(cfg), cfg)
+	ctx := context.Background()
+
+	if _, err := exp.RunAll(ctx); err != nil {
+		// エラーの種類に応じて終了コードを設定
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return classifyError(err)
+	}
+
+	return ExitSuccess
+}
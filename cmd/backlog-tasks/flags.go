@@ -0,0 +1,265 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+	"github.com/miyanaga/backlog-exporter/internal/exporter"
+)
+
+// stringListFlag は繰り返し指定できる文字列フラグ
+type stringListFlag []string
+
+func (s *stringListFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringListFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// commaListFlag は繰り返し指定またはカンマ区切りで複数の値を受け付けるフラグ
+type commaListFlag []string
+
+func (s *commaListFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *commaListFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*s = append(*s, v)
+		}
+	}
+	return nil
+}
+
+// parseInterspersed はフラグと位置引数が混在した引数を解析し、位置引数を返す
+// 標準の flag パッケージは最初の位置引数で解析を止めるため、残りを繰り返し解析する
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// connectionFlags はAPIへの接続設定と設定ファイルのフラグ（全サブコマンド共通）
+type connectionFlags struct {
+	apiKey     string
+	space      string
+	domain     string
+	maxRetries int
+	configPath string
+	profile    string
+}
+
+func (f *connectionFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.apiKey, "api-key", "", "Backlog API key")
+	fs.StringVar(&f.apiKey, "k", "", "Backlog API key (shorthand)")
+	fs.StringVar(&f.space, "space", "", "Backlog space ID (e.g., mycompany)")
+	fs.StringVar(&f.space, "s", "", "Backlog space ID (shorthand)")
+	fs.StringVar(&f.domain, "domain", "", "Backlog domain (backlog.com, backlog.jp, backlogtool.com)")
+	fs.StringVar(&f.domain, "d", "", "Backlog domain (shorthand)")
+	fs.IntVar(&f.maxRetries, "max-retries", -1, "Maximum retries for rate-limited or failed requests")
+	fs.StringVar(&f.configPath, "config", "", "Path to the config file (default: ~/.config/backlog-exporter/config.yaml)")
+	fs.StringVar(&f.profile, "profile", "", "Profile name in the config file")
+}
+
+// printUsage は接続設定のオプションの説明を表示する
+func (f *connectionFlags) printUsage() {
+	fmt.Fprintf(os.Stderr, "  -k, --api-key    Backlog API key (or set BACKLOG_API_KEY)\n")
+	fmt.Fprintf(os.Stderr, "  -s, --space      Backlog space ID (required)\n")
+	fmt.Fprintf(os.Stderr, "  -d, --domain     Backlog domain (default: backlog.com)\n")
+	fmt.Fprintf(os.Stderr, "      --max-retries Maximum retries on rate limit or server errors (default: 5)\n")
+	fmt.Fprintf(os.Stderr, "      --config     Config file (default: ~/.config/backlog-exporter/config.yaml)\n")
+	fmt.Fprintf(os.Stderr, "      --profile    Profile name in the config file\n")
+}
+
+// load は設定ファイル・環境変数・コマンドライン引数の順に設定をマージする
+// cmdCfg にはサブコマンド固有のコマンドライン引数を設定しておく
+func (f *connectionFlags) load(cmdCfg *config.Config) (*config.Config, error) {
+	cfg, err := loadConfigFile(f.configPath, f.profile)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Merge(config.LoadFromEnv())
+
+	cmdCfg.APIKey = f.apiKey
+	cmdCfg.Space = f.space
+	cmdCfg.Domain = f.domain
+	if f.maxRetries >= 0 {
+		maxRetries := f.maxRetries
+		cmdCfg.MaxRetries = &maxRetries
+	}
+	cfg.Merge(cmdCfg)
+
+	return cfg, nil
+}
+
+// newClient は設定からAPIクライアントを作成する
+func newClient(cfg *config.Config) *backlog.APIClient {
+	client := backlog.NewClient(cfg.Space, cfg.Domain, cfg.APIKey)
+	if cfg.MaxRetries != nil {
+		policy := backlog.DefaultRetryPolicy()
+		policy.MaxRetries = *cfg.MaxRetries
+		client.SetRetryPolicy(policy)
+	}
+	return client
+}
+
+// exportFlags は課題の取得条件と出力形式のフラグ（export・serve 共通）
+type exportFlags struct {
+	projects        commaListFlag
+	allProjects     bool
+	combined        bool
+	concurrency     int
+	format          string
+	template        string
+	assignees       stringListFlag
+	withComments    bool
+	withParents     bool
+	includeStatuses stringListFlag
+	excludeStatuses stringListFlag
+	allStatuses     bool
+	issueTypes      stringListFlag
+	categories      stringListFlag
+	milestones      stringListFlag
+	versions        stringListFlag
+	priorities      stringListFlag
+	keyword         string
+	createdSince    string
+	createdUntil    string
+	updatedSince    string
+	updatedUntil    string
+	dueSince        string
+	dueUntil        string
+	parentChild     string
+	customFields    stringListFlag
+	where           string
+	groupBy         commaListFlag
+	sort            commaListFlag
+	bom             bool
+	columns         commaListFlag
+}
+
+func (f *exportFlags) register(fs *flag.FlagSet) {
+	fs.Var(&f.projects, "project", "Project ID or project key (repeatable or comma-separated)")
+	fs.Var(&f.projects, "p", "Project ID or project key (shorthand)")
+	fs.BoolVar(&f.allProjects, "all-projects", false, "Export every project the API key can access")
+	fs.BoolVar(&f.combined, "combined", false, "Write multiple projects into one combined report")
+	fs.IntVar(&f.concurrency, "concurrency", 0, "Number of projects fetched concurrently (default: 4)")
+	fs.StringVar(&f.format, "format", "", "Output format (txt, json, markdown, csv, tsv, xlsx, html, ndjson, sqlite, template)")
+	fs.StringVar(&f.format, "f", "", "Output format (shorthand)")
+	fs.StringVar(&f.template, "template", "", "Template file or built-in template name (txt, markdown) for -f template")
+	fs.Var(&f.groupBy, "group-by", "Comma-separated grouping: milestone, category, version, assignee, status, priority, issueType, dueWeek")
+	fs.Var(&f.sort, "sort", "Comma-separated sort keys: dueDate, priority, updated, status, assignee, keyId (append :desc for descending)")
+	fs.BoolVar(&f.bom, "bom", false, "Prepend a UTF-8 BOM to CSV/TSV output (for Excel)")
+	fs.Var(&f.columns, "columns", "Comma-separated issue fields for CSV/TSV output")
+	fs.Var(&f.assignees, "assignee", "Assignee: me, none, login ID, name, mail address or user ID (repeatable)")
+	fs.Var(&f.assignees, "a", "Assignee (shorthand)")
+	fs.Var(&f.includeStatuses, "include-status", "Status name or ID to include (repeatable)")
+	fs.Var(&f.excludeStatuses, "exclude-status", "Status name or ID to exclude (repeatable)")
+	fs.BoolVar(&f.allStatuses, "all-statuses", false, "Include issues in every status, including completed ones")
+	fs.Var(&f.issueTypes, "issue-type", "Issue type name or ID to include (repeatable)")
+	fs.Var(&f.categories, "category", "Category name or ID to include (repeatable)")
+	fs.Var(&f.milestones, "milestone", "Milestone name or ID to include (repeatable)")
+	fs.Var(&f.versions, "affected-version", "Affected version name or ID to include (repeatable)")
+	fs.Var(&f.priorities, "priority", "Priority name or ID to include (repeatable)")
+	fs.StringVar(&f.keyword, "keyword", "", "Keyword in the summary, description or comments")
+	fs.StringVar(&f.createdSince, "created-since", "", "Include issues created on or after the date (YYYY-MM-DD)")
+	fs.StringVar(&f.createdUntil, "created-until", "", "Include issues created on or before the date (YYYY-MM-DD)")
+	fs.StringVar(&f.updatedSince, "updated-since", "", "Include issues updated on or after the date (YYYY-MM-DD)")
+	fs.StringVar(&f.updatedUntil, "updated-until", "", "Include issues updated on or before the date (YYYY-MM-DD)")
+	fs.StringVar(&f.dueSince, "due-since", "", "Include issues due on or after the date (YYYY-MM-DD)")
+	fs.StringVar(&f.dueUntil, "due-until", "", "Include issues due on or before the date (YYYY-MM-DD)")
+	fs.StringVar(&f.parentChild, "parent-child", "", "Parent/child filter (all, not-child, child, standalone, parent)")
+	fs.Var(&f.customFields, "custom-field", "Custom field condition: NAME=VALUE, NAME>=VALUE or NAME<=VALUE (repeatable)")
+	fs.StringVar(&f.where, "where", "", "Filter expression evaluated on fetched issues (e.g. 'dueDate < today')")
+	fs.BoolVar(&f.withComments, "with-comments", false, "Include issue comments in the output")
+	fs.BoolVar(&f.withParents, "with-parents", false, "Fetch parents of matched issues that are outside the conditions")
+}
+
+// printUsage は取得条件と出力形式のオプションの説明を表示する
+func (f *exportFlags) printUsage() {
+	fmt.Fprintf(os.Stderr, "  -p, --project    Project ID or key (required, repeatable or comma-separated)\n")
+	fmt.Fprintf(os.Stderr, "      --all-projects Export every project the API key can access\n")
+	fmt.Fprintf(os.Stderr, "      --combined   Write multiple projects into one combined report\n")
+	fmt.Fprintf(os.Stderr, "      --concurrency Number of projects fetched concurrently (default: 4)\n")
+	fmt.Fprintf(os.Stderr, "  -f, --format     Output format: txt, json, markdown, csv, tsv, xlsx, html, ndjson, sqlite, template (default: txt)\n")
+	fmt.Fprintf(os.Stderr, "      --template   Template file for -f template, or a built-in template: %s\n", strings.Join(exporter.BuiltinTemplateNames(), ", "))
+	fmt.Fprintf(os.Stderr, "      --group-by   Group issues (comma-separated for nested groups):\n")
+	fmt.Fprintf(os.Stderr, "                   milestone, category, version, assignee, status, priority, issueType, dueWeek\n")
+	fmt.Fprintf(os.Stderr, "      --sort       Sort keys, e.g. priority,dueDate:desc\n")
+	fmt.Fprintf(os.Stderr, "                   Available: dueDate, priority, updated, status, assignee, keyId\n")
+	fmt.Fprintf(os.Stderr, "      --bom        Prepend a UTF-8 BOM to CSV/TSV output (for Excel)\n")
+	fmt.Fprintf(os.Stderr, "      --columns    CSV/TSV columns (default: %s)\n", strings.Join(exporter.DefaultCSVColumns, ","))
+	fmt.Fprintf(os.Stderr, "                   Available: %s,%sNAME (custom field)\n", strings.Join(exporter.CSVColumnNames(), ","), exporter.CustomFieldColumnPrefix)
+	fmt.Fprintf(os.Stderr, "  -a, --assignee   Assignee: me, none (unassigned), login ID, name, mail address or user ID (repeatable)\n")
+	fmt.Fprintf(os.Stderr, "      --include-status Status name or ID to include (repeatable)\n")
+	fmt.Fprintf(os.Stderr, "      --exclude-status Status name or ID to exclude (repeatable, default: 完了)\n")
+	fmt.Fprintf(os.Stderr, "      --all-statuses Include issues in every status\n")
+	fmt.Fprintf(os.Stderr, "      --issue-type Issue type name or ID to include (repeatable)\n")
+	fmt.Fprintf(os.Stderr, "      --category   Category name or ID to include (repeatable)\n")
+	fmt.Fprintf(os.Stderr, "      --milestone  Milestone name or ID to include (repeatable)\n")
+	fmt.Fprintf(os.Stderr, "      --affected-version Affected version name or ID to include (repeatable)\n")
+	fmt.Fprintf(os.Stderr, "      --priority   Priority name or ID to include (repeatable)\n")
+	fmt.Fprintf(os.Stderr, "      --keyword    Keyword in the summary, description or comments\n")
+	fmt.Fprintf(os.Stderr, "      --created-since, --created-until DATE  Created date range (YYYY-MM-DD)\n")
+	fmt.Fprintf(os.Stderr, "      --updated-since, --updated-until DATE  Updated date range (YYYY-MM-DD)\n")
+	fmt.Fprintf(os.Stderr, "      --due-since, --due-until DATE          Due date range (YYYY-MM-DD)\n")
+	fmt.Fprintf(os.Stderr, "      --parent-child Parent/child filter: all, not-child, child, standalone, parent\n")
+	fmt.Fprintf(os.Stderr, "      --custom-field Custom field condition: NAME=VALUE, NAME>=VALUE or NAME<=VALUE (repeatable)\n")
+	fmt.Fprintf(os.Stderr, "      --where      Filter expression, e.g. 'dueDate < today and estimatedHours == null'\n")
+	fmt.Fprintf(os.Stderr, "      --with-comments Include issue comments in the output\n")
+	fmt.Fprintf(os.Stderr, "      --with-parents Fetch out-of-scope parents (even completed ones) as context\n")
+}
+
+// config はフラグの値をコマンドライン引数の設定に変換する
+func (f *exportFlags) config() *config.Config {
+	return &config.Config{
+		Projects:        f.projects,
+		AllProjects:     f.allProjects,
+		Combined:        f.combined,
+		Concurrency:     f.concurrency,
+		Format:          config.OutputFormat(f.format),
+		Template:        f.template,
+		WithComments:    f.withComments,
+		WithParents:     f.withParents,
+		IncludeStatuses: f.includeStatuses,
+		ExcludeStatuses: f.excludeStatuses,
+		AllStatuses:     f.allStatuses,
+		BOM:             f.bom,
+		Columns:         f.columns,
+		Assignees:       f.assignees,
+		IssueTypes:      f.issueTypes,
+		Categories:      f.categories,
+		Milestones:      f.milestones,
+		Versions:        f.versions,
+		Priorities:      f.priorities,
+		Keyword:         f.keyword,
+		CreatedSince:    f.createdSince,
+		CreatedUntil:    f.createdUntil,
+		UpdatedSince:    f.updatedSince,
+		UpdatedUntil:    f.updatedUntil,
+		DueSince:        f.dueSince,
+		DueUntil:        f.dueUntil,
+		ParentChild:     f.parentChild,
+		CustomFields:    f.customFields,
+		Where:           f.where,
+		GroupBy:         f.groupBy,
+		Sort:            f.sort,
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// listCommand は一覧表示系のサブコマンド（projects, statuses, users）の共通処理
+type listCommand struct {
+	name        string
+	description string
+	// needsProject が true の場合は --project で対象のプロジェクトを指定する
+	needsProject bool
+	example      string
+}
+
+// listOptions は一覧表示系のサブコマンドの解析済みの引数
+type listOptions struct {
+	cfg     *config.Config
+	project string
+	json    bool
+}
+
+// parse はフラグを解析して設定を読み込む。終了すべき場合は ok が false
+func (c *listCommand) parse(args []string) (opts *listOptions, code int, ok bool) {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+
+	var (
+		conn     connectionFlags
+		project  string
+		format   string
+		showHelp bool
+	)
+	conn.register(fs)
+	if c.needsProject {
+		fs.StringVar(&project, "project", "", "Project ID or project key")
+		fs.StringVar(&project, "p", "", "Project ID or project key (shorthand)")
+	}
+	fs.StringVar(&format, "format", "txt", "Output format (txt, json)")
+	fs.StringVar(&format, "f", "txt", "Output format (shorthand)")
+	fs.BoolVar(&showHelp, "help", false, "Show help")
+	fs.BoolVar(&showHelp, "h", false, "Show help (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: backlog-tasks %s [options]\n\n", c.name)
+		fmt.Fprintf(os.Stderr, "%s.\n\n", c.description)
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		conn.printUsage()
+		if c.needsProject {
+			fmt.Fprintf(os.Stderr, "  -p, --project    Project ID or key (default: the project in the config file)\n")
+		}
+		fmt.Fprintf(os.Stderr, "  -f, --format     Output format: txt, json (default: txt)\n")
+		fmt.Fprintf(os.Stderr, "  -h, --help       Show this help message\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  %s\n", c.example)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil, ExitSuccess, false
+		}
+		return nil, ExitInvalidArgs, false
+	}
+	if showHelp {
+		fs.Usage()
+		return nil, ExitSuccess, false
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected argument %q\n", fs.Arg(0))
+		return nil, ExitInvalidArgs, false
+	}
+	if format != "txt" && format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: invalid format: %s. Use txt or json\n", format)
+		return nil, ExitInvalidArgs, false
+	}
+
+	cfg, err := conn.load(&config.Config{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return nil, ExitInvalidArgs, false
+	}
+	if err := cfg.ValidateConnection(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return nil, validationExitCode(err), false
+	}
+
+	if c.needsProject && project == "" {
+		// 設定ファイルでプロジェクトが1つだけ指定されていればそれを使う
+		if keys := cfg.ProjectKeys(); len(keys) == 1 {
+			project = keys[0]
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: project is required. Use --project or -p\n")
+			return nil, ExitInvalidArgs, false
+		}
+	}
+
+	return &listOptions{cfg: cfg, project: project, json: format == "json"}, ExitSuccess, true
+}
+
+func runProjects(args []string) int {
+	cmd := &listCommand{
+		name:        "projects",
+		description: "List projects the API key can access",
+		example:     "backlog-tasks projects -s mycompany",
+	}
+	opts, code, ok := cmd.parse(args)
+	if !ok {
+		return code
+	}
+
+	projects, err := newClient(opts.cfg).GetProjects(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return classifyError(err)
+	}
+
+	if opts.json {
+		return printJSON(projects)
+	}
+	rows := [][]string{{"ID", "KEY", "NAME"}}
+	for _, p := range projects {
+		rows = append(rows, []string{strconv.Itoa(p.ID), p.ProjectKey, p.Name})
+	}
+	return printTable(rows)
+}
+
+func runStatuses(args []string) int {
+	cmd := &listCommand{
+		name:         "statuses",
+		description:  "List statuses of a project",
+		needsProject: true,
+		example:      "backlog-tasks statuses -s mycompany -p MYPROJ",
+	}
+	opts, code, ok := cmd.parse(args)
+	if !ok {
+		return code
+	}
+
+	statuses, err := newClient(opts.cfg).GetStatuses(context.Background(), opts.project)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return classifyError(err)
+	}
+
+	if opts.json {
+		return printJSON(statuses)
+	}
+	rows := [][]string{{"ID", "NAME", "COLOR"}}
+	for _, s := range statuses {
+		rows = append(rows, []string{strconv.Itoa(s.ID), s.Name, s.Color})
+	}
+	return printTable(rows)
+}
+
+func runUsers(args []string) int {
+	cmd := &listCommand{
+		name:         "users",
+		description:  "List users of a project",
+		needsProject: true,
+		example:      "backlog-tasks users -s mycompany -p MYPROJ",
+	}
+	opts, code, ok := cmd.parse(args)
+	if !ok {
+		return code
+	}
+
+	users, err := newClient(opts.cfg).GetProjectUsers(context.Background(), opts.project)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return classifyError(err)
+	}
+
+	if opts.json {
+		return printJSON(users)
+	}
+	rows := [][]string{{"ID", "USER ID", "NAME", "MAIL"}}
+	for _, u := range users {
+		rows = append(rows, []string{strconv.Itoa(u.ID), u.UserID, u.Name, u.MailAddress})
+	}
+	return printTable(rows)
+}
+
+// printTable は列を揃えて標準出力に表示する
+func printTable(rows [][]string) int {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		for i, col := range row {
+			if i > 0 {
+				fmt.Fprint(w, "\t")
+			}
+			fmt.Fprint(w, col)
+		}
+		fmt.Fprintln(w)
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+	return ExitSuccess
+}
+
+// printJSON はAPIの応答をJSONで標準出力に表示する
+func printJSON(v interface{}) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+	return ExitSuccess
+}
@@ -1,14 +1,14 @@
 package main
 
 import (
-	"context"
-	"flag"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"strings"
 
 	"github.com/miyanaga/backlog-exporter/internal/backlog"
 	"github.com/miyanaga/backlog-exporter/internal/config"
-	"github.com/miyanaga/backlog-exporter/internal/exporter"
 )
 
 var (
@@ -29,154 +29,126 @@ const (
 	ExitInvalidArgs       = 7
 )
 
-func main() {
-	os.Exit(run())
+// commands はサブコマンドの一覧（ヘルプの表示順）
+var commands = []struct {
+	name        string
+	description string
+}{
+	{"export", "Export incomplete issues to a file (default)"},
+	{"projects", "List projects the API key can access"},
+	{"statuses", "List statuses of a project"},
+	{"users", "List users of a project"},
+	{"diff", "Show changes between two JSON exports"},
+	{"serve", "Serve reports over HTTP"},
+	{"template", "Print a built-in report template for customization"},
+	{"version", "Show version"},
 }
 
-func run() int {
-	// フラグの定義
-	var (
-		apiKey      string
-		space       string
-		domain      string
-		project     string
-		output      string
-		format      string
-		assignee    int
-		showHelp    bool
-		showVersion bool
-	)
-
-	flag.StringVar(&apiKey, "api-key", "", "Backlog API key")
-	flag.StringVar(&apiKey, "k", "", "Backlog API key (shorthand)")
-	flag.StringVar(&space, "space", "", "Backlog space ID (e.g., mycompany)")
-	flag.StringVar(&space, "s", "", "Backlog space ID (shorthand)")
-	flag.StringVar(&domain, "domain", "backlog.com", "Backlog domain (backlog.com, backlog.jp, backlogtool.com)")
-	flag.StringVar(&domain, "d", "backlog.com", "Backlog domain (shorthand)")
-	flag.StringVar(&project, "project", "", "Project ID or project key")
-	flag.StringVar(&project, "p", "", "Project ID or project key (shorthand)")
-	flag.StringVar(&output, "output", "./", "Output directory")
-	flag.StringVar(&output, "o", "./", "Output directory (shorthand)")
-	flag.StringVar(&format, "format", "txt", "Output format (txt, json, markdown)")
-	flag.StringVar(&format, "f", "txt", "Output format (shorthand)")
-	flag.IntVar(&assignee, "assignee", 0, "Filter by assignee user ID")
-	flag.IntVar(&assignee, "a", 0, "Filter by assignee user ID (shorthand)")
-	flag.BoolVar(&showHelp, "help", false, "Show help")
-	flag.BoolVar(&showHelp, "h", false, "Show help (shorthand)")
-	flag.BoolVar(&showVersion, "version", false, "Show version")
-	flag.BoolVar(&showVersion, "v", false, "Show version (shorthand)")
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: backlog-tasks [options]\n\n")
-		fmt.Fprintf(os.Stderr, "A CLI tool to export incomplete tasks from Backlog.\n\n")
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		fmt.Fprintf(os.Stderr, "  -k, --api-key    Backlog API key (or set BACKLOG_API_KEY)\n")
-		fmt.Fprintf(os.Stderr, "  -s, --space      Backlog space ID (required)\n")
-		fmt.Fprintf(os.Stderr, "  -d, --domain     Backlog domain (default: backlog.com)\n")
-		fmt.Fprintf(os.Stderr, "  -p, --project    Project ID or key (required)\n")
-		fmt.Fprintf(os.Stderr, "  -o, --output     Output directory (default: ./)\n")
-		fmt.Fprintf(os.Stderr, "  -f, --format     Output format: txt, json, markdown (default: txt)\n")
-		fmt.Fprintf(os.Stderr, "  -a, --assignee   Filter by assignee user ID\n")
-		fmt.Fprintf(os.Stderr, "  -h, --help       Show this help message\n")
-		fmt.Fprintf(os.Stderr, "  -v, --version    Show version\n\n")
-		fmt.Fprintf(os.Stderr, "Environment variables:\n")
-		fmt.Fprintf(os.Stderr, "  BACKLOG_API_KEY  Backlog API key\n")
-		fmt.Fprintf(os.Stderr, "  BACKLOG_SPACE    Backlog space ID\n")
-		fmt.Fprintf(os.Stderr, "  BACKLOG_DOMAIN   Backlog domain\n\n")
-		fmt.Fprintf(os.Stderr, "Examples:\n")
-		fmt.Fprintf(os.Stderr, "  # Export to Markdown\n")
-		fmt.Fprintf(os.Stderr, "  backlog-tasks -s mycompany -p MYPROJ -f markdown\n\n")
-		fmt.Fprintf(os.Stderr, "  # Export with API key\n")
-		fmt.Fprintf(os.Stderr, "  backlog-tasks -k YOUR_API_KEY -s mycompany -p MYPROJ\n")
+func run(args []string) int {
+	// サブコマンドなし（オプションのみ）の場合は従来どおり export として扱う
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runExport(args)
 	}
 
-	flag.Parse()
-
-	if showHelp {
-		flag.Usage()
+	switch args[0] {
+	case "export":
+		return runExport(args[1:])
+	case "projects":
+		return runProjects(args[1:])
+	case "statuses":
+		return runStatuses(args[1:])
+	case "users":
+		return runUsers(args[1:])
+	case "diff":
+		return runDiff(args[1:])
+	case "serve":
+		return runServe(args[1:])
+	case "template":
+		return runTemplate(args[1:])
+	case "version":
+		printVersion()
 		return ExitSuccess
-	}
-
-	if showVersion {
-		fmt.Printf("backlog-tasks %s (commit: %s, built: %s)\n", version, commit, date)
+	case "help":
+		printUsage()
 		return ExitSuccess
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", args[0])
+		printUsage()
+		return ExitInvalidArgs
 	}
+}
 
-	// 環境変数から設定を読み込み
-	cfg := config.LoadFromEnv()
+// printUsage はサブコマンドの一覧を表示する
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: backlog-tasks [export] [options]\n")
+	fmt.Fprintf(os.Stderr, "       backlog-tasks <command> [options]\n\n")
+	printCommands()
+	fmt.Fprintf(os.Stderr, "\nRun 'backlog-tasks <command> -h' for command options.\n")
+}
 
-	// コマンドライン引数で上書き
-	cmdCfg := &config.Config{
-		APIKey:  apiKey,
-		Space:   space,
-		Domain:  domain,
-		Project: project,
-		Output:  output,
-		Format:  config.OutputFormat(format),
-	}
-	if assignee > 0 {
-		cmdCfg.Assignee = &assignee
+func printCommands() {
+	fmt.Fprintf(os.Stderr, "Commands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.description)
 	}
+}
 
-	cfg.Merge(cmdCfg)
-
-	// バリデーション
-	if err := cfg.Validate(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		return ExitInvalidArgs
-	}
+func printVersion() {
+	fmt.Printf("backlog-tasks %s (commit: %s, built: %s)\n", version, commit, date)
+}
 
-	// 出力ディレクトリの確認
-	if _, err := os.Stat(cfg.Output); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Error: Cannot write to directory '%s'\n", cfg.Output)
-		return ExitOutputDirError
+// loadConfigFile は設定ファイルを読み込む
+// パス未指定でデフォルトの設定ファイルが存在しない場合は空の設定を返す
+func loadConfigFile(path, profile string) (*config.Config, error) {
+	if path == "" {
+		defaultPath, err := config.DefaultConfigPath()
+		if err != nil {
+			if profile != "" {
+				return nil, err
+			}
+			return &config.Config{}, nil
+		}
+		if _, err := os.Stat(defaultPath); errors.Is(err, fs.ErrNotExist) && profile == "" {
+			return &config.Config{}, nil
+		}
+		path = defaultPath
 	}
+	return config.LoadFile(path, profile)
+}
 
-	// APIクライアントの作成
-	client := backlog.NewClient(cfg.Space, cfg.Domain, cfg.APIKey)
-
-	// エクスポーターの作成と実行
-	exp := exporter.NewExporter(client, cfg)
-	ctx := context.Background()
-
-	if _, err := exp.Run(ctx); err != nil {
-		// エラーの種類に応じて終了コードを設定
-		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		return classifyError(err)
+// validationExitCode は設定の検証エラーに対応する終了コードを返す
+func validationExitCode(err error) int {
+	if errors.Is(err, config.ErrAPIKeyRequired) {
+		return ExitAPIKeyRequired
 	}
-
-	return ExitSuccess
+	return ExitInvalidArgs
 }
 
 func classifyError(err error) int {
-	// エラーメッセージに基づいて終了コードを分類
-	errStr := err.Error()
+	// エラーの型に基づいて終了コードを分類
+	var (
+		authErr      *backlog.AuthError
+		notFoundErr  *backlog.NotFoundError
+		rateLimitErr *backlog.RateLimitError
+		networkErr   *backlog.NetworkError
+	)
 
 	switch {
-	case contains(errStr, "API key"):
+	case errors.Is(err, config.ErrAPIKeyRequired):
 		return ExitAPIKeyRequired
-	case contains(errStr, "Authentication failed"), contains(errStr, "401"):
+	case errors.As(err, &authErr):
 		return ExitAuthError
-	case contains(errStr, "not found"), contains(errStr, "404"):
+	case errors.As(err, &notFoundErr):
 		return ExitProjectNotFound
-	case contains(errStr, "connect"), contains(errStr, "network"):
-		return ExitNetworkError
-	case contains(errStr, "rate limit"), contains(errStr, "429"):
+	case errors.As(err, &rateLimitErr):
 		return ExitRateLimitExceeded
+	case errors.As(err, &networkErr):
+		return ExitNetworkError
 	default:
 		return ExitInvalidArgs
 	}
 }
-
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsImpl(s, substr))
-}
-
-func containsImpl(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
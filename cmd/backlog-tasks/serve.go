@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+	"github.com/miyanaga/backlog-exporter/internal/exporter"
+)
+
+// defaultServeAddr は serve のデフォルトの待ち受けアドレス
+const defaultServeAddr = "127.0.0.1:8080"
+
+// contentTypes は出力ファイルの拡張子ごとの Content-Type
+var contentTypes = map[string]string{
+	"txt":    "text/plain; charset=utf-8",
+	"md":     "text/markdown; charset=utf-8",
+	"json":   "application/json; charset=utf-8",
+	"csv":    "text/csv; charset=utf-8",
+	"tsv":    "text/tab-separated-values; charset=utf-8",
+	"html":   "text/html; charset=utf-8",
+	"ndjson": "application/x-ndjson; charset=utf-8",
+	"sqlite": "application/vnd.sqlite3",
+	"xlsx":   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// runServe はリクエストごとに課題を取得してレポートを返すHTTPサーバーを起動する
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+
+	var (
+		conn     connectionFlags
+		filters  exportFlags
+		addr     string
+		showHelp bool
+	)
+	conn.register(fs)
+	filters.register(fs)
+	fs.StringVar(&addr, "addr", defaultServeAddr, "Address to listen on")
+	fs.BoolVar(&showHelp, "help", false, "Show help")
+	fs.BoolVar(&showHelp, "h", false, "Show help (shorthand)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: backlog-tasks serve [options]\n\n")
+		fmt.Fprintf(os.Stderr, "Serve reports over HTTP. Issues are fetched on every request.\n")
+		fmt.Fprintf(os.Stderr, "The query parameters 'project', 'format', 'where', 'group-by' and 'sort' override the options.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "      --addr       Address to listen on (default: %s)\n", defaultServeAddr)
+		conn.printUsage()
+		filters.printUsage()
+		fmt.Fprintf(os.Stderr, "  -h, --help       Show this help message\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  backlog-tasks serve -s mycompany -p MYPROJ\n")
+		fmt.Fprintf(os.Stderr, "  curl 'http://%s/?project=OTHER&format=json'\n", defaultServeAddr)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return ExitSuccess
+		}
+		return ExitInvalidArgs
+	}
+	if showHelp {
+		fs.Usage()
+		return ExitSuccess
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected argument %q\n", fs.Arg(0))
+		return ExitInvalidArgs
+	}
+
+	cfg, err := conn.load(filters.config())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+	// ブラウザで閲覧しやすいよう、フォーマット未指定時はHTMLで返す
+	if cfg.Format == "" {
+		cfg.Format = config.FormatHTML
+	}
+	if err := cfg.ValidateConnection(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return validationExitCode(err)
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newReportHandler(newClient(cfg), cfg),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Serving reports on http://%s/", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+	return ExitSuccess
+}
+
+// reportHandler はリクエストごとにレポートを生成して返す
+type reportHandler struct {
+	client backlog.Client
+	config *config.Config
+}
+
+func newReportHandler(client backlog.Client, cfg *config.Config) http.Handler {
+	return &reportHandler{client: client, config: cfg}
+}
+
+func (h *reportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	start := time.Now()
+	status, err := h.serveReport(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+	}
+	log.Printf("%s %s %d (%s)", r.Method, r.URL.RequestURI(), status, time.Since(start).Round(time.Millisecond))
+}
+
+func (h *reportHandler) serveReport(w http.ResponseWriter, r *http.Request) (int, error) {
+	// リクエストごとに設定を複製し、クエリパラメーターで上書きする
+	cfg := *h.config
+	query := r.URL.Query()
+	if projects := queryList(query, "project"); len(projects) > 0 {
+		cfg.Projects = projects
+		cfg.AllProjects = false
+	}
+	if format := query.Get("format"); format != "" {
+		cfg.Format = config.OutputFormat(format)
+	}
+	if where := query.Get("where"); where != "" {
+		cfg.Where = where
+	}
+	if groupBy := queryList(query, "group-by"); len(groupBy) > 0 {
+		cfg.GroupBy = groupBy
+	}
+	if sort := queryList(query, "sort"); len(sort) > 0 {
+		cfg.Sort = sort
+	}
+	// ファイルを書き出す機能はサーバーでは使わない
+	cfg.WithAttachments = false
+	cfg.Incremental = false
+	cfg.Output, cfg.OutputFile = "", ""
+
+	if err := cfg.Validate(); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := exporter.ValidateCSVColumns(cfg.Columns); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := exporter.ValidateWhere(cfg.Where); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if cfg.Format == config.FormatTemplate {
+		if err := exporter.ValidateTemplate(cfg.Template); err != nil {
+			return http.StatusBadRequest, err
+		}
+	}
+
+	exp := exporter.NewExporterWithOutput(h.client, &cfg, discardOutput{})
+	content, err := exp.Render(r.Context())
+	if err != nil {
+		return errorStatus(err), err
+	}
+
+	ext := exp.Extension()
+	w.Header().Set("Content-Type", contentTypes[ext])
+	if ext == "xlsx" || ext == "sqlite" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "tasks."+ext))
+	}
+	w.Write(content)
+	return http.StatusOK, nil
+}
+
+// queryList は繰り返し指定またはカンマ区切りのクエリパラメーターの値を返す
+func queryList(query url.Values, name string) []string {
+	var values commaListFlag
+	for _, v := range query[name] {
+		values.Set(v)
+	}
+	return values
+}
+
+// errorStatus はエクスポート時のエラーに対応するHTTPステータスを返す
+func errorStatus(err error) int {
+	switch classifyError(err) {
+	case ExitProjectNotFound:
+		return http.StatusNotFound
+	case ExitAuthError, ExitNetworkError:
+		return http.StatusBadGateway
+	case ExitRateLimitExceeded:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// discardOutput は進捗表示を捨てる Output
+type discardOutput struct{}
+
+func (discardOutput) Printf(format string, args ...interface{}) {}
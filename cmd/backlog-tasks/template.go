@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/miyanaga/backlog-exporter/internal/exporter"
+)
+
+// runTemplate は組み込みテンプレートを標準出力に書き出す（カスタマイズの元にする）
+func runTemplate(args []string) int {
+	fs := flag.NewFlagSet("template", flag.ContinueOnError)
+
+	var showHelp bool
+	fs.BoolVar(&showHelp, "help", false, "Show help")
+	fs.BoolVar(&showHelp, "h", false, "Show help (shorthand)")
+
+	names := strings.Join(exporter.BuiltinTemplateNames(), ", ")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: backlog-tasks template <name>\n\n")
+		fmt.Fprintf(os.Stderr, "Print a built-in report template to customize with -f template --template FILE.\n\n")
+		fmt.Fprintf(os.Stderr, "Templates: %s\n\n", names)
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fmt.Fprintf(os.Stderr, "  -h, --help       Show this help message\n\n")
+		fmt.Fprintf(os.Stderr, "Examples:\n")
+		fmt.Fprintf(os.Stderr, "  backlog-tasks template markdown > weekly.md.tmpl\n")
+		fmt.Fprintf(os.Stderr, "  backlog-tasks -s mycompany -p MYPROJ -f template --template weekly.md.tmpl\n")
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return ExitSuccess
+		}
+		return ExitInvalidArgs
+	}
+	if showHelp {
+		fs.Usage()
+		return ExitSuccess
+	}
+	if len(positional) != 1 {
+		fmt.Fprintf(os.Stderr, "Error: template requires a template name (%s)\n\n", names)
+		fs.Usage()
+		return ExitInvalidArgs
+	}
+
+	source, err := exporter.BuiltinTemplate(positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		return ExitInvalidArgs
+	}
+	fmt.Print(source)
+	return ExitSuccess
+}
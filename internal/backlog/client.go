@@ -3,6 +3,7 @@ package backlog
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,29 +24,41 @@ type HTTPClient interface {
 
 // APIClient は Backlog API クライアントの実装
 type APIClient struct {
-	baseURL    string
-	apiKey     string
-	httpClient HTTPClient
+	baseURL     string
+	apiKey      string
+	httpClient  HTTPClient
+	retryPolicy RetryPolicy
+	limiter     rateLimiter
+	sleep       func(ctx context.Context, d time.Duration) error
 }
 
 // NewClient は新しい Backlog API クライアントを作成する
 func NewClient(space, domain, apiKey string) *APIClient {
 	return &APIClient{
-		baseURL:    fmt.Sprintf("https://%s.%s/api/v2", space, domain),
-		apiKey:     apiKey,
-		httpClient: &http.Client{Timeout: defaultTimeout},
+		baseURL:     fmt.Sprintf("https://%s.%s/api/v2", space, domain),
+		apiKey:      apiKey,
+		httpClient:  &http.Client{Timeout: defaultTimeout},
+		retryPolicy: DefaultRetryPolicy(),
+		sleep:       sleepContext,
 	}
 }
 
 // NewClientWithHTTPClient はカスタムHTTPクライアントを使用する Backlog API クライアントを作成する
 func NewClientWithHTTPClient(baseURL, apiKey string, httpClient HTTPClient) *APIClient {
 	return &APIClient{
-		baseURL:    baseURL,
-		apiKey:     apiKey,
-		httpClient: httpClient,
+		baseURL:     baseURL,
+		apiKey:      apiKey,
+		httpClient:  httpClient,
+		retryPolicy: DefaultRetryPolicy(),
+		sleep:       sleepContext,
 	}
 }
 
+// SetRetryPolicy は再試行方針を設定する
+func (c *APIClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
+
 // GetProject はプロジェクト情報を取得する
 func (c *APIClient) GetProject(ctx context.Context, projectIDOrKey string) (*Project, error) {
 	endpoint := fmt.Sprintf("%s/projects/%s", c.baseURL, url.PathEscape(projectIDOrKey))
@@ -58,6 +71,45 @@ func (c *APIClient) GetProject(ctx context.Context, projectIDOrKey string) (*Pro
 	return &project, nil
 }
 
+// GetProjects は参加しているプロジェクトの一覧を取得する（アーカイブ済みを除く）
+func (c *APIClient) GetProjects(ctx context.Context) ([]*Project, error) {
+	endpoint := fmt.Sprintf("%s/projects", c.baseURL)
+
+	params := url.Values{}
+	params.Set("archived", "false")
+
+	var projects []*Project
+	if err := c.doRequest(ctx, endpoint, params, &projects); err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// GetMyself はAPIキーの所有者のユーザー情報を取得する
+func (c *APIClient) GetMyself(ctx context.Context) (*User, error) {
+	endpoint := fmt.Sprintf("%s/users/myself", c.baseURL)
+
+	var user User
+	if err := c.doRequest(ctx, endpoint, nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to get current user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// GetProjectUsers はプロジェクトの参加ユーザー一覧を取得する
+func (c *APIClient) GetProjectUsers(ctx context.Context, projectIDOrKey string) ([]*User, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/users", c.baseURL, url.PathEscape(projectIDOrKey))
+
+	var users []*User
+	if err := c.doRequest(ctx, endpoint, nil, &users); err != nil {
+		return nil, fmt.Errorf("failed to get project users: %w", err)
+	}
+
+	return users, nil
+}
+
 // GetStatuses はプロジェクトの状態一覧を取得する
 func (c *APIClient) GetStatuses(ctx context.Context, projectIDOrKey string) ([]*Status, error) {
 	endpoint := fmt.Sprintf("%s/projects/%s/statuses", c.baseURL, url.PathEscape(projectIDOrKey))
@@ -70,59 +122,351 @@ func (c *APIClient) GetStatuses(ctx context.Context, projectIDOrKey string) ([]*
 	return statuses, nil
 }
 
+// GetIssueTypes はプロジェクトの課題種別一覧を取得する
+func (c *APIClient) GetIssueTypes(ctx context.Context, projectIDOrKey string) ([]*IssueType, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/issueTypes", c.baseURL, url.PathEscape(projectIDOrKey))
+
+	var issueTypes []*IssueType
+	if err := c.doRequest(ctx, endpoint, nil, &issueTypes); err != nil {
+		return nil, fmt.Errorf("failed to get issue types: %w", err)
+	}
+
+	return issueTypes, nil
+}
+
+// GetCategories はプロジェクトのカテゴリー一覧を取得する
+func (c *APIClient) GetCategories(ctx context.Context, projectIDOrKey string) ([]*Category, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/categories", c.baseURL, url.PathEscape(projectIDOrKey))
+
+	var categories []*Category
+	if err := c.doRequest(ctx, endpoint, nil, &categories); err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// GetVersions はプロジェクトのバージョン（マイルストーン）一覧を取得する
+func (c *APIClient) GetVersions(ctx context.Context, projectIDOrKey string) ([]*Version, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/versions", c.baseURL, url.PathEscape(projectIDOrKey))
+
+	var versions []*Version
+	if err := c.doRequest(ctx, endpoint, nil, &versions); err != nil {
+		return nil, fmt.Errorf("failed to get versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetPriorities は優先度の一覧を取得する
+func (c *APIClient) GetPriorities(ctx context.Context) ([]*Priority, error) {
+	endpoint := fmt.Sprintf("%s/priorities", c.baseURL)
+
+	var priorities []*Priority
+	if err := c.doRequest(ctx, endpoint, nil, &priorities); err != nil {
+		return nil, fmt.Errorf("failed to get priorities: %w", err)
+	}
+
+	return priorities, nil
+}
+
+// GetCustomFields はプロジェクトのカスタム属性の一覧を取得する
+func (c *APIClient) GetCustomFields(ctx context.Context, projectIDOrKey string) ([]*CustomFieldDefinition, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/customFields", c.baseURL, url.PathEscape(projectIDOrKey))
+
+	var fields []*CustomFieldDefinition
+	if err := c.doRequest(ctx, endpoint, nil, &fields); err != nil {
+		return nil, fmt.Errorf("failed to get custom fields: %w", err)
+	}
+
+	return fields, nil
+}
+
 // GetIssues は課題一覧を取得する（ページネーション処理済み）
-func (c *APIClient) GetIssues(ctx context.Context, projectID int, statusIDs []int, assigneeID *int, progressFn func(fetched, total int)) ([]*Issue, error) {
+func (c *APIClient) GetIssues(ctx context.Context, query IssueQuery, progressFn func(fetched, total int)) ([]*Issue, error) {
 	var allIssues []*Issue
-	offset := 0
+	err := c.WalkIssues(ctx, query, func(issues []*Issue) error {
+		allIssues = append(allIssues, issues...)
+		return nil
+	}, progressFn)
+	if err != nil {
+		return nil, err
+	}
+	return allIssues, nil
+}
 
-	for {
-		params := url.Values{}
-		params.Set("projectId[]", strconv.Itoa(projectID))
-		params.Set("count", strconv.Itoa(maxCount))
-		params.Set("offset", strconv.Itoa(offset))
-		params.Set("sort", "created")
-		params.Set("order", "asc")
+// WalkIssues は課題一覧を1ページずつ取得して pageFn に渡す
+// 最初に件数を取得し、各ページを同時に取得しながら順番どおりに渡す
+// 渡す前のページは同時取得数までに抑えるため、課題数が多くてもメモリ使用量は一定で済む
+func (c *APIClient) WalkIssues(ctx context.Context, query IssueQuery, pageFn func(issues []*Issue) error, progressFn func(fetched, total int)) error {
+	total, err := c.countIssues(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to count issues: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 各ページを同時に取得する（pageFn に渡していないページが issuePageConcurrency を超えないようにする）
+	pages := (total + maxCount - 1) / maxCount
+	results := make([]chan issuePage, pages)
+	for i := range results {
+		results[i] = make(chan issuePage, 1)
+	}
+	sem := make(chan struct{}, issuePageConcurrency)
+	go func() {
+		for i := range results {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(i int) {
+				issues, err := c.getIssuePage(ctx, query, i*maxCount)
+				results[i] <- issuePage{issues: issues, err: err}
+			}(i)
+		}
+	}()
 
-		for _, statusID := range statusIDs {
-			params.Add("statusId[]", strconv.Itoa(statusID))
+	fetched := 0
+	deliver := func(issues []*Issue, total int) error {
+		if err := pageFn(issues); err != nil {
+			return err
 		}
+		fetched += len(issues)
 
-		if assigneeID != nil {
-			params.Set("assigneeId[]", strconv.Itoa(*assigneeID))
+		// 進捗通知
+		if progressFn != nil {
+			progressFn(fetched, total)
 		}
+		return nil
+	}
 
-		endpoint := fmt.Sprintf("%s/issues", c.baseURL)
+	full := false
+	for _, result := range results {
+		page := <-result
+		if page.err != nil {
+			return page.err
+		}
+		if err := deliver(page.issues, total); err != nil {
+			return err
+		}
+		full = len(page.issues) == maxCount
+		<-sem
+	}
 
-		var issues []*Issue
-		if err := c.doRequest(ctx, endpoint, params, &issues); err != nil {
-			return nil, fmt.Errorf("failed to get issues: %w", err)
+	// 件数の取得後に追加された課題は最後のページに続くので、最後のページが満杯なら続けて取得する
+	for offset := pages * maxCount; full; offset += maxCount {
+		issues, err := c.getIssuePage(ctx, query, offset)
+		if err != nil {
+			return err
+		}
+		if len(issues) == 0 {
+			break
 		}
+		if err := deliver(issues, -1); err != nil { // 総数は不明なので-1
+			return err
+		}
+		full = len(issues) == maxCount
+	}
 
-		allIssues = append(allIssues, issues...)
+	// 最終的な進捗通知（件数と取得数が異なる場合や課題がない場合）
+	if progressFn != nil && (fetched != total || total == 0) {
+		progressFn(fetched, fetched)
+	}
 
-		// 進捗通知
-		if progressFn != nil {
-			progressFn(len(allIssues), -1) // 総数は不明なので-1
+	return nil
+}
+
+// issuePageConcurrency は課題一覧のページを同時に取得する数
+const issuePageConcurrency = 4
+
+// issuePage は同時に取得した課題一覧の1ページ分の結果
+type issuePage struct {
+	issues []*Issue
+	err    error
+}
+
+// countIssues は query の条件に一致する課題の件数を取得する
+func (c *APIClient) countIssues(ctx context.Context, query IssueQuery) (int, error) {
+	params := url.Values{}
+	params.Set("projectId[]", strconv.Itoa(query.ProjectID))
+	query.setParams(params)
+
+	endpoint := fmt.Sprintf("%s/issues/count", c.baseURL)
+
+	var result struct {
+		Count int `json:"count"`
+	}
+	if err := c.doRequest(ctx, endpoint, params, &result); err != nil {
+		return 0, err
+	}
+	return result.Count, nil
+}
+
+// getIssuePage は offset から1ページ分（最大 maxCount 件）の課題を作成日時の昇順で取得する
+func (c *APIClient) getIssuePage(ctx context.Context, query IssueQuery, offset int) ([]*Issue, error) {
+	params := url.Values{}
+	params.Set("projectId[]", strconv.Itoa(query.ProjectID))
+	params.Set("count", strconv.Itoa(maxCount))
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("sort", "created")
+	params.Set("order", "asc")
+
+	query.setParams(params)
+
+	endpoint := fmt.Sprintf("%s/issues", c.baseURL)
+
+	var issues []*Issue
+	if err := c.doRequest(ctx, endpoint, params, &issues); err != nil {
+		return nil, fmt.Errorf("failed to get issues: %w", err)
+	}
+	return issues, nil
+}
+
+// setParams は取得条件をAPIのパラメータに設定する
+func (q IssueQuery) setParams(params url.Values) {
+	addIDs := func(name string, ids []int) {
+		for _, id := range ids {
+			params.Add(name, strconv.Itoa(id))
+		}
+	}
+	setDate := func(name string, date *time.Time) {
+		if date != nil {
+			params.Set(name, date.Format("2006-01-02"))
 		}
+	}
+
+	addIDs("statusId[]", q.StatusIDs)
+	addIDs("assigneeId[]", q.AssigneeIDs)
+	addIDs("issueTypeId[]", q.IssueTypeIDs)
+	addIDs("categoryId[]", q.CategoryIDs)
+	addIDs("milestoneId[]", q.MilestoneIDs)
+	addIDs("versionId[]", q.VersionIDs)
+	addIDs("priorityId[]", q.PriorityIDs)
+
+	if q.Keyword != "" {
+		params.Set("keyword", q.Keyword)
+	}
+
+	setDate("createdSince", q.CreatedSince)
+	setDate("createdUntil", q.CreatedUntil)
+	setDate("updatedSince", q.UpdatedSince)
+	setDate("updatedUntil", q.UpdatedUntil)
+	setDate("dueDateSince", q.DueDateSince)
+	setDate("dueDateUntil", q.DueDateUntil)
+
+	if q.ParentChild != ParentChildAll {
+		params.Set("parentChild", strconv.Itoa(int(q.ParentChild)))
+	}
+
+	for _, cf := range q.CustomFields {
+		name := fmt.Sprintf("customField_%d", cf.ID)
+		if cf.Keyword != "" {
+			params.Set(name, cf.Keyword)
+		}
+		addIDs(name+"[]", cf.ItemIDs)
+		if cf.Min != "" {
+			params.Set(name+"_min", cf.Min)
+		}
+		if cf.Max != "" {
+			params.Set(name+"_max", cf.Max)
+		}
+	}
+}
+
+// GetIssue は課題を1件取得する
+func (c *APIClient) GetIssue(ctx context.Context, issueIDOrKey string) (*Issue, error) {
+	endpoint := fmt.Sprintf("%s/issues/%s", c.baseURL, url.PathEscape(issueIDOrKey))
+
+	var issue Issue
+	if err := c.doRequest(ctx, endpoint, nil, &issue); err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	return &issue, nil
+}
+
+// GetComments は課題のコメント一覧を古い順に取得する（ページネーション処理済み）
+func (c *APIClient) GetComments(ctx context.Context, issueIDOrKey string) ([]*Comment, error) {
+	var allComments []*Comment
+	minID := 0
+
+	for {
+		params := url.Values{}
+		params.Set("count", strconv.Itoa(maxCount))
+		params.Set("order", "asc")
+		if minID > 0 {
+			params.Set("minId", strconv.Itoa(minID))
+		}
+
+		endpoint := fmt.Sprintf("%s/issues/%s/comments", c.baseURL, url.PathEscape(issueIDOrKey))
+
+		var comments []*Comment
+		if err := c.doRequest(ctx, endpoint, params, &comments); err != nil {
+			return nil, fmt.Errorf("failed to get comments: %w", err)
+		}
+
+		allComments = append(allComments, comments...)
 
 		// ページネーション: 取得件数がmaxCount未満なら終了
-		if len(issues) < maxCount {
+		if len(comments) < maxCount {
 			break
 		}
 
-		offset += maxCount
+		// minId は指定したIDより大きいコメントを返すので、最後のIDを次の起点にする
+		minID = comments[len(comments)-1].ID
 	}
 
-	// 最終的な進捗通知
-	if progressFn != nil {
-		progressFn(len(allIssues), len(allIssues))
+	return allComments, nil
+}
+
+// GetAttachments は課題の添付ファイル一覧を取得する
+func (c *APIClient) GetAttachments(ctx context.Context, issueIDOrKey string) ([]*Attachment, error) {
+	endpoint := fmt.Sprintf("%s/issues/%s/attachments", c.baseURL, url.PathEscape(issueIDOrKey))
+
+	var attachments []*Attachment
+	if err := c.doRequest(ctx, endpoint, nil, &attachments); err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
 	}
 
-	return allIssues, nil
+	return attachments, nil
+}
+
+// DownloadAttachment は課題の添付ファイルをダウンロードして w に書き込む
+func (c *APIClient) DownloadAttachment(ctx context.Context, issueIDOrKey string, attachmentID int, w io.Writer) error {
+	endpoint := fmt.Sprintf("%s/issues/%s/attachments/%d", c.baseURL, url.PathEscape(issueIDOrKey), attachmentID)
+
+	body, err := c.fetch(ctx, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to download attachment: %w", err)
+	}
+
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("failed to write attachment: %w", err)
+	}
+
+	return nil
 }
 
-// doRequest は API リクエストを実行する
+// doRequest は API リクエストを実行し、JSONレスポンスを result にデコードする
 func (c *APIClient) doRequest(ctx context.Context, endpoint string, params url.Values, result interface{}) error {
+	body, err := c.fetch(ctx, endpoint, params)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
+
+// fetch は API リクエストを実行してレスポンスボディを返す
+// レート制限の残量が尽きている場合はリセットまで待機し、
+// 429・5xx・通信エラーは再試行方針に従ってバックオフしながら再試行する
+func (c *APIClient) fetch(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
 	if params == nil {
 		params = url.Values{}
 	}
@@ -130,33 +474,67 @@ func (c *APIClient) doRequest(ctx context.Context, endpoint string, params url.V
 
 	fullURL := endpoint + "?" + params.Encode()
 
+	for attempt := 0; ; attempt++ {
+		// 前回までのレスポンスでレート制限に達していればリセットまで待つ
+		if wait := c.limiter.waitDuration(c.retryPolicy, time.Now()); wait > 0 {
+			if err := c.sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+
+		body, err := c.send(ctx, fullURL)
+		if err == nil {
+			return body, nil
+		}
+
+		if !IsRetryable(err) || attempt >= c.retryPolicy.MaxRetries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		wait := c.retryPolicy.backoff(attempt)
+		var rateLimitErr *RateLimitError
+		if errors.As(err, &rateLimitErr) && !rateLimitErr.Reset.IsZero() {
+			if untilReset := c.retryPolicy.rateLimitWait(rateLimitErr.Reset, time.Now()); untilReset > wait {
+				wait = untilReset
+			}
+		}
+		if err := c.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send は1回分のリクエストを送信してレスポンスボディを返す
+func (c *APIClient) send(ctx context.Context, fullURL string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, &NetworkError{Err: fmt.Errorf("failed to send request: %w", err)}
 	}
 	defer resp.Body.Close()
 
+	c.limiter.update(resp.Header)
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, &NetworkError{Err: fmt.Errorf("failed to read response body: %w", err)}
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		var apiErr APIError
-		if err := json.Unmarshal(body, &apiErr); err == nil && len(apiErr.Errors) > 0 {
-			return &apiErr
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(body, apiErr); err != nil || len(apiErr.Errors) == 0 {
+			apiErr.Errors = nil
+			apiErr.Body = string(body)
 		}
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newResponseError(apiErr, resp.Header)
 	}
 
-	if err := json.Unmarshal(body, result); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return nil
+	return body, nil
 }
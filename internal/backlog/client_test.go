@@ -1,11 +1,19 @@
 package backlog
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestAPIClient_GetProject(t *testing.T) {
@@ -81,14 +89,14 @@ func TestAPIClient_GetStatuses(t *testing.T) {
 func TestAPIClient_GetIssues(t *testing.T) {
 	requestCount := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/api/v2/issues" {
+		if r.URL.Path != "/api/v2/issues" && r.URL.Path != "/api/v2/issues/count" {
 			t.Errorf("unexpected path: %s", r.URL.Path)
 		}
 
 		requestCount++
 		var issues []*Issue
 
-		// 最初のリクエストでは50件を返す（ページネーション終了）
+		// 50件を返す（ページネーション終了）
 		for i := 0; i < 50; i++ {
 			issues = append(issues, &Issue{
 				ID:       i + 1,
@@ -97,7 +105,7 @@ func TestAPIClient_GetIssues(t *testing.T) {
 			})
 		}
 
-		json.NewEncoder(w).Encode(issues)
+		writeIssuesResponse(w, r, issues)
 	}))
 	defer server.Close()
 
@@ -105,7 +113,7 @@ func TestAPIClient_GetIssues(t *testing.T) {
 
 	ctx := context.Background()
 	var progressCalls int
-	issues, err := client.GetIssues(ctx, 1, []int{1, 2, 3}, nil, func(fetched, total int) {
+	issues, err := client.GetIssues(ctx, IssueQuery{ProjectID: 1, StatusIDs: []int{1, 2, 3}}, func(fetched, total int) {
 		progressCalls++
 	})
 	if err != nil {
@@ -115,51 +123,205 @@ func TestAPIClient_GetIssues(t *testing.T) {
 	if len(issues) != 50 {
 		t.Errorf("expected 50 issues, got %d", len(issues))
 	}
-	if requestCount != 1 {
-		t.Errorf("expected 1 request, got %d", requestCount)
+	// 件数の取得と1ページ分のリクエスト
+	if requestCount != 2 {
+		t.Errorf("expected 2 requests, got %d", requestCount)
 	}
 	if progressCalls < 1 {
 		t.Errorf("expected at least 1 progress callback, got %d", progressCalls)
 	}
 }
 
-func TestAPIClient_GetIssues_Pagination(t *testing.T) {
-	requestCount := 0
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestCount++
-		var issues []*Issue
+// writeIssuesResponse は課題一覧のリクエストに issues を、件数（/issues/count）のリクエストにその件数を返す
+func writeIssuesResponse(w http.ResponseWriter, r *http.Request, issues []*Issue) {
+	if strings.HasSuffix(r.URL.Path, "/count") {
+		json.NewEncoder(w).Encode(map[string]int{"count": len(issues)})
+		return
+	}
+	json.NewEncoder(w).Encode(issues)
+}
 
-		// 最初のリクエストでは100件、2回目は50件を返す
-		count := 100
-		if requestCount > 1 {
-			count = 50
+// issuePagesServer は total 件の課題を offset・count に応じて返すサーバーを作成する
+// 件数（/issues/count）のリクエストには countResult 件と応答する
+func issuePagesServer(t *testing.T, total, countResult int, onPage func(offset int)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/issues/count" {
+			json.NewEncoder(w).Encode(map[string]int{"count": countResult})
+			return
 		}
-
-		for i := 0; i < count; i++ {
-			issues = append(issues, &Issue{
-				ID:       (requestCount-1)*100 + i + 1,
-				IssueKey: "MYPROJ-" + string(rune('0'+requestCount)) + string(rune('0'+i%10)),
-				Summary:  "Test Issue",
-			})
+		q := r.URL.Query()
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		count, _ := strconv.Atoi(q.Get("count"))
+		if onPage != nil {
+			onPage(offset)
+		}
+		issues := []*Issue{}
+		for id := offset + 1; id <= min(offset+count, total); id++ {
+			issues = append(issues, &Issue{ID: id, IssueKey: "MYPROJ-" + strconv.Itoa(id)})
 		}
-
 		json.NewEncoder(w).Encode(issues)
 	}))
+}
+
+func TestAPIClient_GetIssues_Pagination(t *testing.T) {
+	var mu sync.Mutex
+	var offsets []int
+	server := issuePagesServer(t, 1240, 1240, func(offset int) {
+		mu.Lock()
+		defer mu.Unlock()
+		offsets = append(offsets, offset)
+	})
 	defer server.Close()
 
 	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
 
-	ctx := context.Background()
-	issues, err := client.GetIssues(ctx, 1, []int{1, 2, 3}, nil, nil)
+	var progress []string
+	issues, err := client.GetIssues(context.Background(), IssueQuery{ProjectID: 1, StatusIDs: []int{1, 2, 3}}, func(fetched, total int) {
+		progress = append(progress, fmt.Sprintf("%d/%d", fetched, total))
+	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if len(issues) != 150 {
-		t.Errorf("expected 150 issues, got %d", len(issues))
+	// 同時に取得したページも作成日時の順に並べる
+	if len(issues) != 1240 {
+		t.Fatalf("expected 1240 issues, got %d", len(issues))
 	}
-	if requestCount != 2 {
-		t.Errorf("expected 2 requests, got %d", requestCount)
+	for i, issue := range issues {
+		if issue.ID != i+1 {
+			t.Fatalf("issue %d: expected ID %d, got %d", i, i+1, issue.ID)
+		}
+	}
+	if len(offsets) != 13 {
+		t.Errorf("expected 13 page requests, got %v", offsets)
+	}
+	// 進捗は件数の取得で分かった総数とともに通知する
+	if progress[2] != "300/1240" || progress[len(progress)-1] != "1240/1240" || len(progress) != 13 {
+		t.Errorf("unexpected progress: %v", progress)
+	}
+}
+
+func TestAPIClient_GetIssues_AddedAfterCount(t *testing.T) {
+	// 件数の取得後に課題が追加された場合は、最後のページに続けて取得する
+	server := issuePagesServer(t, 230, 200, nil)
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	var progress []string
+	issues, err := client.GetIssues(context.Background(), IssueQuery{ProjectID: 1}, func(fetched, total int) {
+		progress = append(progress, fmt.Sprintf("%d/%d", fetched, total))
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 230 || issues[229].ID != 230 {
+		t.Errorf("expected 230 issues, got %d", len(issues))
+	}
+	if strings.Join(progress, ",") != "100/200,200/200,230/-1,230/230" {
+		t.Errorf("unexpected progress: %v", progress)
+	}
+}
+
+func TestAPIClient_WalkIssues(t *testing.T) {
+	var mu sync.Mutex
+	var requested, maxAhead int
+	delivered := 0
+	server := issuePagesServer(t, 2030, 2030, func(offset int) {
+		mu.Lock()
+		defer mu.Unlock()
+		requested++
+		// pageFn に渡していないページは同時取得数を超えない
+		maxAhead = max(maxAhead, requested-delivered)
+	})
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	var pages []int
+	err := client.WalkIssues(context.Background(), IssueQuery{ProjectID: 1}, func(issues []*Issue) error {
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+		pages = append(pages, issues[0].ID)
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pages) != 21 || pages[0] != 1 || pages[20] != 2001 {
+		t.Errorf("unexpected pages: %v", pages)
+	}
+	if maxAhead > issuePageConcurrency {
+		t.Errorf("expected at most %d pages ahead, got %d", issuePageConcurrency, maxAhead)
+	}
+
+	// pageFn のエラーで取得を中断する
+	stop := errors.New("stop")
+	err = client.WalkIssues(context.Background(), IssueQuery{ProjectID: 1}, func(issues []*Issue) error {
+		return stop
+	}, nil)
+	if !errors.Is(err, stop) {
+		t.Errorf("expected pageFn error, got %v", err)
+	}
+}
+
+func TestAPIClient_WalkIssues_CountError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	err := client.WalkIssues(context.Background(), IssueQuery{ProjectID: 1}, func(issues []*Issue) error {
+		t.Error("pageFn should not be called")
+		return nil
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to count issues") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAPIClient_GetIssues_UpdatedSince(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got := q.Get("updatedSince"); got != "2024-11-26" {
+			t.Errorf("expected updatedSince=2024-11-26, got %q", got)
+		}
+		if _, ok := q["statusId[]"]; ok {
+			t.Error("statusId should not be set")
+		}
+		writeIssuesResponse(w, r, []*Issue{{ID: 1}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	since := time.Date(2024, 11, 26, 9, 0, 0, 0, time.UTC)
+	if _, err := client.GetIssues(context.Background(), IssueQuery{ProjectID: 1, UpdatedSince: &since}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAPIClient_GetIssue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/issues/100" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(Issue{ID: 100, IssueKey: "MYPROJ-1", Summary: "完了した親課題"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	issue, err := client.GetIssue(context.Background(), "100")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue.ID != 100 || issue.IssueKey != "MYPROJ-1" {
+		t.Errorf("unexpected issue: %+v", issue)
 	}
 }
 
@@ -187,3 +349,630 @@ func TestAPIClient_GetProject_Error(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+// noSleep は待機せずに待機時間を記録するスリープ関数を返す
+func noSleep(waits *[]time.Duration) func(ctx context.Context, d time.Duration) error {
+	return func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return ctx.Err()
+	}
+}
+
+func TestAPIClient_Retry_ServerError(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		if requestCount < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(Project{ID: 1, ProjectKey: "MYPROJ"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+	var waits []time.Duration
+	client.sleep = noSleep(&waits)
+
+	project, err := client.GetProject(context.Background(), "MYPROJ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if project.ProjectKey != "MYPROJ" {
+		t.Errorf("expected project key MYPROJ, got %s", project.ProjectKey)
+	}
+	if requestCount != 3 {
+		t.Errorf("expected 3 requests, got %d", requestCount)
+	}
+	if len(waits) != 2 {
+		t.Errorf("expected 2 backoff waits, got %d", len(waits))
+	}
+}
+
+func TestAPIClient_Retry_RateLimited(t *testing.T) {
+	requestCount := 0
+	reset := time.Now().Add(10 * time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		if requestCount == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(Project{ID: 1, ProjectKey: "MYPROJ"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+	var waits []time.Duration
+	client.sleep = noSleep(&waits)
+
+	if _, err := client.GetProject(context.Background(), "MYPROJ"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requestCount != 2 {
+		t.Errorf("expected 2 requests, got %d", requestCount)
+	}
+	if len(waits) == 0 || waits[0] < 5*time.Second {
+		t.Errorf("expected to wait until rate limit reset, got %v", waits)
+	}
+}
+
+func TestAPIClient_Retry_WaitsWhenQuotaExhausted(t *testing.T) {
+	reset := time.Now().Add(10 * time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		json.NewEncoder(w).Encode(Project{ID: 1, ProjectKey: "MYPROJ"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+	var waits []time.Duration
+	client.sleep = noSleep(&waits)
+
+	ctx := context.Background()
+	if _, err := client.GetProject(ctx, "MYPROJ"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(waits) != 0 {
+		t.Errorf("first request should not wait, got %v", waits)
+	}
+
+	if _, err := client.GetProject(ctx, "MYPROJ"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(waits) != 1 {
+		t.Errorf("second request should wait for reset, got %v", waits)
+	}
+}
+
+func TestAPIClient_Retry_BudgetExhausted(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+	var waits []time.Duration
+	client.sleep = noSleep(&waits)
+	policy := DefaultRetryPolicy()
+	policy.MaxRetries = 2
+	client.SetRetryPolicy(policy)
+
+	if _, err := client.GetProject(context.Background(), "MYPROJ"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if requestCount != 3 {
+		t.Errorf("expected 3 requests (1 + 2 retries), got %d", requestCount)
+	}
+}
+
+func TestAPIClient_Retry_NotRetryable(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+	var waits []time.Duration
+	client.sleep = noSleep(&waits)
+
+	if _, err := client.GetProject(context.Background(), "MYPROJ"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if requestCount != 1 {
+		t.Errorf("expected 1 request, got %d", requestCount)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		d := policy.backoff(attempt)
+		if d > time.Second {
+			t.Errorf("attempt %d: backoff %v exceeds max delay", attempt, d)
+		}
+		if d < 50*time.Millisecond {
+			t.Errorf("attempt %d: backoff %v below half of base delay", attempt, d)
+		}
+	}
+}
+
+func TestAPIClient_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		check  func(t *testing.T, err error)
+	}{
+		{
+			name:   "unauthorized",
+			status: http.StatusUnauthorized,
+			body:   `{"errors":[{"message":"Authenticate error","code":11,"moreInfo":""}]}`,
+			check: func(t *testing.T, err error) {
+				var target *AuthError
+				if !errors.As(err, &target) {
+					t.Errorf("expected AuthError, got %T: %v", err, err)
+				}
+			},
+		},
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			body:   `{"errors":[{"message":"No project.","code":6,"moreInfo":""}]}`,
+			check: func(t *testing.T, err error) {
+				var target *NotFoundError
+				if !errors.As(err, &target) {
+					t.Errorf("expected NotFoundError, got %T: %v", err, err)
+				}
+			},
+		},
+		{
+			name:   "rate limited",
+			status: http.StatusTooManyRequests,
+			body:   `{"errors":[{"message":"Too many requests","code":13,"moreInfo":""}]}`,
+			check: func(t *testing.T, err error) {
+				var target *RateLimitError
+				if !errors.As(err, &target) {
+					t.Fatalf("expected RateLimitError, got %T: %v", err, err)
+				}
+				if target.Reset.Unix() != 1700000000 {
+					t.Errorf("expected reset time from header, got %v", target.Reset)
+				}
+			},
+		},
+		{
+			name:   "bad request",
+			status: http.StatusBadRequest,
+			body:   `{"errors":[{"message":"Invalid request","code":7,"moreInfo":""}]}`,
+			check: func(t *testing.T, err error) {
+				var target *APIError
+				if !errors.As(err, &target) {
+					t.Fatalf("expected APIError, got %T: %v", err, err)
+				}
+				if target.StatusCode != http.StatusBadRequest {
+					t.Errorf("expected status 400, got %d", target.StatusCode)
+				}
+				if !target.HasCode(ErrorCodeInvalidRequest) {
+					t.Errorf("expected error code 7, got %v", target.Codes())
+				}
+			},
+		},
+		{
+			name:   "non-JSON body",
+			status: http.StatusForbidden,
+			body:   "Forbidden",
+			check: func(t *testing.T, err error) {
+				var target *APIError
+				if !errors.As(err, &target) {
+					t.Fatalf("expected APIError, got %T: %v", err, err)
+				}
+				if target.Body != "Forbidden" {
+					t.Errorf("expected raw body, got %q", target.Body)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", "1700000000")
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+			var waits []time.Duration
+			client.sleep = noSleep(&waits)
+			client.SetRetryPolicy(RetryPolicy{})
+
+			_, err := client.GetProject(context.Background(), "MYPROJ")
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			tc.check(t, err)
+		})
+	}
+}
+
+func TestAPIClient_NetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serverURL := server.URL
+	server.Close()
+
+	client := NewClientWithHTTPClient(serverURL+"/api/v2", "test-api-key", http.DefaultClient)
+	var waits []time.Duration
+	client.sleep = noSleep(&waits)
+	client.SetRetryPolicy(RetryPolicy{})
+
+	_, err := client.GetProject(context.Background(), "MYPROJ")
+	var target *NetworkError
+	if !errors.As(err, &target) {
+		t.Errorf("expected NetworkError, got %T: %v", err, err)
+	}
+}
+
+func TestAPIClient_GetComments_Pagination(t *testing.T) {
+	var minIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/issues/MYPROJ-1/comments" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("order") != "asc" {
+			t.Errorf("expected ascending order, got %s", r.URL.Query().Get("order"))
+		}
+		minIDs = append(minIDs, r.URL.Query().Get("minId"))
+
+		// 最初のリクエストでは100件、2回目は30件を返す
+		start, count := 1, 100
+		if len(minIDs) > 1 {
+			start, count = 101, 30
+		}
+
+		var comments []*Comment
+		for i := 0; i < count; i++ {
+			comments = append(comments, &Comment{ID: start + i, Content: "comment"})
+		}
+		json.NewEncoder(w).Encode(comments)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	comments, err := client.GetComments(context.Background(), "MYPROJ-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(comments) != 130 {
+		t.Errorf("expected 130 comments, got %d", len(comments))
+	}
+	if len(minIDs) != 2 || minIDs[0] != "" || minIDs[1] != "100" {
+		t.Errorf("unexpected minId sequence: %v", minIDs)
+	}
+}
+
+func TestAPIClient_Attachments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/issues/MYPROJ-1/attachments":
+			attachments := []*Attachment{
+				{ID: 8, Name: "screenshot.png", Size: 4},
+			}
+			json.NewEncoder(w).Encode(attachments)
+		case "/api/v2/issues/MYPROJ-1/attachments/8":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+	ctx := context.Background()
+
+	attachments, err := client.GetAttachments(ctx, "MYPROJ-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(attachments) != 1 || attachments[0].Name != "screenshot.png" {
+		t.Fatalf("unexpected attachments: %+v", attachments)
+	}
+
+	var buf bytes.Buffer
+	if err := client.DownloadAttachment(ctx, "MYPROJ-1", attachments[0].ID, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "\x89PNG" {
+		t.Errorf("unexpected attachment content: %q", buf.String())
+	}
+}
+
+func TestAPIClient_GetProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/projects" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("archived") != "false" {
+			t.Errorf("archived projects should be excluded")
+		}
+
+		projects := []*Project{
+			{ID: 1, ProjectKey: "ALPHA"},
+			{ID: 2, ProjectKey: "BETA"},
+		}
+		json.NewEncoder(w).Encode(projects)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	projects, err := client.GetProjects(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Errorf("expected 2 projects, got %d", len(projects))
+	}
+}
+
+func TestAPIClient_GetProjectUsers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/projects/MYPROJ/users" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		users := []*User{
+			{ID: 1, UserID: "yamada", Name: "山田"},
+			{ID: 2, UserID: "suzuki", Name: "鈴木"},
+		}
+		json.NewEncoder(w).Encode(users)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	users, err := client.GetProjectUsers(context.Background(), "MYPROJ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 2 || users[0].UserID != "yamada" {
+		t.Errorf("unexpected users: %+v", users)
+	}
+}
+
+func TestAPIClient_GetMyself(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/users/myself" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(&User{ID: 1, UserID: "yamada", Name: "山田"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	user, err := client.GetMyself(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.ID != 1 || user.UserID != "yamada" {
+		t.Errorf("unexpected user: %+v", user)
+	}
+}
+
+func TestAPIClient_GetIssues_Assignees(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := r.URL.Query()["assigneeId[]"]
+		if len(got) != 2 || got[0] != "1" || got[1] != "2" {
+			t.Errorf("unexpected assigneeId[]: %v", got)
+		}
+		writeIssuesResponse(w, r, []*Issue{{ID: 1}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	if _, err := client.GetIssues(context.Background(), IssueQuery{ProjectID: 1, AssigneeIDs: []int{1, 2}}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAPIClient_GetIssues_Filters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		want := map[string][]string{
+			"issueTypeId[]": {"10"},
+			"categoryId[]":  {"20", "21"},
+			"milestoneId[]": {"30"},
+			"versionId[]":   {"31"},
+			"priorityId[]":  {"2"},
+			"keyword":       {"ログイン"},
+			"createdSince":  {"2024-11-01"},
+			"dueDateUntil":  {"2024-11-30"},
+			"parentChild":   {"1"},
+		}
+		for name, values := range want {
+			if got := q[name]; !reflect.DeepEqual(got, values) {
+				t.Errorf("%s: expected %v, got %v", name, values, got)
+			}
+		}
+		if q.Has("updatedSince") || q.Has("assigneeId[]") {
+			t.Errorf("unspecified conditions should not be sent: %v", q)
+		}
+		writeIssuesResponse(w, r, []*Issue{{ID: 1}})
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	createdSince := time.Date(2024, 11, 1, 0, 0, 0, 0, time.Local)
+	dueDateUntil := time.Date(2024, 11, 30, 0, 0, 0, 0, time.Local)
+	query := IssueQuery{
+		ProjectID:    1,
+		IssueTypeIDs: []int{10},
+		CategoryIDs:  []int{20, 21},
+		MilestoneIDs: []int{30},
+		VersionIDs:   []int{31},
+		PriorityIDs:  []int{2},
+		Keyword:      "ログイン",
+		CreatedSince: &createdSince,
+		DueDateUntil: &dueDateUntil,
+		ParentChild:  ParentChildNotChild,
+	}
+	if _, err := client.GetIssues(context.Background(), query, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAPIClient_Lookups(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/projects/MYPROJ/issueTypes":
+			json.NewEncoder(w).Encode([]*IssueType{{ID: 10, Name: "バグ"}})
+		case "/api/v2/projects/MYPROJ/categories":
+			json.NewEncoder(w).Encode([]*Category{{ID: 20, Name: "フロントエンド"}})
+		case "/api/v2/projects/MYPROJ/versions":
+			json.NewEncoder(w).Encode([]*Version{{ID: 30, Name: "Sprint 12"}})
+		case "/api/v2/priorities":
+			json.NewEncoder(w).Encode([]*Priority{{ID: 2, Name: "高"}})
+		case "/api/v2/projects/MYPROJ/customFields":
+			w.Write([]byte(`[{"id":40,"typeId":5,"name":"工程","required":true,"items":[{"id":1,"name":"設計"}]}]`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+	ctx := context.Background()
+
+	issueTypes, err := client.GetIssueTypes(ctx, "MYPROJ")
+	if err != nil || len(issueTypes) != 1 || issueTypes[0].Name != "バグ" {
+		t.Errorf("GetIssueTypes: %v, %v", issueTypes, err)
+	}
+	categories, err := client.GetCategories(ctx, "MYPROJ")
+	if err != nil || len(categories) != 1 || categories[0].ID != 20 {
+		t.Errorf("GetCategories: %v, %v", categories, err)
+	}
+	versions, err := client.GetVersions(ctx, "MYPROJ")
+	if err != nil || len(versions) != 1 || versions[0].Name != "Sprint 12" {
+		t.Errorf("GetVersions: %v, %v", versions, err)
+	}
+	priorities, err := client.GetPriorities(ctx)
+	if err != nil || len(priorities) != 1 || priorities[0].ID != 2 {
+		t.Errorf("GetPriorities: %v, %v", priorities, err)
+	}
+	customFields, err := client.GetCustomFields(ctx, "MYPROJ")
+	if err != nil || len(customFields) != 1 || customFields[0].TypeID != CustomFieldSingleList || customFields[0].Items[0].Name != "設計" {
+		t.Errorf("GetCustomFields: %v, %v", customFields, err)
+	}
+}
+
+// customFieldsJSON はAPIが返す課題のカスタム属性（種類ごとに value の型が異なる）
+const customFieldsJSON = `[
+	{"id": 1, "fieldTypeId": 1, "name": "顧客", "value": "ACME"},
+	{"id": 2, "fieldTypeId": 2, "name": "備考", "value": null},
+	{"id": 3, "fieldTypeId": 3, "name": "見積", "value": 2.5},
+	{"id": 4, "fieldTypeId": 4, "name": "リリース日", "value": "2024-12-01T00:00:00Z"},
+	{"id": 5, "fieldTypeId": 5, "name": "工程", "value": {"id": 11, "name": "設計", "displayOrder": 0}},
+	{"id": 6, "fieldTypeId": 6, "name": "対象", "value": [{"id": 21, "name": "iOS"}, {"id": 22, "name": "Android"}]},
+	{"id": 7, "fieldTypeId": 7, "name": "確認", "value": [{"id": 31, "name": "レビュー済"}], "otherValue": "QA待ち"},
+	{"id": 8, "fieldTypeId": 8, "name": "環境", "value": {"id": 41, "name": "本番"}}
+]`
+
+func TestCustomField_UnmarshalJSON(t *testing.T) {
+	var fields []*CustomField
+	if err := json.Unmarshal([]byte(customFieldsJSON), &fields); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"ACME", "", "2.5", "2024-12-01", "設計", "iOS, Android", "レビュー済, QA待ち", "本番"}
+	for i, field := range fields {
+		if got := field.String(); got != want[i] {
+			t.Errorf("%s: expected %q, got %q", field.Name, want[i], got)
+		}
+	}
+	if !fields[1].IsEmpty() || fields[0].IsEmpty() {
+		t.Error("only the null value should be empty")
+	}
+	if fields[2].Number == nil || *fields[2].Number != 2.5 {
+		t.Errorf("numeric value should be decoded as a number: %+v", fields[2])
+	}
+	if len(fields[5].Items) != 2 || fields[5].Items[1].ID != 22 {
+		t.Errorf("unexpected multiple list items: %+v", fields[5].Items)
+	}
+}
+
+func TestCustomField_MarshalJSON_RoundTrip(t *testing.T) {
+	var fields []*CustomField
+	if err := json.Unmarshal([]byte(customFieldsJSON), &fields); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded []*CustomField
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(fields, decoded) {
+		t.Errorf("custom fields changed after round trip:\n%s", data)
+	}
+}
+
+func TestAPIClient_GetIssues_CustomFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		want := map[string][]string{
+			"customField_1":     {"ACME"},
+			"customField_3_min": {"1"},
+			"customField_3_max": {"3"},
+			"customField_5[]":   {"11", "12"},
+		}
+		for name, values := range want {
+			if got := q[name]; !reflect.DeepEqual(got, values) {
+				t.Errorf("%s: expected %v, got %v", name, values, got)
+			}
+		}
+		if strings.HasSuffix(r.URL.Path, "/count") {
+			w.Write([]byte(`{"count": 1}`))
+			return
+		}
+		w.Write([]byte(`[{"id": 1, "issueKey": "MYPROJ-1", "customFields": ` + customFieldsJSON + `}]`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTPClient(server.URL+"/api/v2", "test-api-key", server.Client())
+
+	query := IssueQuery{
+		ProjectID: 1,
+		CustomFields: []CustomFieldQuery{
+			{ID: 1, Keyword: "ACME"},
+			{ID: 3, Min: "1", Max: "3"},
+			{ID: 5, ItemIDs: []int{11, 12}},
+		},
+	}
+	issues, err := client.GetIssues(context.Background(), query, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(issues) != 1 || len(issues[0].CustomFields) != 8 || issues[0].CustomFields[4].Items[0].Name != "設計" {
+		t.Errorf("unexpected custom fields: %+v", issues)
+	}
+}
@@ -0,0 +1,156 @@
+package backlog
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Backlog API のエラーコード
+// https://developer.nulab.com/docs/backlog/error-response/
+const (
+	ErrorCodeInternal              = 1
+	ErrorCodeLicence               = 2
+	ErrorCodeLicenceExpired        = 3
+	ErrorCodeAccessDenied          = 4
+	ErrorCodeUnauthorizedOperation = 5
+	ErrorCodeNoResource            = 6
+	ErrorCodeInvalidRequest        = 7
+	ErrorCodeSpaceOverCapacity     = 8
+	ErrorCodeResourceOverflow      = 9
+	ErrorCodeTooLargeFile          = 10
+	ErrorCodeAuthentication        = 11
+	ErrorCodeRequiredMFA           = 12
+	ErrorCodeTooManyRequests       = 13
+)
+
+// APIError はBacklog APIからのエラーレスポンスを表す
+type APIError struct {
+	// StatusCode はHTTPステータスコード
+	StatusCode int `json:"-"`
+	// Body はエラーレスポンスがJSONとして解釈できなかった場合の本文
+	Body   string `json:"-"`
+	Errors []struct {
+		Message  string `json:"message"`
+		Code     int    `json:"code"`
+		MoreInfo string `json:"moreInfo"`
+	} `json:"errors"`
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) > 0 {
+		return e.Errors[0].Message
+	}
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+	}
+	return "unknown API error"
+}
+
+// Codes はレスポンスに含まれるBacklogのエラーコード一覧を返す
+func (e *APIError) Codes() []int {
+	codes := make([]int, 0, len(e.Errors))
+	for _, detail := range e.Errors {
+		codes = append(codes, detail.Code)
+	}
+	return codes
+}
+
+// HasCode は指定したBacklogのエラーコードを含むかどうかを判定する
+func (e *APIError) HasCode(code int) bool {
+	for _, detail := range e.Errors {
+		if detail.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthError は認証に失敗したことを表す
+type AuthError struct {
+	Err *APIError
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("authentication failed: %s", e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// NotFoundError は指定したリソースが存在しないことを表す
+type NotFoundError struct {
+	Err *APIError
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("not found: %s", e.Err)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// RateLimitError はレート制限を超過したことを表す
+type RateLimitError struct {
+	// Reset はレート制限が解除される時刻（不明な場合はゼロ値）
+	Reset time.Time
+	Err   *APIError
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return fmt.Sprintf("rate limit exceeded: %s", e.Err)
+	}
+	return fmt.Sprintf("rate limit exceeded until %s: %s", e.Reset.Format(time.RFC3339), e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// NetworkError はAPIサーバーとの通信に失敗したことを表す
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("network error: %s", e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// newResponseError はエラーレスポンスを種類に応じた型付きエラーに変換する
+func newResponseError(apiErr *APIError, header http.Header) error {
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized, apiErr.HasCode(ErrorCodeAuthentication):
+		return &AuthError{Err: apiErr}
+	case apiErr.StatusCode == http.StatusNotFound, apiErr.HasCode(ErrorCodeNoResource):
+		return &NotFoundError{Err: apiErr}
+	case apiErr.StatusCode == http.StatusTooManyRequests, apiErr.HasCode(ErrorCodeTooManyRequests):
+		rle := &RateLimitError{Err: apiErr}
+		if _, reset, ok := parseRateLimit(header); ok {
+			rle.Reset = reset
+		}
+		return rle
+	default:
+		return apiErr
+	}
+}
+
+// IsRetryable は再試行で回復する可能性のあるエラーかどうかを判定する
+func IsRetryable(err error) bool {
+	var netErr *NetworkError
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+	return false
+}
@@ -1,21 +1,119 @@
 package backlog
 
-import "context"
+import (
+	"context"
+	"io"
+	"time"
+)
 
 // Client はBacklog APIクライアントのインターフェース
 type Client interface {
 	// GetProject はプロジェクト情報を取得する
 	GetProject(ctx context.Context, projectIDOrKey string) (*Project, error)
 
+	// GetProjects は参加しているプロジェクトの一覧を取得する（アーカイブ済みを除く）
+	GetProjects(ctx context.Context) ([]*Project, error)
+
+	// GetMyself はAPIキーの所有者のユーザー情報を取得する
+	GetMyself(ctx context.Context) (*User, error)
+
+	// GetProjectUsers はプロジェクトの参加ユーザー一覧を取得する
+	GetProjectUsers(ctx context.Context, projectIDOrKey string) ([]*User, error)
+
 	// GetStatuses はプロジェクトの状態一覧を取得する
 	GetStatuses(ctx context.Context, projectIDOrKey string) ([]*Status, error)
 
-	// GetIssues は課題一覧を取得する（ページネーション処理済み）
-	// statusIDs に指定した状態の課題のみ取得
-	// assigneeID が指定された場合は担当者でフィルタリング
+	// GetIssueTypes はプロジェクトの課題種別一覧を取得する
+	GetIssueTypes(ctx context.Context, projectIDOrKey string) ([]*IssueType, error)
+
+	// GetCategories はプロジェクトのカテゴリー一覧を取得する
+	GetCategories(ctx context.Context, projectIDOrKey string) ([]*Category, error)
+
+	// GetVersions はプロジェクトのバージョン（マイルストーン）一覧を取得する
+	GetVersions(ctx context.Context, projectIDOrKey string) ([]*Version, error)
+
+	// GetPriorities は優先度の一覧を取得する
+	GetPriorities(ctx context.Context) ([]*Priority, error)
+
+	// GetCustomFields はプロジェクトのカスタム属性の一覧を取得する
+	GetCustomFields(ctx context.Context, projectIDOrKey string) ([]*CustomFieldDefinition, error)
+
+	// GetIssues は query の条件に一致する課題一覧を取得する（ページネーション処理済み）
 	// progressFn は進捗状況を通知するコールバック（nil可）
-	GetIssues(ctx context.Context, projectID int, statusIDs []int, assigneeID *int, progressFn func(fetched, total int)) ([]*Issue, error)
+	GetIssues(ctx context.Context, query IssueQuery, progressFn func(fetched, total int)) ([]*Issue, error)
+
+	// WalkIssues は query の条件に一致する課題を1ページずつ取得して pageFn に渡す
+	// pageFn がエラーを返した場合は取得を中断してそのエラーを返す
+	WalkIssues(ctx context.Context, query IssueQuery, pageFn func(issues []*Issue) error, progressFn func(fetched, total int)) error
+
+	// GetIssue は課題を1件取得する（完了した課題も取得できる）
+	GetIssue(ctx context.Context, issueIDOrKey string) (*Issue, error)
+
+	// GetComments は課題のコメント一覧を古い順に取得する（ページネーション処理済み）
+	GetComments(ctx context.Context, issueIDOrKey string) ([]*Comment, error)
+
+	// GetAttachments は課題の添付ファイル一覧を取得する
+	GetAttachments(ctx context.Context, issueIDOrKey string) ([]*Attachment, error)
+
+	// DownloadAttachment は課題の添付ファイルをダウンロードして w に書き込む
+	DownloadAttachment(ctx context.Context, issueIDOrKey string, attachmentID int, w io.Writer) error
 }
 
 // ProgressCallback は進捗を通知するコールバック関数の型
 type ProgressCallback func(fetched, total int)
+
+// ParentChild は課題一覧の親子関係による絞り込みを表す
+type ParentChild int
+
+const (
+	// ParentChildAll はすべての課題
+	ParentChildAll ParentChild = 0
+	// ParentChildNotChild は子課題以外
+	ParentChildNotChild ParentChild = 1
+	// ParentChildChild は子課題のみ
+	ParentChildChild ParentChild = 2
+	// ParentChildStandalone は親課題でも子課題でもない課題のみ
+	ParentChildStandalone ParentChild = 3
+	// ParentChildParent は親課題のみ
+	ParentChildParent ParentChild = 4
+)
+
+// IssueQuery は課題一覧の取得条件を表す
+// ID の一覧が指定された条件は、いずれかに一致する課題のみ取得する
+// 日付の条件は日単位で、Since・Until の日を含む
+type IssueQuery struct {
+	ProjectID int
+	// StatusIDs が指定された場合は、その状態の課題のみ取得する
+	StatusIDs []int
+	// AssigneeIDs が指定された場合は、いずれかのユーザーが担当者の課題のみ取得する
+	AssigneeIDs  []int
+	IssueTypeIDs []int
+	CategoryIDs  []int
+	// MilestoneIDs はマイルストーン、VersionIDs は発生バージョンのバージョンID
+	MilestoneIDs []int
+	VersionIDs   []int
+	PriorityIDs  []int
+	// Keyword が指定された場合は、件名・詳細・コメントにキーワードを含む課題のみ取得する
+	Keyword      string
+	CreatedSince *time.Time
+	CreatedUntil *time.Time
+	// UpdatedSince が指定された場合は、その日以降に更新された課題のみ取得する
+	UpdatedSince *time.Time
+	UpdatedUntil *time.Time
+	DueDateSince *time.Time
+	DueDateUntil *time.Time
+	ParentChild  ParentChild
+	// CustomFields はカスタム属性による取得条件（すべてに一致する課題のみ取得する）
+	CustomFields []CustomFieldQuery
+}
+
+// CustomFieldQuery はカスタム属性による取得条件を表す
+// 種類に応じて、文字列・文章は Keyword、数値・日付は Min・Max、リスト形式は ItemIDs を指定する
+type CustomFieldQuery struct {
+	ID      int
+	Keyword string
+	ItemIDs []int
+	// Min・Max は数値または日付（YYYY-MM-DD）の範囲（指定値を含む）
+	Min string
+	Max string
+}
@@ -1,12 +1,28 @@
 package backlog
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 // MockClient はテスト用のモッククライアント
 type MockClient struct {
-	GetProjectFunc   func(ctx context.Context, projectIDOrKey string) (*Project, error)
-	GetStatusesFunc  func(ctx context.Context, projectIDOrKey string) ([]*Status, error)
-	GetIssuesFunc    func(ctx context.Context, projectID int, statusIDs []int, assigneeID *int, progressFn func(fetched, total int)) ([]*Issue, error)
+	GetProjectFunc         func(ctx context.Context, projectIDOrKey string) (*Project, error)
+	GetProjectsFunc        func(ctx context.Context) ([]*Project, error)
+	GetMyselfFunc          func(ctx context.Context) (*User, error)
+	GetProjectUsersFunc    func(ctx context.Context, projectIDOrKey string) ([]*User, error)
+	GetStatusesFunc        func(ctx context.Context, projectIDOrKey string) ([]*Status, error)
+	GetIssueTypesFunc      func(ctx context.Context, projectIDOrKey string) ([]*IssueType, error)
+	GetCategoriesFunc      func(ctx context.Context, projectIDOrKey string) ([]*Category, error)
+	GetVersionsFunc        func(ctx context.Context, projectIDOrKey string) ([]*Version, error)
+	GetPrioritiesFunc      func(ctx context.Context) ([]*Priority, error)
+	GetCustomFieldsFunc    func(ctx context.Context, projectIDOrKey string) ([]*CustomFieldDefinition, error)
+	GetIssuesFunc          func(ctx context.Context, query IssueQuery, progressFn func(fetched, total int)) ([]*Issue, error)
+	WalkIssuesFunc         func(ctx context.Context, query IssueQuery, pageFn func(issues []*Issue) error, progressFn func(fetched, total int)) error
+	GetIssueFunc           func(ctx context.Context, issueIDOrKey string) (*Issue, error)
+	GetCommentsFunc        func(ctx context.Context, issueIDOrKey string) ([]*Comment, error)
+	GetAttachmentsFunc     func(ctx context.Context, issueIDOrKey string) ([]*Attachment, error)
+	DownloadAttachmentFunc func(ctx context.Context, issueIDOrKey string, attachmentID int, w io.Writer) error
 }
 
 // GetProject はモック実装
@@ -17,6 +33,30 @@ func (m *MockClient) GetProject(ctx context.Context, projectIDOrKey string) (*Pr
 	return nil, nil
 }
 
+// GetProjects はモック実装
+func (m *MockClient) GetProjects(ctx context.Context) ([]*Project, error) {
+	if m.GetProjectsFunc != nil {
+		return m.GetProjectsFunc(ctx)
+	}
+	return nil, nil
+}
+
+// GetMyself はモック実装
+func (m *MockClient) GetMyself(ctx context.Context) (*User, error) {
+	if m.GetMyselfFunc != nil {
+		return m.GetMyselfFunc(ctx)
+	}
+	return nil, nil
+}
+
+// GetProjectUsers はモック実装
+func (m *MockClient) GetProjectUsers(ctx context.Context, projectIDOrKey string) ([]*User, error) {
+	if m.GetProjectUsersFunc != nil {
+		return m.GetProjectUsersFunc(ctx, projectIDOrKey)
+	}
+	return nil, nil
+}
+
 // GetStatuses はモック実装
 func (m *MockClient) GetStatuses(ctx context.Context, projectIDOrKey string) ([]*Status, error) {
 	if m.GetStatusesFunc != nil {
@@ -25,10 +65,95 @@ func (m *MockClient) GetStatuses(ctx context.Context, projectIDOrKey string) ([]
 	return nil, nil
 }
 
+// GetIssueTypes はモック実装
+func (m *MockClient) GetIssueTypes(ctx context.Context, projectIDOrKey string) ([]*IssueType, error) {
+	if m.GetIssueTypesFunc != nil {
+		return m.GetIssueTypesFunc(ctx, projectIDOrKey)
+	}
+	return nil, nil
+}
+
+// GetCategories はモック実装
+func (m *MockClient) GetCategories(ctx context.Context, projectIDOrKey string) ([]*Category, error) {
+	if m.GetCategoriesFunc != nil {
+		return m.GetCategoriesFunc(ctx, projectIDOrKey)
+	}
+	return nil, nil
+}
+
+// GetVersions はモック実装
+func (m *MockClient) GetVersions(ctx context.Context, projectIDOrKey string) ([]*Version, error) {
+	if m.GetVersionsFunc != nil {
+		return m.GetVersionsFunc(ctx, projectIDOrKey)
+	}
+	return nil, nil
+}
+
+// GetPriorities はモック実装
+func (m *MockClient) GetPriorities(ctx context.Context) ([]*Priority, error) {
+	if m.GetPrioritiesFunc != nil {
+		return m.GetPrioritiesFunc(ctx)
+	}
+	return nil, nil
+}
+
+// GetCustomFields はモック実装
+func (m *MockClient) GetCustomFields(ctx context.Context, projectIDOrKey string) ([]*CustomFieldDefinition, error) {
+	if m.GetCustomFieldsFunc != nil {
+		return m.GetCustomFieldsFunc(ctx, projectIDOrKey)
+	}
+	return nil, nil
+}
+
 // GetIssues はモック実装
-func (m *MockClient) GetIssues(ctx context.Context, projectID int, statusIDs []int, assigneeID *int, progressFn func(fetched, total int)) ([]*Issue, error) {
+func (m *MockClient) GetIssues(ctx context.Context, query IssueQuery, progressFn func(fetched, total int)) ([]*Issue, error) {
 	if m.GetIssuesFunc != nil {
-		return m.GetIssuesFunc(ctx, projectID, statusIDs, assigneeID, progressFn)
+		return m.GetIssuesFunc(ctx, query, progressFn)
 	}
 	return nil, nil
 }
+
+// WalkIssues はモック実装
+// WalkIssuesFunc が未設定の場合は GetIssuesFunc の結果を1ページとして渡す
+func (m *MockClient) WalkIssues(ctx context.Context, query IssueQuery, pageFn func(issues []*Issue) error, progressFn func(fetched, total int)) error {
+	if m.WalkIssuesFunc != nil {
+		return m.WalkIssuesFunc(ctx, query, pageFn, progressFn)
+	}
+	issues, err := m.GetIssues(ctx, query, progressFn)
+	if err != nil {
+		return err
+	}
+	return pageFn(issues)
+}
+
+// GetIssue はモック実装
+func (m *MockClient) GetIssue(ctx context.Context, issueIDOrKey string) (*Issue, error) {
+	if m.GetIssueFunc != nil {
+		return m.GetIssueFunc(ctx, issueIDOrKey)
+	}
+	return nil, nil
+}
+
+// GetComments はモック実装
+func (m *MockClient) GetComments(ctx context.Context, issueIDOrKey string) ([]*Comment, error) {
+	if m.GetCommentsFunc != nil {
+		return m.GetCommentsFunc(ctx, issueIDOrKey)
+	}
+	return nil, nil
+}
+
+// GetAttachments はモック実装
+func (m *MockClient) GetAttachments(ctx context.Context, issueIDOrKey string) ([]*Attachment, error) {
+	if m.GetAttachmentsFunc != nil {
+		return m.GetAttachmentsFunc(ctx, issueIDOrKey)
+	}
+	return nil, nil
+}
+
+// DownloadAttachment はモック実装
+func (m *MockClient) DownloadAttachment(ctx context.Context, issueIDOrKey string, attachmentID int, w io.Writer) error {
+	if m.DownloadAttachmentFunc != nil {
+		return m.DownloadAttachmentFunc(ctx, issueIDOrKey, attachmentID, w)
+	}
+	return nil
+}
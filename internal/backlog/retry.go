@@ -0,0 +1,151 @@
+package backlog
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Backlog API のレート制限ヘッダー
+const (
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// RetryPolicy は一時的なエラー発生時の再試行方針を表す
+type RetryPolicy struct {
+	// MaxRetries は最初のリクエスト以降に再試行する最大回数（0で再試行しない）
+	MaxRetries int
+	// BaseDelay は指数バックオフの初期待機時間
+	BaseDelay time.Duration
+	// MaxDelay はバックオフ1回あたりの待機時間の上限
+	MaxDelay time.Duration
+	// MaxRateLimitWait はレート制限解除を待つ時間の上限
+	MaxRateLimitWait time.Duration
+}
+
+// DefaultRetryPolicy はデフォルトの再試行方針を返す
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:       5,
+		BaseDelay:        500 * time.Millisecond,
+		MaxDelay:         30 * time.Second,
+		MaxRateLimitWait: 5 * time.Minute,
+	}
+}
+
+// backoff は attempt 回目（0始まり）の再試行前に待つ時間をジッター付きで計算する
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 0; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	// 待機時間の半分を固定、残り半分をランダムにして同時再試行の集中を避ける
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// rateLimitWait はレート制限解除までの待機時間を上限付きで返す
+func (p RetryPolicy) rateLimitWait(reset time.Time, now time.Time) time.Duration {
+	d := reset.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+	if p.MaxRateLimitWait > 0 && d > p.MaxRateLimitWait {
+		d = p.MaxRateLimitWait
+	}
+	return d
+}
+
+// isRetryableStatus は再試行すべきHTTPステータスかどうかを判定する
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// rateLimiter はレスポンスヘッダーから読み取ったレート制限の状態を保持する
+type rateLimiter struct {
+	mu        sync.Mutex
+	exhausted bool
+	reset     time.Time
+}
+
+// update はレスポンスヘッダーからレート制限の状態を更新する
+func (l *rateLimiter) update(h http.Header) {
+	remaining, reset, ok := parseRateLimit(h)
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exhausted = remaining <= 0
+	l.reset = reset
+}
+
+// waitDuration は次のリクエストを送る前に待つべき時間を返す
+func (l *rateLimiter) waitDuration(p RetryPolicy, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.exhausted {
+		return 0
+	}
+	if !now.Before(l.reset) {
+		l.exhausted = false
+		return 0
+	}
+	return p.rateLimitWait(l.reset, now)
+}
+
+// parseRateLimit は X-RateLimit-Remaining / X-RateLimit-Reset ヘッダーを解析する
+func parseRateLimit(h http.Header) (remaining int, reset time.Time, ok bool) {
+	remainingStr := h.Get(headerRateLimitRemaining)
+	resetStr := h.Get(headerRateLimitReset)
+	if remainingStr == "" || resetStr == "" {
+		return 0, time.Time{}, false
+	}
+
+	remaining, err := strconv.Atoi(remainingStr)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	resetUnix, err := strconv.ParseInt(resetStr, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	return remaining, time.Unix(resetUnix, 0), true
+}
+
+// sleepContext は指定時間待機する（コンテキストのキャンセルで中断）
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
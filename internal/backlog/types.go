@@ -1,14 +1,20 @@
 package backlog
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Project はBacklogプロジェクトを表す
 type Project struct {
-	ID              int    `json:"id"`
-	ProjectKey      string `json:"projectKey"`
-	Name            string `json:"name"`
-	ChartEnabled    bool   `json:"chartEnabled"`
-	SubtaskingEnabled bool `json:"subtaskingEnabled"`
+	ID                 int    `json:"id"`
+	ProjectKey         string `json:"projectKey"`
+	Name               string `json:"name"`
+	ChartEnabled       bool   `json:"chartEnabled"`
+	SubtaskingEnabled  bool   `json:"subtaskingEnabled"`
 	TextFormattingRule string `json:"textFormattingRule"`
 }
 
@@ -46,33 +52,312 @@ type IssueType struct {
 	DisplayOrder int    `json:"displayOrder"`
 }
 
+// Category は課題のカテゴリーを表す
+type Category struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	DisplayOrder int    `json:"displayOrder"`
+}
+
+// Version はプロジェクトのバージョン（マイルストーン）を表す
+// 課題のマイルストーンと発生バージョンはどちらもこの一覧から選択する
+type Version struct {
+	ID             int     `json:"id"`
+	ProjectID      int     `json:"projectId"`
+	Name           string  `json:"name"`
+	Description    string  `json:"description"`
+	StartDate      *string `json:"startDate"`
+	ReleaseDueDate *string `json:"releaseDueDate"`
+	Archived       bool    `json:"archived"`
+	DisplayOrder   int     `json:"displayOrder"`
+}
+
+// Resolution は課題の完了理由を表す
+type Resolution struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// CustomFieldType はカスタム属性の種類を表す
+type CustomFieldType int
+
+const (
+	// CustomFieldText は文字列
+	CustomFieldText CustomFieldType = 1
+	// CustomFieldTextArea は文章
+	CustomFieldTextArea CustomFieldType = 2
+	// CustomFieldNumeric は数値
+	CustomFieldNumeric CustomFieldType = 3
+	// CustomFieldDate は日付
+	CustomFieldDate CustomFieldType = 4
+	// CustomFieldSingleList は単一リスト
+	CustomFieldSingleList CustomFieldType = 5
+	// CustomFieldMultipleList は複数リスト
+	CustomFieldMultipleList CustomFieldType = 6
+	// CustomFieldCheckbox はチェックボックス
+	CustomFieldCheckbox CustomFieldType = 7
+	// CustomFieldRadio はラジオ
+	CustomFieldRadio CustomFieldType = 8
+)
+
+// customFieldTypeNames はカスタム属性の種類の名前
+var customFieldTypeNames = map[CustomFieldType]string{
+	CustomFieldText:         "text",
+	CustomFieldTextArea:     "textArea",
+	CustomFieldNumeric:      "numeric",
+	CustomFieldDate:         "date",
+	CustomFieldSingleList:   "singleList",
+	CustomFieldMultipleList: "multipleList",
+	CustomFieldCheckbox:     "checkbox",
+	CustomFieldRadio:        "radio",
+}
+
+// String はカスタム属性の種類の名前を返す（JSON出力で使用する）
+func (t CustomFieldType) String() string {
+	if name, ok := customFieldTypeNames[t]; ok {
+		return name
+	}
+	return strconv.Itoa(int(t))
+}
+
+// IsList はリストから値を選択する種類（単一・複数リスト、チェックボックス、ラジオ）かどうかを返す
+func (t CustomFieldType) IsList() bool {
+	return t >= CustomFieldSingleList && t <= CustomFieldRadio
+}
+
+// multiple は複数の項目を選択できる種類かどうかを返す
+func (t CustomFieldType) multiple() bool {
+	return t == CustomFieldMultipleList || t == CustomFieldCheckbox
+}
+
+// CustomFieldItem はリスト形式のカスタム属性の選択肢を表す
+type CustomFieldItem struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	DisplayOrder int    `json:"displayOrder"`
+}
+
+// CustomFieldDefinition はプロジェクトに定義されたカスタム属性を表す
+type CustomFieldDefinition struct {
+	ID          int                `json:"id"`
+	TypeID      CustomFieldType    `json:"typeId"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Required    bool               `json:"required"`
+	Items       []*CustomFieldItem `json:"items"`
+}
+
+// CustomField は課題のカスタム属性の値を表す
+// APIの value は種類によって型が異なるため、種類に応じたフィールドにデコードする
+type CustomField struct {
+	ID          int
+	FieldTypeID CustomFieldType
+	Name        string
+	// Text は文字列・文章の値
+	Text *string
+	// Number は数値の値
+	Number *float64
+	// Date は日付の値（YYYY-MM-DD）
+	Date *string
+	// Items はリスト・チェックボックス・ラジオで選択された項目
+	Items []*CustomFieldItem
+	// OtherValue はチェックボックス・ラジオの「その他」に入力された値
+	OtherValue *string
+}
+
+// customFieldJSON はAPIのカスタム属性の形式
+type customFieldJSON struct {
+	ID          int             `json:"id"`
+	FieldTypeID CustomFieldType `json:"fieldTypeId"`
+	Name        string          `json:"name"`
+	Value       json.RawMessage `json:"value"`
+	OtherValue  *string         `json:"otherValue,omitempty"`
+}
+
+// UnmarshalJSON はAPIのカスタム属性を種類に応じてデコードする
+func (f *CustomField) UnmarshalJSON(data []byte) error {
+	var raw customFieldJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*f = CustomField{ID: raw.ID, FieldTypeID: raw.FieldTypeID, Name: raw.Name, OtherValue: raw.OtherValue}
+
+	value := strings.TrimSpace(string(raw.Value))
+	if value == "" || value == "null" {
+		return nil
+	}
+
+	switch {
+	case f.FieldTypeID == CustomFieldNumeric:
+		// 数値は文字列で返される場合もある
+		var s string
+		if json.Unmarshal(raw.Value, &s) == nil {
+			if s == "" {
+				return nil
+			}
+			raw.Value = json.RawMessage(s)
+		}
+		var n float64
+		if err := json.Unmarshal(raw.Value, &n); err != nil {
+			return fmt.Errorf("custom field %q: invalid numeric value: %s", f.Name, value)
+		}
+		f.Number = &n
+	case f.FieldTypeID == CustomFieldDate:
+		var s string
+		if err := json.Unmarshal(raw.Value, &s); err != nil {
+			return fmt.Errorf("custom field %q: invalid date value: %s", f.Name, value)
+		}
+		if len(s) > len("2006-01-02") {
+			s = s[:len("2006-01-02")]
+		}
+		if s != "" {
+			f.Date = &s
+		}
+	case f.FieldTypeID.IsList():
+		// 単一リスト・ラジオはオブジェクト、複数リスト・チェックボックスは配列
+		if value[0] == '[' {
+			if err := json.Unmarshal(raw.Value, &f.Items); err != nil {
+				return fmt.Errorf("custom field %q: invalid items: %w", f.Name, err)
+			}
+		} else {
+			var item CustomFieldItem
+			if err := json.Unmarshal(raw.Value, &item); err != nil {
+				return fmt.Errorf("custom field %q: invalid item: %w", f.Name, err)
+			}
+			f.Items = []*CustomFieldItem{&item}
+		}
+	default:
+		var s string
+		if err := json.Unmarshal(raw.Value, &s); err != nil {
+			// 未知の種類はJSONの値をそのまま文字列として保持する
+			s = value
+		}
+		f.Text = &s
+	}
+	return nil
+}
+
+// MarshalJSON はAPIと同じ形式でカスタム属性をエンコードする（状態ファイルの読み書きで使用する）
+func (f CustomField) MarshalJSON() ([]byte, error) {
+	raw := customFieldJSON{ID: f.ID, FieldTypeID: f.FieldTypeID, Name: f.Name, OtherValue: f.OtherValue}
+
+	var value any
+	switch {
+	case f.Number != nil:
+		value = *f.Number
+	case f.Date != nil:
+		value = *f.Date
+	case f.Text != nil:
+		value = *f.Text
+	case f.FieldTypeID.IsList() && f.FieldTypeID.multiple():
+		items := f.Items
+		if items == nil {
+			items = []*CustomFieldItem{}
+		}
+		value = items
+	case len(f.Items) > 0:
+		value = f.Items[0]
+	}
+
+	var err error
+	if raw.Value, err = json.Marshal(value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(raw)
+}
+
+// IsEmpty は値が設定されていないかどうかを返す
+func (f *CustomField) IsEmpty() bool {
+	return f.Text == nil && f.Number == nil && f.Date == nil && len(f.Items) == 0 && f.OtherValue == nil
+}
+
+// ItemNames は選択された項目の名前を返す（「その他」の値を含む）
+func (f *CustomField) ItemNames() []string {
+	names := make([]string, 0, len(f.Items)+1)
+	for _, item := range f.Items {
+		names = append(names, item.Name)
+	}
+	if f.OtherValue != nil && *f.OtherValue != "" {
+		names = append(names, *f.OtherValue)
+	}
+	return names
+}
+
+// String は値を表示用の文字列にする（値がない場合は空文字列）
+// 複数の項目は ", " で区切る
+func (f *CustomField) String() string {
+	switch {
+	case f.Text != nil:
+		return *f.Text
+	case f.Number != nil:
+		return strconv.FormatFloat(*f.Number, 'f', -1, 64)
+	case f.Date != nil:
+		return *f.Date
+	}
+	return strings.Join(f.ItemNames(), ", ")
+}
+
 // Issue はBacklog課題を表す
 type Issue struct {
-	ID             int        `json:"id"`
-	ProjectID      int        `json:"projectId"`
-	IssueKey       string     `json:"issueKey"`
-	KeyID          int        `json:"keyId"`
-	IssueType      *IssueType `json:"issueType"`
-	Summary        string     `json:"summary"`
-	Description    string     `json:"description"`
-	Priority       *Priority  `json:"priority"`
-	Status         *Status    `json:"status"`
-	Assignee       *User      `json:"assignee"`
-	StartDate      *string    `json:"startDate"`
-	DueDate        *string    `json:"dueDate"`
-	EstimatedHours *float64   `json:"estimatedHours"`
-	ActualHours    *float64   `json:"actualHours"`
-	ParentIssueID  *int       `json:"parentIssueId"`
-	CreatedUser    *User      `json:"createdUser"`
-	Created        time.Time  `json:"created"`
-	UpdatedUser    *User      `json:"updatedUser"`
-	Updated        time.Time  `json:"updated"`
+	ID             int            `json:"id"`
+	ProjectID      int            `json:"projectId"`
+	IssueKey       string         `json:"issueKey"`
+	KeyID          int            `json:"keyId"`
+	IssueType      *IssueType     `json:"issueType"`
+	Summary        string         `json:"summary"`
+	Description    string         `json:"description"`
+	Priority       *Priority      `json:"priority"`
+	Status         *Status        `json:"status"`
+	Resolution     *Resolution    `json:"resolution"`
+	Assignee       *User          `json:"assignee"`
+	StartDate      *string        `json:"startDate"`
+	DueDate        *string        `json:"dueDate"`
+	EstimatedHours *float64       `json:"estimatedHours"`
+	ActualHours    *float64       `json:"actualHours"`
+	ParentIssueID  *int           `json:"parentIssueId"`
+	Category       []*Category    `json:"category"`
+	Versions       []*Version     `json:"versions"`
+	Milestone      []*Version     `json:"milestone"`
+	CustomFields   []*CustomField `json:"customFields"`
+	CreatedUser    *User          `json:"createdUser"`
+	Created        time.Time      `json:"created"`
+	UpdatedUser    *User          `json:"updatedUser"`
+	Updated        time.Time      `json:"updated"`
+}
+
+// Comment は課題のコメントを表す
+type Comment struct {
+	ID          int       `json:"id"`
+	Content     string    `json:"content"`
+	CreatedUser *User     `json:"createdUser"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+// Attachment は課題の添付ファイルを表す
+type Attachment struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`
+	CreatedUser *User     `json:"createdUser"`
+	Created     time.Time `json:"created"`
+}
+
+// ExportedAttachment はローカルに保存した添付ファイルを表す
+type ExportedAttachment struct {
+	Attachment *Attachment `json:"attachment"`
+	// Path は出力ディレクトリからの相対パス（区切り文字は "/"）
+	Path string `json:"path"`
 }
 
 // HierarchicalIssue は親子関係を持つ課題を表す
 type HierarchicalIssue struct {
-	Issue    *Issue
-	Children []*HierarchicalIssue
+	Issue       *Issue
+	Children    []*HierarchicalIssue
+	Comments    []*Comment
+	Attachments []*ExportedAttachment
+	// ContextOnly は取得条件に一致せず、子課題の親として取得した課題かどうか（--with-parents、件数に含めない）
+	ContextOnly bool
 }
 
 // ExportData はエクスポートデータを表す
@@ -81,6 +366,30 @@ type ExportData struct {
 	ExportedAt time.Time
 	Summary    ExportSummary
 	Issues     []*HierarchicalIssue
+	// GroupBy は課題の分類方法（--group-by、複数の場合は入れ子に分類する。分類しない場合は空）
+	GroupBy []string
+	// Groups は GroupBy で分類した課題（分類しない場合は nil）
+	// 複数のマイルストーンなどが設定された課題はそれぞれのグループに含まれる
+	Groups []*IssueGroup
+}
+
+// IssueGroup は分類した課題のグループを表す
+type IssueGroup struct {
+	// Name はグループ名（値が設定されていない課題のグループは "(未設定)"）
+	Name    string
+	Summary ExportSummary
+	// Issues はグループに含まれるルート課題（子課題は親課題の下に含まれる）
+	// さらに分類する場合は nil で、Groups に下位のグループが入る
+	Issues []*HierarchicalIssue
+	// Groups は次の分類方法で分けた下位のグループ（最後の分類方法のグループでは nil）
+	Groups []*IssueGroup
+}
+
+// MultiExportData は複数プロジェクトをまとめたエクスポートデータを表す
+type MultiExportData struct {
+	ExportedAt time.Time
+	Summary    ExportSummary
+	Projects   []*ExportData
 }
 
 // ExportSummary はエクスポートのサマリーを表す
@@ -89,19 +398,3 @@ type ExportSummary struct {
 	ParentIssues int `json:"parentIssues"`
 	ChildIssues  int `json:"childIssues"`
 }
-
-// APIError はBacklog APIからのエラーレスポンスを表す
-type APIError struct {
-	Errors []struct {
-		Message  string `json:"message"`
-		Code     int    `json:"code"`
-		MoreInfo string `json:"moreInfo"`
-	} `json:"errors"`
-}
-
-func (e *APIError) Error() string {
-	if len(e.Errors) > 0 {
-		return e.Errors[0].Message
-	}
-	return "unknown API error"
-}
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 	"unicode"
 )
 
@@ -15,8 +18,55 @@ const (
 	FormatTXT      OutputFormat = "txt"
 	FormatJSON     OutputFormat = "json"
 	FormatMarkdown OutputFormat = "markdown"
+	FormatCSV      OutputFormat = "csv"
+	FormatTSV      OutputFormat = "tsv"
+	FormatXLSX     OutputFormat = "xlsx"
+	FormatHTML     OutputFormat = "html"
+	// FormatTemplate は --template のテンプレートで出力する
+	FormatTemplate OutputFormat = "template"
+	// FormatNDJSON は1行に1課題のJSONを、取得しながら順に書き出す
+	FormatNDJSON OutputFormat = "ndjson"
+	// FormatSQLite はSQLiteのデータベースに書き込む（再実行すると同じファイルを更新する）
+	FormatSQLite OutputFormat = "sqlite"
 )
 
+// 親子関係による取得条件（--parent-child）
+const (
+	ParentChildAll        = "all"
+	ParentChildNotChild   = "not-child"
+	ParentChildChild      = "child"
+	ParentChildStandalone = "standalone"
+	ParentChildParent     = "parent"
+)
+
+// 課題の分類方法（--group-by）
+const (
+	GroupByMilestone = "milestone"
+	GroupByCategory  = "category"
+	GroupByVersion   = "version"
+	GroupByAssignee  = "assignee"
+	GroupByStatus    = "status"
+	GroupByPriority  = "priority"
+	GroupByIssueType = "issueType"
+	GroupByDueWeek   = "dueWeek"
+)
+
+// 課題の並び順（--sort）
+const (
+	SortDueDate  = "dueDate"
+	SortPriority = "priority"
+	SortUpdated  = "updated"
+	SortStatus   = "status"
+	SortAssignee = "assignee"
+	SortKeyID    = "keyId"
+)
+
+// ErrAPIKeyRequired はAPIキーが設定されていないことを表す
+var ErrAPIKeyRequired = errors.New("API key is required. Set --api-key or BACKLOG_API_KEY")
+
+// OutputStdout は Output に指定するとレポートを標準出力に書き出す値（--output -）
+const OutputStdout = "-"
+
 // Config はCLIの設定を表す
 type Config struct {
 	APIKey   string
@@ -26,40 +76,284 @@ type Config struct {
 	Output   string
 	Format   OutputFormat
 	Assignee *int
+	// Assignees は担当者の指定（me、none、ユーザーID、ログインID、名前、メールアドレス）
+	// Assignee と併用した場合はどちらかが担当者の課題を取得する
+	Assignees []string
+	// Projects は複数のプロジェクトIDまたはキー（指定時は Project より優先）
+	Projects []string
+	// AllProjects が true の場合は参加しているすべてのプロジェクトを対象にする
+	AllProjects bool
+	// Combined が true の場合は複数プロジェクトを1つのレポートにまとめる
+	Combined bool
+	// Concurrency は複数プロジェクトを同時に取得する数（0の場合はデフォルト）
+	Concurrency int
+	// MaxRetries は一時的なエラー時の最大再試行回数（nilの場合はデフォルト）
+	MaxRetries *int
+	// WithComments が true の場合は課題のコメントも出力する
+	WithComments bool
+	// WithAttachments が true の場合は添付ファイルを出力先に保存する
+	WithAttachments bool
+	// WithParents が true の場合は取得条件に一致しない親課題（完了を含む）も取得して階層を補う
+	WithParents bool
+	// IncludeStatuses は取得対象とする状態（名前またはID）
+	IncludeStatuses []string
+	// ExcludeStatuses は取得対象から除外する状態（名前またはID）
+	// IncludeStatuses と共に未指定の場合は「完了」を除外する
+	ExcludeStatuses []string
+	// AllStatuses が true の場合は完了を含むすべての状態を取得する
+	AllStatuses bool
+	// IssueTypes・Categories・Milestones・Versions・Priorities は取得対象とする
+	// 課題種別・カテゴリー・マイルストーン・発生バージョン・優先度（名前またはID）
+	IssueTypes []string
+	Categories []string
+	Milestones []string
+	Versions   []string
+	Priorities []string
+	// Keyword は件名・詳細・コメントに含まれるキーワード
+	Keyword string
+	// CreatedSince などは作成日・更新日・期限日による取得条件（YYYY-MM-DD、指定日を含む）
+	CreatedSince string
+	CreatedUntil string
+	UpdatedSince string
+	UpdatedUntil string
+	DueSince     string
+	DueUntil     string
+	// ParentChild は親子関係による取得条件（ParentChildAll など、空の場合はすべて）
+	ParentChild string
+	// CustomFields はカスタム属性による取得条件（"名前=値"、"名前>=値"、"名前<=値"）
+	CustomFields []string
+	// Where は取得した課題を絞り込む条件式（例: dueDate < today and estimatedHours == null）
+	Where string
+	// GroupBy は出力する課題の分類方法（GroupByMilestone など、複数指定した場合は入れ子に分類する。空の場合は分類しない）
+	GroupBy []string
+	// Sort は課題の並び順（"dueDate"、"priority:desc" など、先頭のキーを優先する。空の場合は取得順）
+	Sort []string
+	// BOM が true の場合はCSV・TSVの先頭にUTF-8のBOMを付ける（Excel向け）
+	BOM bool
+	// Columns はCSV・TSVに出力する列名（空の場合はデフォルトの列）
+	Columns []string
+	// OutputFile はレポートの出力先のファイルパス（指定した場合は Output のディレクトリと自動のファイル名の代わりに使う）
+	OutputFile string
+	// Template は FormatTemplate で使うテンプレートファイルのパスまたは組み込みテンプレートの名前（txt、markdown）
+	Template string
+	// Incremental が true の場合は前回の実行以降に更新された課題のみ取得して前回の結果に反映する
+	Incremental bool
+
+	// origins は設定ファイルから読み込んだ値の読み込み元（キー -> ファイル名:行番号）
+	origins map[string]string
 }
 
-// Validate は設定を検証する
-func (c *Config) Validate() error {
+// ValidateConnection はAPIへの接続に必要な設定を検証する
+func (c *Config) ValidateConnection() error {
 	if c.APIKey == "" {
-		return errors.New("API key is required. Set --api-key or BACKLOG_API_KEY")
+		return ErrAPIKeyRequired
 	}
 	if c.Space == "" {
 		return errors.New("space is required. Use --space or -s")
 	}
-	if c.Project == "" {
-		return errors.New("project is required. Use --project or -p")
-	}
 	if c.Domain == "" {
 		c.Domain = "backlog.com"
 	}
+	if c.MaxRetries != nil && *c.MaxRetries < 0 {
+		return c.errorAt("max-retries", fmt.Errorf("invalid max retries: %d. Must be 0 or greater", *c.MaxRetries))
+	}
+	return nil
+}
+
+// Validate はエクスポートに必要な設定を検証する
+func (c *Config) Validate() error {
+	if err := c.ValidateConnection(); err != nil {
+		return err
+	}
+	if c.AllProjects {
+		if len(c.ProjectKeys()) > 0 {
+			return c.errorAt("all-projects", errors.New("--all-projects cannot be combined with --project"))
+		}
+	} else if len(c.ProjectKeys()) == 0 {
+		return errors.New("project is required. Use --project or -p")
+	}
+	if c.Concurrency < 0 {
+		return c.errorAt("concurrency", fmt.Errorf("invalid concurrency: %d. Must be 1 or greater", c.Concurrency))
+	}
 	if c.Output == "" {
 		c.Output = "./"
 	}
+	if c.Output == OutputStdout && c.OutputFile != "" {
+		return c.errorAt("output-file", errors.New("--output-file cannot be combined with --output -"))
+	}
+	if c.Output == OutputStdout && c.WithAttachments {
+		return c.errorAt("with-attachments", errors.New("--with-attachments cannot be used when writing to stdout"))
+	}
+	if (c.Output == OutputStdout || c.OutputFile != "") && !c.Combined && (c.AllProjects || len(c.ProjectKeys()) > 1) {
+		return errors.New("multiple projects require --combined when writing to stdout or --output-file")
+	}
 	if c.Format == "" {
 		c.Format = FormatTXT
 	}
 
+	if c.AllStatuses && (len(c.IncludeStatuses) > 0 || len(c.ExcludeStatuses) > 0) {
+		return c.errorAt("all-statuses", errors.New("--all-statuses cannot be combined with --include-status or --exclude-status"))
+	}
+
+	for _, d := range []struct{ key, value string }{
+		{"created-since", c.CreatedSince},
+		{"created-until", c.CreatedUntil},
+		{"updated-since", c.UpdatedSince},
+		{"updated-until", c.UpdatedUntil},
+		{"due-since", c.DueSince},
+		{"due-until", c.DueUntil},
+	} {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d.value); err != nil {
+			return c.errorAt(d.key, fmt.Errorf("invalid %s: %s. Use YYYY-MM-DD", d.key, d.value))
+		}
+	}
+
+	switch c.ParentChild {
+	case "", ParentChildAll, ParentChildNotChild, ParentChildChild, ParentChildStandalone, ParentChildParent:
+		// OK
+	default:
+		return c.errorAt("parent-child", fmt.Errorf("invalid parent-child: %s. Use all, not-child, child, standalone, or parent", c.ParentChild))
+	}
+
+	for _, spec := range c.CustomFields {
+		if _, err := ParseCustomFieldCondition(spec); err != nil {
+			return c.errorAt("custom-field", err)
+		}
+	}
+
+	for _, groupBy := range c.GroupBy {
+		switch groupBy {
+		case GroupByMilestone, GroupByCategory, GroupByVersion, GroupByAssignee, GroupByStatus, GroupByPriority, GroupByIssueType, GroupByDueWeek:
+			// OK
+		default:
+			return c.errorAt("group-by", fmt.Errorf("invalid group-by: %s. Use milestone, category, version, assignee, status, priority, issueType, or dueWeek", groupBy))
+		}
+	}
+
+	for _, spec := range c.Sort {
+		if _, err := ParseSortKey(spec); err != nil {
+			return c.errorAt("sort", err)
+		}
+	}
+
 	// フォーマットの検証
 	switch c.Format {
-	case FormatTXT, FormatJSON, FormatMarkdown:
+	case FormatTXT, FormatJSON, FormatMarkdown, FormatCSV, FormatTSV, FormatXLSX, FormatHTML:
 		// OK
+	case FormatTemplate:
+		if c.Template == "" {
+			return c.errorAt("format", errors.New("template format requires --template"))
+		}
+	case FormatNDJSON:
+		// 課題を保持せずに書き出すため、すべての課題が揃っている必要があるオプションは使えない
+		for _, o := range []struct {
+			key string
+			set bool
+		}{
+			{"group-by", len(c.GroupBy) > 0},
+			{"sort", len(c.Sort) > 0},
+			{"with-parents", c.WithParents},
+			{"incremental", c.Incremental},
+		} {
+			if o.set {
+				return c.errorAt(o.key, fmt.Errorf("--%s cannot be used with --format ndjson", o.key))
+			}
+		}
+	case FormatSQLite:
+		if c.Output == OutputStdout {
+			return c.errorAt("format", errors.New("sqlite format cannot be written to stdout. Use --output-file"))
+		}
 	default:
-		return fmt.Errorf("invalid format: %s. Use txt, json, or markdown", c.Format)
+		return c.errorAt("format", fmt.Errorf("invalid format: %s. Use txt, json, markdown, csv, tsv, xlsx, html, ndjson, sqlite, or template", c.Format))
 	}
 
 	return nil
 }
 
+// SortKey は課題の並び順のキーを表す
+type SortKey struct {
+	// Field は SortDueDate などの並べ替える項目
+	Field string
+	// Desc が true の場合は降順
+	Desc bool
+}
+
+// ParseSortKey は "項目"、"項目:asc"、"項目:desc" 形式の並び順の指定を解析する
+func ParseSortKey(spec string) (SortKey, error) {
+	field, order, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	key := SortKey{Field: field}
+
+	switch order {
+	case "", "asc":
+		// OK
+	case "desc":
+		key.Desc = true
+	default:
+		return SortKey{}, fmt.Errorf("invalid sort order: %s. Use asc or desc", order)
+	}
+
+	switch field {
+	case SortDueDate, SortPriority, SortUpdated, SortStatus, SortAssignee, SortKeyID:
+		return key, nil
+	default:
+		return SortKey{}, fmt.Errorf("invalid sort: %s. Use dueDate, priority, updated, status, assignee, or keyId", field)
+	}
+}
+
+// CustomFieldCondition はカスタム属性による取得条件の指定を表す
+type CustomFieldCondition struct {
+	// Field はカスタム属性の名前またはID
+	Field string
+	// Op は "="、">="、"<=" のいずれか
+	Op    string
+	Value string
+}
+
+// ParseCustomFieldCondition は "名前=値"、"名前>=値"、"名前<=値" 形式の取得条件を解析する
+func ParseCustomFieldCondition(spec string) (CustomFieldCondition, error) {
+	i := strings.Index(spec, "=")
+	if i < 0 {
+		return CustomFieldCondition{}, fmt.Errorf("invalid custom-field: %s. Use NAME=VALUE, NAME>=VALUE or NAME<=VALUE", spec)
+	}
+	cond := CustomFieldCondition{Field: spec[:i], Op: "=", Value: strings.TrimSpace(spec[i+1:])}
+	if strings.HasSuffix(cond.Field, ">") || strings.HasSuffix(cond.Field, "<") {
+		cond.Op = cond.Field[len(cond.Field)-1:] + "="
+		cond.Field = cond.Field[:len(cond.Field)-1]
+	}
+	cond.Field = strings.TrimSpace(cond.Field)
+	if cond.Field == "" || cond.Value == "" {
+		return CustomFieldCondition{}, fmt.Errorf("invalid custom-field: %s. Use NAME=VALUE, NAME>=VALUE or NAME<=VALUE", spec)
+	}
+	return cond, nil
+}
+
+// OutputDir は添付ファイルや差分取得の状態ファイルを保存するディレクトリを返す
+// --output-file ではそのファイルのディレクトリ、標準出力に書き出す場合はカレントディレクトリ
+func (c *Config) OutputDir() string {
+	switch {
+	case c.OutputFile != "":
+		return filepath.Dir(c.OutputFile)
+	case c.Output == OutputStdout:
+		return "."
+	default:
+		return c.Output
+	}
+}
+
+// ProjectKeys は対象のプロジェクトIDまたはキーの一覧を返す
+func (c *Config) ProjectKeys() []string {
+	if len(c.Projects) > 0 {
+		return c.Projects
+	}
+	if c.Project != "" {
+		return []string{c.Project}
+	}
+	return nil
+}
+
 // IsProjectID はプロジェクト指定が数値（ID）かどうかを判定する
 func (c *Config) IsProjectID() bool {
 	for _, r := range c.Project {
@@ -93,23 +387,165 @@ func LoadFromEnv() *Config {
 func (c *Config) Merge(other *Config) {
 	if other.APIKey != "" {
 		c.APIKey = other.APIKey
+		c.mergeOrigin(other, "api-key")
 	}
 	if other.Space != "" {
 		c.Space = other.Space
+		c.mergeOrigin(other, "space")
 	}
 	if other.Domain != "" {
 		c.Domain = other.Domain
+		c.mergeOrigin(other, "domain")
 	}
 	if other.Project != "" {
 		c.Project = other.Project
 	}
+	if len(other.Projects) > 0 {
+		c.Projects = other.Projects
+		c.mergeOrigin(other, "project")
+	}
+	if other.AllProjects {
+		c.AllProjects = true
+		c.mergeOrigin(other, "all-projects")
+	}
+	if other.Combined {
+		c.Combined = true
+		c.mergeOrigin(other, "combined")
+	}
+	if other.Concurrency > 0 {
+		c.Concurrency = other.Concurrency
+		c.mergeOrigin(other, "concurrency")
+	}
+	if other.OutputFile != "" {
+		c.OutputFile = other.OutputFile
+		c.mergeOrigin(other, "output-file")
+	}
 	if other.Output != "" {
 		c.Output = other.Output
+		c.mergeOrigin(other, "output")
 	}
 	if other.Format != "" {
 		c.Format = other.Format
+		c.mergeOrigin(other, "format")
+	}
+	if other.Template != "" {
+		c.Template = other.Template
+		c.mergeOrigin(other, "template")
 	}
 	if other.Assignee != nil {
 		c.Assignee = other.Assignee
+		c.mergeOrigin(other, "assignee")
+	}
+	if len(other.Assignees) > 0 {
+		c.Assignees = other.Assignees
+		c.mergeOrigin(other, "assignee")
+	}
+	if other.MaxRetries != nil {
+		c.MaxRetries = other.MaxRetries
+		c.mergeOrigin(other, "max-retries")
+	}
+	if other.WithComments {
+		c.WithComments = true
+		c.mergeOrigin(other, "with-comments")
+	}
+	if other.WithAttachments {
+		c.WithAttachments = true
+		c.mergeOrigin(other, "with-attachments")
+	}
+	if other.WithParents {
+		c.WithParents = true
+		c.mergeOrigin(other, "with-parents")
+	}
+	if len(other.IncludeStatuses) > 0 {
+		c.IncludeStatuses = other.IncludeStatuses
+		c.mergeOrigin(other, "include-status")
+	}
+	if len(other.ExcludeStatuses) > 0 {
+		c.ExcludeStatuses = other.ExcludeStatuses
+		c.mergeOrigin(other, "exclude-status")
+	}
+	if other.AllStatuses {
+		c.AllStatuses = true
+		c.mergeOrigin(other, "all-statuses")
+	}
+	if len(other.IssueTypes) > 0 {
+		c.IssueTypes = other.IssueTypes
+		c.mergeOrigin(other, "issue-type")
+	}
+	if len(other.Categories) > 0 {
+		c.Categories = other.Categories
+		c.mergeOrigin(other, "category")
+	}
+	if len(other.Milestones) > 0 {
+		c.Milestones = other.Milestones
+		c.mergeOrigin(other, "milestone")
+	}
+	if len(other.Versions) > 0 {
+		c.Versions = other.Versions
+		c.mergeOrigin(other, "affected-version")
+	}
+	if len(other.Priorities) > 0 {
+		c.Priorities = other.Priorities
+		c.mergeOrigin(other, "priority")
+	}
+	if other.Keyword != "" {
+		c.Keyword = other.Keyword
+		c.mergeOrigin(other, "keyword")
+	}
+	if other.CreatedSince != "" {
+		c.CreatedSince = other.CreatedSince
+		c.mergeOrigin(other, "created-since")
+	}
+	if other.CreatedUntil != "" {
+		c.CreatedUntil = other.CreatedUntil
+		c.mergeOrigin(other, "created-until")
+	}
+	if other.UpdatedSince != "" {
+		c.UpdatedSince = other.UpdatedSince
+		c.mergeOrigin(other, "updated-since")
+	}
+	if other.UpdatedUntil != "" {
+		c.UpdatedUntil = other.UpdatedUntil
+		c.mergeOrigin(other, "updated-until")
+	}
+	if other.DueSince != "" {
+		c.DueSince = other.DueSince
+		c.mergeOrigin(other, "due-since")
+	}
+	if other.DueUntil != "" {
+		c.DueUntil = other.DueUntil
+		c.mergeOrigin(other, "due-until")
+	}
+	if other.ParentChild != "" {
+		c.ParentChild = other.ParentChild
+		c.mergeOrigin(other, "parent-child")
+	}
+	if len(other.CustomFields) > 0 {
+		c.CustomFields = other.CustomFields
+		c.mergeOrigin(other, "custom-field")
+	}
+	if other.Where != "" {
+		c.Where = other.Where
+		c.mergeOrigin(other, "where")
+	}
+	if len(other.GroupBy) > 0 {
+		c.GroupBy = other.GroupBy
+		c.mergeOrigin(other, "group-by")
+	}
+	if len(other.Sort) > 0 {
+		c.Sort = other.Sort
+		c.mergeOrigin(other, "sort")
+	}
+	if other.BOM {
+		c.BOM = true
+		c.mergeOrigin(other, "bom")
+	}
+	if len(other.Columns) > 0 {
+		c.Columns = other.Columns
+		c.mergeOrigin(other, "columns")
+	}
+	if other.Incremental {
+		c.Incremental = true
+		c.mergeOrigin(other, "incremental")
 	}
 }
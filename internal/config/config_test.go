@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -54,6 +55,247 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "multiple projects",
+			config: &Config{
+				APIKey:   "test-key",
+				Space:    "mycompany",
+				Projects: []string{"ALPHA", "BETA"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "all projects",
+			config: &Config{
+				APIKey:      "test-key",
+				Space:       "mycompany",
+				AllProjects: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "all projects with project",
+			config: &Config{
+				APIKey:      "test-key",
+				Space:       "mycompany",
+				Project:     "MYPROJ",
+				AllProjects: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "all statuses with exclude",
+			config: &Config{
+				APIKey:          "test-key",
+				Space:           "mycompany",
+				Project:         "MYPROJ",
+				AllStatuses:     true,
+				ExcludeStatuses: []string{"完了"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "date filters",
+			config: &Config{
+				APIKey:       "test-key",
+				Space:        "mycompany",
+				Project:      "MYPROJ",
+				CreatedSince: "2024-11-01",
+				DueUntil:     "2024-12-31",
+				ParentChild:  ParentChildNotChild,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid date filter",
+			config: &Config{
+				APIKey:   "test-key",
+				Space:    "mycompany",
+				Project:  "MYPROJ",
+				DueUntil: "2024/12/31",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid parent-child",
+			config: &Config{
+				APIKey:      "test-key",
+				Space:       "mycompany",
+				Project:     "MYPROJ",
+				ParentChild: "children",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid custom field conditions",
+			config: &Config{
+				APIKey:       "test-key",
+				Space:        "mycompany",
+				Project:      "MYPROJ",
+				CustomFields: []string{"工程=設計", "見積>=2", "リリース日 <= 2024-12-31"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid custom field condition",
+			config: &Config{
+				APIKey:       "test-key",
+				Space:        "mycompany",
+				Project:      "MYPROJ",
+				CustomFields: []string{"工程"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid group-by and sort",
+			config: &Config{
+				APIKey:  "test-key",
+				Space:   "mycompany",
+				Project: "MYPROJ",
+				GroupBy: []string{GroupByMilestone, GroupByAssignee},
+				Sort:    []string{"priority", "dueDate:desc"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid group-by",
+			config: &Config{
+				APIKey:  "test-key",
+				Space:   "mycompany",
+				Project: "MYPROJ",
+				GroupBy: []string{"sprint"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid sort",
+			config: &Config{
+				APIKey:  "test-key",
+				Space:   "mycompany",
+				Project: "MYPROJ",
+				Sort:    []string{"created"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "template format",
+			config: &Config{
+				APIKey:   "test-key",
+				Space:    "mycompany",
+				Project:  "MYPROJ",
+				Format:   FormatTemplate,
+				Template: "weekly.md.tmpl",
+			},
+			wantErr: false,
+		},
+		{
+			name: "template format without template",
+			config: &Config{
+				APIKey:  "test-key",
+				Space:   "mycompany",
+				Project: "MYPROJ",
+				Format:  FormatTemplate,
+			},
+			wantErr: true,
+		},
+		{
+			name: "ndjson format",
+			config: &Config{
+				APIKey:       "test-key",
+				Space:        "mycompany",
+				Project:      "MYPROJ",
+				Format:       FormatNDJSON,
+				Where:        "dueDate < today",
+				WithComments: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "ndjson format with group-by",
+			config: &Config{
+				APIKey:  "test-key",
+				Space:   "mycompany",
+				Project: "MYPROJ",
+				Format:  FormatNDJSON,
+				GroupBy: []string{GroupByMilestone},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ndjson format with incremental",
+			config: &Config{
+				APIKey:      "test-key",
+				Space:       "mycompany",
+				Project:     "MYPROJ",
+				Format:      FormatNDJSON,
+				Incremental: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "sqlite format to stdout",
+			config: &Config{
+				APIKey:  "test-key",
+				Space:   "mycompany",
+				Project: "MYPROJ",
+				Format:  FormatSQLite,
+				Output:  OutputStdout,
+			},
+			wantErr: true,
+		},
+		{
+			name: "output to stdout",
+			config: &Config{
+				APIKey:  "test-key",
+				Space:   "mycompany",
+				Project: "MYPROJ",
+				Output:  OutputStdout,
+			},
+			wantErr: false,
+		},
+		{
+			name: "output to stdout with output file",
+			config: &Config{
+				APIKey:     "test-key",
+				Space:      "mycompany",
+				Project:    "MYPROJ",
+				Output:     OutputStdout,
+				OutputFile: "report.txt",
+			},
+			wantErr: true,
+		},
+		{
+			name: "output to stdout with attachments",
+			config: &Config{
+				APIKey:          "test-key",
+				Space:           "mycompany",
+				Project:         "MYPROJ",
+				Output:          OutputStdout,
+				WithAttachments: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "output file with multiple projects",
+			config: &Config{
+				APIKey:     "test-key",
+				Space:      "mycompany",
+				Projects:   []string{"ALPHA", "BETA"},
+				OutputFile: "report.txt",
+			},
+			wantErr: true,
+		},
+		{
+			name: "output file with combined projects",
+			config: &Config{
+				APIKey:     "test-key",
+				Space:      "mycompany",
+				Projects:   []string{"ALPHA", "BETA"},
+				Combined:   true,
+				OutputFile: "report.txt",
+			},
+			wantErr: false,
+		},
 		{
 			name: "valid with all formats",
 			config: &Config{
@@ -122,6 +364,38 @@ func TestConfig_IsProjectID(t *testing.T) {
 	}
 }
 
+func TestConfig_ProjectKeys(t *testing.T) {
+	single := &Config{Project: "MYPROJ"}
+	if keys := single.ProjectKeys(); len(keys) != 1 || keys[0] != "MYPROJ" {
+		t.Errorf("expected [MYPROJ], got %v", keys)
+	}
+
+	multi := &Config{Project: "MYPROJ", Projects: []string{"ALPHA", "BETA"}}
+	if keys := multi.ProjectKeys(); len(keys) != 2 || keys[0] != "ALPHA" {
+		t.Errorf("Projects should take precedence, got %v", keys)
+	}
+
+	if keys := (&Config{}).ProjectKeys(); len(keys) != 0 {
+		t.Errorf("expected no projects, got %v", keys)
+	}
+}
+
+func TestConfig_OutputDir(t *testing.T) {
+	tests := []struct {
+		config *Config
+		want   string
+	}{
+		{&Config{Output: "reports"}, "reports"},
+		{&Config{Output: "reports", OutputFile: filepath.Join("out", "weekly.md")}, "out"},
+		{&Config{Output: OutputStdout}, "."},
+	}
+	for _, tt := range tests {
+		if got := tt.config.OutputDir(); got != tt.want {
+			t.Errorf("OutputDir() = %q, want %q (%+v)", got, tt.want, tt.config)
+		}
+	}
+}
+
 func TestConfig_GetProjectID(t *testing.T) {
 	cfg := &Config{Project: "12345"}
 	id, err := cfg.GetProjectID()
@@ -203,3 +477,58 @@ func TestConfig_Merge(t *testing.T) {
 		t.Errorf("Assignee should be merged")
 	}
 }
+
+func TestParseCustomFieldCondition(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    CustomFieldCondition
+		wantErr bool
+	}{
+		{spec: "工程=設計", want: CustomFieldCondition{Field: "工程", Op: "=", Value: "設計"}},
+		{spec: "見積 >= 2.5", want: CustomFieldCondition{Field: "見積", Op: ">=", Value: "2.5"}},
+		{spec: "リリース日<=2024-12-31", want: CustomFieldCondition{Field: "リリース日", Op: "<=", Value: "2024-12-31"}},
+		{spec: "URL=https://example.com/?a=b", want: CustomFieldCondition{Field: "URL", Op: "=", Value: "https://example.com/?a=b"}},
+		{spec: "工程", wantErr: true},
+		{spec: "=設計", wantErr: true},
+		{spec: "工程=", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			got, err := ParseCustomFieldCondition(tc.spec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseCustomFieldCondition() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseSortKey(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    SortKey
+		wantErr bool
+	}{
+		{spec: "dueDate", want: SortKey{Field: SortDueDate}},
+		{spec: "priority:asc", want: SortKey{Field: SortPriority}},
+		{spec: " updated:desc ", want: SortKey{Field: SortUpdated, Desc: true}},
+		{spec: "keyId:down", wantErr: true},
+		{spec: "created", wantErr: true},
+		{spec: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			got, err := ParseSortKey(tc.spec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseSortKey() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profilesKey は設定ファイルでプロファイルを定義するキー
+const profilesKey = "profiles"
+
+// DefaultConfigPath はデフォルトの設定ファイルのパスを返す
+// XDG_CONFIG_HOME が設定されていればその配下、なければ ~/.config 配下
+func DefaultConfigPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "backlog-exporter", "config.yaml"), nil
+}
+
+// LoadFile はYAMLの設定ファイルを読み込む
+// トップレベルの設定を基本とし、profile が指定された場合は profiles 配下の
+// 同名のプロファイルで上書きする
+func LoadFile(path, profile string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	cfg := &Config{}
+	if len(doc.Content) == 0 {
+		// 空のファイル
+		if profile != "" {
+			return nil, fmt.Errorf("%s: profile %q not found", path, profile)
+		}
+		return cfg, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s:%d: config must be a mapping", path, root.Line)
+	}
+
+	var profiles *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value == profilesKey {
+			if value.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("%s:%d: %s must be a mapping of profile names", path, value.Line, profilesKey)
+			}
+			profiles = value
+			continue
+		}
+		if err := cfg.applyFileValue(path, key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if profile == "" {
+		return cfg, nil
+	}
+
+	node, names := findProfile(profiles, profile)
+	if node == nil {
+		if len(names) == 0 {
+			return nil, fmt.Errorf("%s: profile %q not found", path, profile)
+		}
+		return nil, fmt.Errorf("%s: profile %q not found. Available profiles: %s", path, profile, strings.Join(names, ", "))
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s:%d: profile %q must be a mapping", path, node.Line, profile)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if err := cfg.applyFileValue(path, node.Content[i], node.Content[i+1]); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// findProfile は名前に一致するプロファイルと、定義済みのプロファイル名一覧を返す
+func findProfile(profiles *yaml.Node, name string) (*yaml.Node, []string) {
+	if profiles == nil {
+		return nil, nil
+	}
+	var names []string
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		if profiles.Content[i].Value == name {
+			return profiles.Content[i+1], nil
+		}
+		names = append(names, profiles.Content[i].Value)
+	}
+	sort.Strings(names)
+	return nil, names
+}
+
+// applyFileValue は設定ファイルの1つのキーを設定に反映する
+// キー名はコマンドラインオプションの名前に合わせている
+func (c *Config) applyFileValue(path string, key, value *yaml.Node) error {
+	var err error
+	switch key.Value {
+	case "api-key":
+		err = decodeString(value, &c.APIKey)
+	case "space":
+		err = decodeString(value, &c.Space)
+	case "domain":
+		err = decodeString(value, &c.Domain)
+	case "project":
+		err = decodeList(value, &c.Projects)
+	case "all-projects":
+		err = decodeBool(value, &c.AllProjects)
+	case "combined":
+		err = decodeBool(value, &c.Combined)
+	case "concurrency":
+		err = decodeInt(value, &c.Concurrency)
+	case "output":
+		if err = decodeString(value, &c.Output); err == nil {
+			c.Output = expandHome(c.Output)
+		}
+	case "output-file":
+		if err = decodeString(value, &c.OutputFile); err == nil {
+			c.OutputFile = expandHome(c.OutputFile)
+		}
+	case "template":
+		if err = decodeString(value, &c.Template); err == nil {
+			c.Template = expandHome(c.Template)
+		}
+	case "format":
+		var format string
+		if err = decodeString(value, &format); err == nil {
+			c.Format = OutputFormat(format)
+		}
+	case "assignee":
+		err = decodeList(value, &c.Assignees)
+	case "max-retries":
+		var maxRetries int
+		if err = decodeInt(value, &maxRetries); err == nil {
+			c.MaxRetries = &maxRetries
+		}
+	case "with-comments":
+		err = decodeBool(value, &c.WithComments)
+	case "with-attachments":
+		err = decodeBool(value, &c.WithAttachments)
+	case "with-parents":
+		err = decodeBool(value, &c.WithParents)
+	case "include-status":
+		err = decodeList(value, &c.IncludeStatuses)
+	case "exclude-status":
+		err = decodeList(value, &c.ExcludeStatuses)
+	case "all-statuses":
+		err = decodeBool(value, &c.AllStatuses)
+	case "issue-type":
+		err = decodeList(value, &c.IssueTypes)
+	case "category":
+		err = decodeList(value, &c.Categories)
+	case "milestone":
+		err = decodeList(value, &c.Milestones)
+	case "affected-version":
+		err = decodeList(value, &c.Versions)
+	case "priority":
+		err = decodeList(value, &c.Priorities)
+	case "keyword":
+		err = decodeString(value, &c.Keyword)
+	case "created-since":
+		err = decodeString(value, &c.CreatedSince)
+	case "created-until":
+		err = decodeString(value, &c.CreatedUntil)
+	case "updated-since":
+		err = decodeString(value, &c.UpdatedSince)
+	case "updated-until":
+		err = decodeString(value, &c.UpdatedUntil)
+	case "due-since":
+		err = decodeString(value, &c.DueSince)
+	case "due-until":
+		err = decodeString(value, &c.DueUntil)
+	case "parent-child":
+		err = decodeString(value, &c.ParentChild)
+	case "custom-field":
+		err = decodeList(value, &c.CustomFields)
+	case "where":
+		err = decodeString(value, &c.Where)
+	case "group-by":
+		err = decodeList(value, &c.GroupBy)
+	case "sort":
+		err = decodeList(value, &c.Sort)
+	case "bom":
+		err = decodeBool(value, &c.BOM)
+	case "columns":
+		err = decodeList(value, &c.Columns)
+	case "incremental":
+		err = decodeBool(value, &c.Incremental)
+	default:
+		return fmt.Errorf("%s:%d: unknown key %q", path, key.Line, key.Value)
+	}
+	if err != nil {
+		return fmt.Errorf("%s:%d: %s: %w", path, value.Line, key.Value, err)
+	}
+
+	c.setOrigin(key.Value, fmt.Sprintf("%s:%d", path, value.Line))
+	return nil
+}
+
+func decodeString(node *yaml.Node, v *string) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("expected a string")
+	}
+	*v = node.Value
+	return nil
+}
+
+func decodeBool(node *yaml.Node, v *bool) error {
+	if node.Kind != yaml.ScalarNode || node.Decode(v) != nil {
+		return fmt.Errorf("expected true or false, got %q", node.Value)
+	}
+	return nil
+}
+
+func decodeInt(node *yaml.Node, v *int) error {
+	if node.Kind != yaml.ScalarNode || node.Decode(v) != nil {
+		return fmt.Errorf("expected an integer, got %q", node.Value)
+	}
+	return nil
+}
+
+// decodeList は文字列のリストを読み込む
+// コマンドラインと同様にカンマ区切りの文字列も受け付ける
+func decodeList(node *yaml.Node, v *[]string) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var list []string
+		for _, s := range strings.Split(node.Value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+		*v = list
+		return nil
+	case yaml.SequenceNode:
+		list := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: expected a string", item.Line)
+			}
+			list = append(list, item.Value)
+		}
+		*v = list
+		return nil
+	default:
+		return fmt.Errorf("expected a string or a list of strings")
+	}
+}
+
+// expandHome は先頭の ~/ をホームディレクトリに展開する
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// setOrigin は設定値の読み込み元（ファイル名:行番号）を記録する
+func (c *Config) setOrigin(key, origin string) {
+	if c.origins == nil {
+		c.origins = make(map[string]string)
+	}
+	c.origins[key] = origin
+}
+
+// mergeOrigin は Merge で上書きされた設定値の読み込み元を引き継ぐ
+func (c *Config) mergeOrigin(other *Config, key string) {
+	if origin, ok := other.origins[key]; ok {
+		c.setOrigin(key, origin)
+	} else {
+		delete(c.origins, key)
+	}
+}
+
+// errorAt は設定ファイル由来の値に関するエラーに読み込み元を付ける
+func (c *Config) errorAt(key string, err error) error {
+	if origin, ok := c.origins[key]; ok {
+		return fmt.Errorf("%s: %w", origin, err)
+	}
+	return err
+}
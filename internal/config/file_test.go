@@ -0,0 +1,204 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+const testConfigFile = `space: mycompany
+domain: backlog.jp
+format: markdown
+with-comments: true
+exclude-status: [完了, Closed]
+
+profiles:
+  client-a:
+    space: client-a
+    project: CLA, CLB
+    format: xlsx
+    max-retries: 2
+  client-b:
+    project:
+      - CLB
+    assignee: [me, none]
+`
+
+func TestLoadFile(t *testing.T) {
+	path := writeConfigFile(t, testConfigFile)
+
+	cfg, err := LoadFile(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Space != "mycompany" || cfg.Domain != "backlog.jp" || cfg.Format != FormatMarkdown {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if !cfg.WithComments {
+		t.Error("with-comments should be loaded")
+	}
+	if !reflect.DeepEqual(cfg.ExcludeStatuses, []string{"完了", "Closed"}) {
+		t.Errorf("unexpected exclude statuses: %v", cfg.ExcludeStatuses)
+	}
+	if len(cfg.Projects) != 0 {
+		t.Errorf("profiles should not be applied without --profile: %v", cfg.Projects)
+	}
+}
+
+func TestLoadFile_Profile(t *testing.T) {
+	path := writeConfigFile(t, testConfigFile)
+
+	cfg, err := LoadFile(path, "client-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// プロファイルの値がトップレベルの値を上書きする
+	if cfg.Space != "client-a" || cfg.Format != FormatXLSX {
+		t.Errorf("profile should override top-level values: %+v", cfg)
+	}
+	// プロファイルにない値はトップレベルの値を使う
+	if cfg.Domain != "backlog.jp" {
+		t.Errorf("expected domain from top level, got %s", cfg.Domain)
+	}
+	if !reflect.DeepEqual(cfg.Projects, []string{"CLA", "CLB"}) {
+		t.Errorf("unexpected projects: %v", cfg.Projects)
+	}
+	if cfg.MaxRetries == nil || *cfg.MaxRetries != 2 {
+		t.Errorf("unexpected max retries: %v", cfg.MaxRetries)
+	}
+
+	cfg, err = LoadFile(path, "client-b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Assignees, []string{"me", "none"}) {
+		t.Errorf("unexpected assignees: %v", cfg.Assignees)
+	}
+}
+
+func TestLoadFile_UnknownProfile(t *testing.T) {
+	path := writeConfigFile(t, testConfigFile)
+
+	_, err := LoadFile(path, "client-c")
+	if err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+	if !strings.Contains(err.Error(), "client-a, client-b") {
+		t.Errorf("error should list available profiles: %v", err)
+	}
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "unknown key",
+			content:  "space: mycompany\nfromat: json\n",
+			expected: ":2: unknown key \"fromat\"",
+		},
+		{
+			name:     "invalid integer",
+			content:  "space: mycompany\n\nconcurrency: many\n",
+			expected: ":3: concurrency: expected an integer",
+		},
+		{
+			name:     "invalid bool",
+			content:  "profiles:\n  a:\n    bom: maybe\n",
+			expected: ":3: bom: expected true or false",
+		},
+		{
+			name:     "syntax error",
+			content:  "space: [mycompany\n",
+			expected: "yaml:",
+		},
+		{
+			name:     "not a mapping",
+			content:  "- space\n",
+			expected: ":1: config must be a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.content)
+			_, err := LoadFile(path, "a")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadFile_NotFound(t *testing.T) {
+	_, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml"), "")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
+
+func TestConfig_ValidateReportsFileLine(t *testing.T) {
+	path := writeConfigFile(t, "space: mycompany\nproject: MYPROJ\nformat: pdf\n")
+
+	cfg, err := LoadFile(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg.Merge(&Config{APIKey: "key"})
+
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if !strings.HasPrefix(err.Error(), path+":3: invalid format") {
+		t.Errorf("expected error with file line, got %v", err)
+	}
+
+	// コマンドライン引数で上書きした値には行番号を付けない
+	cfg.Merge(&Config{Format: "doc"})
+	err = cfg.Validate()
+	if err == nil || strings.Contains(err.Error(), path) {
+		t.Errorf("expected error without file line, got %v", err)
+	}
+}
+
+func TestConfig_MergePrecedence(t *testing.T) {
+	path := writeConfigFile(t, "space: from-file\ndomain: backlog.jp\noutput: ./reports\n")
+
+	cfg, err := LoadFile(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// ファイル < 環境変数 < コマンドライン引数
+	cfg.Merge(&Config{Space: "from-env"})
+	cfg.Merge(&Config{Output: "./out"})
+
+	if cfg.Space != "from-env" {
+		t.Errorf("env should override file, got %s", cfg.Space)
+	}
+	if cfg.Domain != "backlog.jp" {
+		t.Errorf("file value should be kept, got %s", cfg.Domain)
+	}
+	if cfg.Output != "./out" {
+		t.Errorf("flag should override file, got %s", cfg.Output)
+	}
+}
@@ -0,0 +1,120 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+// 担当者の指定で特別な意味を持つ値
+const (
+	assigneeMe   = "me"
+	assigneeNone = "none"
+)
+
+// assigneeSpecs は設定された担当者の指定を返す
+func (e *Exporter) assigneeSpecs() []string {
+	specs := e.config.Assignees
+	if e.config.Assignee != nil {
+		specs = append(specs[:len(specs):len(specs)], strconv.Itoa(*e.config.Assignee))
+	}
+	return specs
+}
+
+// resolveAssignees は担当者の指定をユーザーIDに解決する
+//
+//   - me: APIキーの所有者
+//   - none: 担当者が未設定の課題
+//   - 数値: ユーザーID（Backlog内部のID）
+//   - それ以外: プロジェクトの参加ユーザーのログインID、メールアドレス、名前
+//
+// 戻り値はユーザーIDの一覧と、未割当の課題を対象にするかどうか
+func (e *Exporter) resolveAssignees(ctx context.Context, projectIDOrKey string, specs []string) ([]int, bool, error) {
+	var ids []int
+	var unassigned bool
+	var users []*backlog.User
+	seen := make(map[int]bool)
+
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+
+		var id int
+		switch {
+		case strings.EqualFold(spec, assigneeNone):
+			unassigned = true
+			continue
+		case strings.EqualFold(spec, assigneeMe):
+			me, err := e.client.GetMyself(ctx)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to get current user: %w", err)
+			}
+			id = me.ID
+		default:
+			if n, err := strconv.Atoi(spec); err == nil {
+				id = n
+				break
+			}
+			if users == nil {
+				var err error
+				users, err = e.client.GetProjectUsers(ctx, projectIDOrKey)
+				if err != nil {
+					return nil, false, fmt.Errorf("failed to get project users: %w", err)
+				}
+			}
+			user, err := findUser(users, spec)
+			if err != nil {
+				return nil, false, err
+			}
+			id = user.ID
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, unassigned, nil
+}
+
+// findUser はログインID、メールアドレス、名前でユーザーを検索する
+// ログインIDとメールアドレスは大文字小文字を区別せず、名前は完全一致を優先する
+func findUser(users []*backlog.User, spec string) (*backlog.User, error) {
+	matchers := []func(*backlog.User) bool{
+		func(u *backlog.User) bool { return strings.EqualFold(u.UserID, spec) },
+		func(u *backlog.User) bool { return u.MailAddress != "" && strings.EqualFold(u.MailAddress, spec) },
+		func(u *backlog.User) bool { return u.Name == spec },
+		func(u *backlog.User) bool { return strings.EqualFold(u.Name, spec) },
+	}
+
+	for _, match := range matchers {
+		var found []*backlog.User
+		for _, u := range users {
+			if match(u) {
+				found = append(found, u)
+			}
+		}
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], nil
+		default:
+			return nil, fmt.Errorf("ambiguous assignee %q matches %s. Use the login ID instead", spec, describeUsers(found))
+		}
+	}
+
+	return nil, fmt.Errorf("unknown assignee %q. Available users: %s", spec, describeUsers(users))
+}
+
+// describeUsers はユーザー一覧をエラーメッセージ用の文字列にする
+func describeUsers(users []*backlog.User) string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		names = append(names, fmt.Sprintf("%s (%s)", u.Name, u.UserID))
+	}
+	return strings.Join(names, ", ")
+}
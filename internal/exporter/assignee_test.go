@@ -0,0 +1,132 @@
+package exporter
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func createTestUsers() []*backlog.User {
+	return []*backlog.User{
+		{ID: 1, UserID: "yamada", Name: "山田", MailAddress: "yamada@example.com"},
+		{ID: 2, UserID: "suzuki", Name: "鈴木", MailAddress: "suzuki@example.com"},
+		{ID: 3, UserID: "tanaka", Name: "Tanaka Taro"},
+		{ID: 4, UserID: "tanaka2", Name: "Tanaka Taro"},
+	}
+}
+
+func TestExporter_ResolveAssignees(t *testing.T) {
+	tests := []struct {
+		name       string
+		specs      []string
+		want       []int
+		unassigned bool
+		wantErr    string
+	}{
+		{name: "me", specs: []string{"me"}, want: []int{2}},
+		{name: "login id", specs: []string{"Yamada"}, want: []int{1}},
+		{name: "mail address", specs: []string{"SUZUKI@example.com"}, want: []int{2}},
+		{name: "name", specs: []string{"山田"}, want: []int{1}},
+		{name: "numeric id", specs: []string{"12345"}, want: []int{12345}},
+		{name: "multiple with duplicates", specs: []string{"yamada", "山田", "me"}, want: []int{1, 2}},
+		{name: "none", specs: []string{"none", "suzuki"}, want: []int{2}, unassigned: true},
+		{name: "ambiguous name", specs: []string{"Tanaka Taro"}, wantErr: "ambiguous assignee"},
+		{name: "unknown", specs: []string{"sato"}, wantErr: "Available users: 山田 (yamada)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &backlog.MockClient{
+				GetMyselfFunc: func(ctx context.Context) (*backlog.User, error) {
+					return &backlog.User{ID: 2, UserID: "suzuki", Name: "鈴木"}, nil
+				},
+				GetProjectUsersFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.User, error) {
+					return createTestUsers(), nil
+				},
+			}
+			exp := NewExporterWithOutput(mockClient, &config.Config{}, &testOutput{})
+
+			ids, unassigned, err := exp.resolveAssignees(context.Background(), "MYPROJ", tt.specs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, ids)
+			}
+			if unassigned != tt.unassigned {
+				t.Errorf("expected unassigned %v, got %v", tt.unassigned, unassigned)
+			}
+		})
+	}
+}
+
+func TestExporter_Run_Assignees(t *testing.T) {
+	project, statuses, issues := createTestData()
+	unassigned := &backlog.Issue{
+		ID:       400,
+		IssueKey: "MYPROJ-400",
+		Summary:  "未割当の課題",
+		Status:   &backlog.Status{ID: 1, Name: "未対応"},
+	}
+	issues = append(issues, unassigned)
+
+	var queries []backlog.IssueQuery
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetProjectUsersFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.User, error) {
+			return createTestUsers(), nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			queries = append(queries, query)
+			return issues, nil
+		},
+	}
+
+	// ユーザーの指定のみの場合はAPIで絞り込む
+	cfg := &config.Config{
+		Project:   "MYPROJ",
+		Output:    t.TempDir(),
+		Format:    config.FormatJSON,
+		Assignees: []string{"yamada"},
+	}
+	exp := NewExporterWithOutput(mockClient, cfg, &testOutput{})
+	if _, err := exp.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(queries[0].AssigneeIDs, []int{1}) {
+		t.Errorf("expected assignee IDs [1], got %v", queries[0].AssigneeIDs)
+	}
+
+	// 未割当を含む場合は担当者で絞り込まずに取得してから絞り込む
+	queries = nil
+	cfg.Assignees = []string{"none"}
+	content, err := exp.Render(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queries[0].AssigneeIDs) != 0 {
+		t.Errorf("unassigned filter should not be sent to the API: %v", queries[0].AssigneeIDs)
+	}
+	result := string(content)
+	if !strings.Contains(result, "MYPROJ-400") {
+		t.Error("expected unassigned issue in output")
+	}
+	if strings.Contains(result, "MYPROJ-100") {
+		t.Error("assigned issue should be filtered out")
+	}
+}
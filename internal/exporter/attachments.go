@@ -0,0 +1,103 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+// attachmentsDirName は課題ごとの添付ファイル保存先ディレクトリ名
+const attachmentsDirName = "attachments"
+
+// downloadAttachments は各課題の添付ファイルを <output>/<ISSUEKEY>/attachments/ に保存する
+// 戻り値は課題ID -> 保存した添付ファイル一覧
+func (e *Exporter) downloadAttachments(ctx context.Context, issues []*backlog.Issue) (map[int][]*backlog.ExportedAttachment, error) {
+	result := make(map[int][]*backlog.ExportedAttachment, len(issues))
+	downloaded := 0
+
+	e.output.Printf("Downloading attachments... ")
+	for _, issue := range issues {
+		attachments, err := e.client.GetAttachments(ctx, issue.IssueKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", issue.IssueKey, err)
+		}
+		if len(attachments) == 0 {
+			continue
+		}
+
+		relDir := path.Join(issue.IssueKey, attachmentsDirName)
+		dir := filepath.Join(e.config.OutputDir(), filepath.FromSlash(relDir))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create attachment directory: %w", err)
+		}
+
+		used := make(map[string]bool)
+		for _, a := range attachments {
+			name := attachmentFilename(a, used)
+			if err := e.saveAttachment(ctx, issue.IssueKey, a.ID, filepath.Join(dir, name)); err != nil {
+				return nil, fmt.Errorf("%s: %w", issue.IssueKey, err)
+			}
+
+			result[issue.ID] = append(result[issue.ID], &backlog.ExportedAttachment{
+				Attachment: a,
+				Path:       path.Join(relDir, name),
+			})
+			downloaded++
+		}
+	}
+	e.output.Printf("done (%d files)\n", downloaded)
+
+	return result, nil
+}
+
+// saveAttachment は添付ファイルをダウンロードして filePath に保存する
+func (e *Exporter) saveAttachment(ctx context.Context, issueKey string, attachmentID int, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if err := e.client.DownloadAttachment(ctx, issueKey, attachmentID, f); err != nil {
+		f.Close()
+		os.Remove(filePath)
+		return err
+	}
+
+	return f.Close()
+}
+
+// attachmentFilename は保存用の安全なファイル名を返す
+// 同じ課題内で名前が重複する場合は添付ファイルIDを前置する
+func attachmentFilename(a *backlog.Attachment, used map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r == '/', r == '\\', r == ':', r < 0x20:
+			return '_'
+		}
+		return r
+	}, a.Name)
+	if name == "" || name == "." || name == ".." {
+		name = "attachment"
+	}
+
+	if used[name] {
+		name = strconv.Itoa(a.ID) + "_" + name
+	}
+	used[name] = true
+
+	return name
+}
+
+// attachAttachments は保存した添付ファイルを階層構造の各課題に設定する
+func attachAttachments(issues []*backlog.HierarchicalIssue, attachments map[int][]*backlog.ExportedAttachment) {
+	for _, hi := range issues {
+		hi.Attachments = attachments[hi.Issue.ID]
+		attachAttachments(hi.Children, attachments)
+	}
+}
@@ -0,0 +1,321 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+// utf8BOM はExcelでUTF-8として認識させるためのバイトオーダーマーク
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// csvRow はCSV出力用に平坦化した課題の行
+type csvRow struct {
+	issue     *backlog.Issue
+	parentKey string
+	// contextOnly は取得条件外の親課題（--with-parents）の行かどうか
+	contextOnly bool
+}
+
+// csvColumn はCSVの列定義
+type csvColumn struct {
+	name  string
+	value func(row csvRow) string
+}
+
+// csvColumns は出力可能な列の一覧（この順序で --columns の候補を表示する）
+var csvColumns = []csvColumn{
+	{"id", func(r csvRow) string { return strconv.Itoa(r.issue.ID) }},
+	{"key", func(r csvRow) string { return r.issue.IssueKey }},
+	{"parentKey", func(r csvRow) string { return r.parentKey }},
+	{"contextOnly", func(r csvRow) string { return strconv.FormatBool(r.contextOnly) }},
+	{"issueType", func(r csvRow) string {
+		if r.issue.IssueType != nil {
+			return r.issue.IssueType.Name
+		}
+		return ""
+	}},
+	{"summary", func(r csvRow) string { return r.issue.Summary }},
+	{"description", func(r csvRow) string { return r.issue.Description }},
+	{"status", func(r csvRow) string {
+		if r.issue.Status != nil {
+			return r.issue.Status.Name
+		}
+		return ""
+	}},
+	{"priority", func(r csvRow) string {
+		if r.issue.Priority != nil {
+			return r.issue.Priority.Name
+		}
+		return ""
+	}},
+	{"resolution", func(r csvRow) string {
+		if r.issue.Resolution != nil {
+			return r.issue.Resolution.Name
+		}
+		return ""
+	}},
+	{"category", func(r csvRow) string { return strings.Join(categoryNames(r.issue), ", ") }},
+	{"milestone", func(r csvRow) string { return strings.Join(versionNames(r.issue.Milestone), ", ") }},
+	{"version", func(r csvRow) string { return strings.Join(versionNames(r.issue.Versions), ", ") }},
+	{"assignee", func(r csvRow) string { return csvUserName(r.issue.Assignee) }},
+	{"startDate", func(r csvRow) string { return csvDate(r.issue.StartDate) }},
+	{"dueDate", func(r csvRow) string { return csvDate(r.issue.DueDate) }},
+	{"estimatedHours", func(r csvRow) string { return csvHours(r.issue.EstimatedHours) }},
+	{"actualHours", func(r csvRow) string { return csvHours(r.issue.ActualHours) }},
+	{"createdUser", func(r csvRow) string { return csvUserName(r.issue.CreatedUser) }},
+	{"created", func(r csvRow) string { return csvTime(r.issue.Created) }},
+	{"updatedUser", func(r csvRow) string { return csvUserName(r.issue.UpdatedUser) }},
+	{"updated", func(r csvRow) string { return csvTime(r.issue.Updated) }},
+}
+
+// CustomFieldColumnPrefix はカスタム属性の列名の接頭辞（例: cf:見積もり）
+const CustomFieldColumnPrefix = "cf:"
+
+// DefaultCSVColumns は --columns 未指定時に出力する列
+// 課題にカスタム属性がある場合は、これに続けてすべてのカスタム属性の列を出力する
+var DefaultCSVColumns = []string{
+	"key", "parentKey", "summary", "status", "priority", "assignee", "dueDate", "created", "updated",
+}
+
+// CSVColumnNames は出力可能な列名の一覧を返す
+func CSVColumnNames() []string {
+	names := make([]string, 0, len(csvColumns))
+	for _, c := range csvColumns {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// ValidateCSVColumns は列名がすべて出力可能なものかどうかを検証する
+func ValidateCSVColumns(names []string) error {
+	_, err := lookupCSVColumns(names)
+	return err
+}
+
+func lookupCSVColumns(names []string) ([]csvColumn, error) {
+	if len(names) == 0 {
+		names = DefaultCSVColumns
+	}
+
+	columns := make([]csvColumn, 0, len(names))
+	for _, name := range names {
+		if field, ok := strings.CutPrefix(name, CustomFieldColumnPrefix); ok && field != "" {
+			columns = append(columns, customFieldCSVColumn(name, field))
+			continue
+		}
+		found := false
+		for _, c := range csvColumns {
+			if strings.EqualFold(c.name, name) {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q. Available columns: %s, %sNAME", name, strings.Join(CSVColumnNames(), ", "), CustomFieldColumnPrefix)
+		}
+	}
+
+	return columns, nil
+}
+
+// customFieldCSVColumn はカスタム属性（名前またはID）の値を出力する列を作成する
+func customFieldCSVColumn(name, field string) csvColumn {
+	return csvColumn{name, func(r csvRow) string {
+		if cf := findCustomFieldByName(r.issue, field); cf != nil {
+			return cf.String()
+		}
+		return ""
+	}}
+}
+
+// findCustomFieldByName は課題のカスタム属性を名前またはIDで検索する
+// 名前は完全一致を優先し、見つからなければ大文字小文字を区別せずに比較する
+func findCustomFieldByName(issue *backlog.Issue, field string) *backlog.CustomField {
+	for _, cf := range issue.CustomFields {
+		if cf.Name == field {
+			return cf
+		}
+	}
+	for _, cf := range issue.CustomFields {
+		if strings.EqualFold(cf.Name, field) {
+			return cf
+		}
+	}
+	if id, err := strconv.Atoi(field); err == nil {
+		return findCustomField(issue, id)
+	}
+	return nil
+}
+
+// withCustomFieldColumns は列の指定がない場合にデフォルトの列へカスタム属性の列を加える
+func (f *CSVFormatter) withCustomFieldColumns(columns []csvColumn, issues []*backlog.HierarchicalIssue) []csvColumn {
+	if len(f.Columns) > 0 {
+		return columns
+	}
+	for _, c := range customFieldColumns(issues) {
+		columns = append(columns, customFieldCSVColumn(CustomFieldColumnPrefix+c.Name, c.Name))
+	}
+	return columns
+}
+
+// ============================================
+// CSV / TSV Formatter
+// ============================================
+
+// CSVFormatter はCSV・TSV形式のフォーマッター
+// 課題は親子関係を保った順序で1行ずつ出力し、parentKey 列で親課題を表す
+type CSVFormatter struct {
+	// Delimiter は区切り文字（',' または '\t'）
+	Delimiter rune
+	// BOM が true の場合は先頭にUTF-8のBOMを付ける
+	BOM bool
+	// Columns は出力する列名（空の場合は DefaultCSVColumns）
+	Columns []string
+}
+
+func (f *CSVFormatter) Extension() string {
+	if f.Delimiter == '\t' {
+		return "tsv"
+	}
+	return "csv"
+}
+
+func (f *CSVFormatter) Format(data *backlog.ExportData) ([]byte, error) {
+	return f.write(false, data.Issues, func(writeRow func(project *backlog.Project, row csvRow) error) error {
+		return f.walk(data.Project, data.Issues, "", writeRow)
+	})
+}
+
+// FormatMulti は複数プロジェクトを1つの表にまとめる（先頭に project 列を追加）
+func (f *CSVFormatter) FormatMulti(data *backlog.MultiExportData) ([]byte, error) {
+	var issues []*backlog.HierarchicalIssue
+	for _, p := range data.Projects {
+		issues = append(issues, p.Issues...)
+	}
+	return f.write(true, issues, func(writeRow func(project *backlog.Project, row csvRow) error) error {
+		for _, p := range data.Projects {
+			if err := f.walk(p.Project, p.Issues, "", writeRow); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (f *CSVFormatter) write(withProject bool, issues []*backlog.HierarchicalIssue, rows func(writeRow func(project *backlog.Project, row csvRow) error) error) ([]byte, error) {
+	columns, err := lookupCSVColumns(f.Columns)
+	if err != nil {
+		return nil, err
+	}
+	columns = f.withCustomFieldColumns(columns, issues)
+
+	var buf bytes.Buffer
+	if f.BOM {
+		buf.Write(utf8BOM)
+	}
+
+	w := csv.NewWriter(&buf)
+	if f.Delimiter != 0 {
+		w.Comma = f.Delimiter
+	}
+
+	// ヘッダー行
+	header := make([]string, 0, len(columns)+1)
+	if withProject {
+		header = append(header, "project")
+	}
+	for _, c := range columns {
+		header = append(header, c.name)
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	// 課題行
+	err = rows(func(project *backlog.Project, row csvRow) error {
+		record := make([]string, 0, len(columns)+1)
+		if withProject {
+			record = append(record, project.ProjectKey)
+		}
+		for _, c := range columns {
+			record = append(record, c.value(row))
+		}
+		return w.Write(record)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// walk は課題ツリーを親→子の順にたどって行を出力する
+func (f *CSVFormatter) walk(project *backlog.Project, issues []*backlog.HierarchicalIssue, parentKey string, writeRow func(project *backlog.Project, row csvRow) error) error {
+	for _, hi := range issues {
+		if err := writeRow(project, csvRow{issue: hi.Issue, parentKey: parentKey, contextOnly: hi.ContextOnly}); err != nil {
+			return err
+		}
+		if err := f.walk(project, hi.Children, hi.Issue.IssueKey, writeRow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// categoryNames は課題のカテゴリー名の一覧を返す
+func categoryNames(issue *backlog.Issue) []string {
+	names := make([]string, 0, len(issue.Category))
+	for _, c := range issue.Category {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// versionNames はマイルストーン・発生バージョンの名前の一覧を返す
+func versionNames(versions []*backlog.Version) []string {
+	names := make([]string, 0, len(versions))
+	for _, v := range versions {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func csvUserName(u *backlog.User) string {
+	if u != nil {
+		return u.Name
+	}
+	return ""
+}
+
+func csvDate(d *string) string {
+	if d != nil && len(*d) >= len("2006-01-02") {
+		return (*d)[:len("2006-01-02")]
+	}
+	return ""
+}
+
+func csvHours(h *float64) string {
+	if h != nil {
+		return strconv.FormatFloat(*h, 'f', -1, 64)
+	}
+	return ""
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func TestCSVFormatter_Format(t *testing.T) {
+	data := createTestExportData()
+	f := NewFormatter(config.FormatCSV)
+
+	if f.Extension() != "csv" {
+		t.Errorf("expected extension csv, got %s", f.Extension())
+	}
+
+	output, err := f.Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV output: %v", err)
+	}
+
+	// ヘッダー + 3課題
+	if len(records) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(DefaultCSVColumns, ",") {
+		t.Errorf("unexpected header: %v", records[0])
+	}
+
+	// 親課題の直後に子課題が続き、parentKey で親を参照する
+	if records[1][0] != "MYPROJ-100" || records[1][1] != "" {
+		t.Errorf("unexpected parent row: %v", records[1])
+	}
+	if records[2][0] != "MYPROJ-101" || records[2][1] != "MYPROJ-100" {
+		t.Errorf("unexpected child row: %v", records[2])
+	}
+	if records[1][6] != "2024-12-01" {
+		t.Errorf("expected due date 2024-12-01, got %s", records[1][6])
+	}
+	if records[3][5] != "" {
+		t.Errorf("expected empty assignee, got %s", records[3][5])
+	}
+}
+
+func TestCSVFormatter_TSVWithOptions(t *testing.T) {
+	data := createTestExportData()
+	f := NewFormatterFromConfig(&config.Config{
+		Format:  config.FormatTSV,
+		BOM:     true,
+		Columns: []string{"key", "Summary", "assignee"},
+	})
+
+	if f.Extension() != "tsv" {
+		t.Errorf("expected extension tsv, got %s", f.Extension())
+	}
+
+	output, err := f.Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.HasPrefix(output, utf8BOM) {
+		t.Error("output should start with UTF-8 BOM")
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output[len(utf8BOM):])), "\n")
+	if lines[0] != "key\tsummary\tassignee" {
+		t.Errorf("unexpected header: %q", lines[0])
+	}
+	if lines[1] != "MYPROJ-100\t親課題\t山田" {
+		t.Errorf("unexpected first row: %q", lines[1])
+	}
+}
+
+func TestCSVFormatter_UnknownColumn(t *testing.T) {
+	f := &CSVFormatter{Columns: []string{"key", "storyPoints"}}
+
+	_, err := f.Format(createTestExportData())
+	if err == nil || !strings.Contains(err.Error(), `unknown column "storyPoints"`) {
+		t.Errorf("expected unknown column error, got %v", err)
+	}
+	if err := ValidateCSVColumns([]string{"storyPoints"}); err == nil {
+		t.Error("ValidateCSVColumns should reject unknown columns")
+	}
+}
+
+func TestCSVFormatter_FormatMulti(t *testing.T) {
+	f := &CSVFormatter{Delimiter: ',', Columns: []string{"key"}}
+
+	output, err := f.FormatMulti(createTestMultiExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV output: %v", err)
+	}
+	if len(records) != 7 {
+		t.Fatalf("expected 7 rows, got %d", len(records))
+	}
+	if records[0][0] != "project" || records[4][0] != "OTHER" {
+		t.Errorf("expected project column, got %v / %v", records[0], records[4])
+	}
+}
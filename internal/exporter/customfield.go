@@ -0,0 +1,222 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// customFieldFilter はIDに解決したカスタム属性による取得条件
+// 同じカスタム属性への複数の指定は1つにまとめる（リストはいずれかの項目、範囲は両端）
+type customFieldFilter struct {
+	ID   int                     `json:"id"`
+	Type backlog.CustomFieldType `json:"type"`
+	// Keyword は文字列・文章に含まれる文字列
+	Keyword string `json:"keyword,omitempty"`
+	// ItemIDs はリスト形式で選択されている項目のID（いずれかに一致）
+	ItemIDs []int `json:"itemIds,omitempty"`
+	// Min・Max は数値または日付（YYYY-MM-DD）の範囲（指定値を含む）
+	Min string `json:"min,omitempty"`
+	Max string `json:"max,omitempty"`
+}
+
+// resolveCustomFields はカスタム属性の指定（名前=値 など）をIDに解決する
+func (e *Exporter) resolveCustomFields(ctx context.Context, projectIDOrKey string, filter *issueFilter) error {
+	definitions, err := e.client.GetCustomFields(ctx, projectIDOrKey)
+	if err != nil {
+		return fmt.Errorf("failed to get custom fields: %w", err)
+	}
+
+	for _, spec := range e.config.CustomFields {
+		cond, err := config.ParseCustomFieldCondition(spec)
+		if err != nil {
+			return err
+		}
+
+		ids, err := findIDs(definitions, []string{cond.Field}, "custom field", func(d *backlog.CustomFieldDefinition) (int, string) {
+			return d.ID, d.Name
+		})
+		if err != nil {
+			return err
+		}
+		def := definitions[slices.IndexFunc(definitions, func(d *backlog.CustomFieldDefinition) bool { return d.ID == ids[0] })]
+
+		cf := filter.customField(def)
+		if err := cf.add(def, cond); err != nil {
+			return fmt.Errorf("invalid custom-field %q: %w", spec, err)
+		}
+	}
+
+	return nil
+}
+
+// customField はカスタム属性の取得条件を返す（未登録の場合は追加する）
+func (f *issueFilter) customField(def *backlog.CustomFieldDefinition) *customFieldFilter {
+	for _, cf := range f.CustomFields {
+		if cf.ID == def.ID {
+			return cf
+		}
+	}
+	cf := &customFieldFilter{ID: def.ID, Type: def.TypeID}
+	f.CustomFields = append(f.CustomFields, cf)
+	return cf
+}
+
+// add はカスタム属性の種類に応じて値を検証し、取得条件に加える
+func (cf *customFieldFilter) add(def *backlog.CustomFieldDefinition, cond config.CustomFieldCondition) error {
+	switch {
+	case def.TypeID == backlog.CustomFieldNumeric || def.TypeID == backlog.CustomFieldDate:
+		value, err := normalizeCustomFieldValue(def.TypeID, cond.Value)
+		if err != nil {
+			return err
+		}
+		if cond.Op != "<=" {
+			cf.Min = value
+		}
+		if cond.Op != ">=" {
+			cf.Max = value
+		}
+	case def.TypeID.IsList():
+		if cond.Op != "=" {
+			return fmt.Errorf("%s is a list field. Use NAME=ITEM", def.Name)
+		}
+		ids, err := findIDs(def.Items, []string{cond.Value}, "item of "+def.Name, func(item *backlog.CustomFieldItem) (int, string) {
+			return item.ID, item.Name
+		})
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(cf.ItemIDs, ids[0]) {
+			cf.ItemIDs = append(cf.ItemIDs, ids[0])
+		}
+	default:
+		if cond.Op != "=" {
+			return fmt.Errorf("%s is a text field. Use NAME=KEYWORD", def.Name)
+		}
+		cf.Keyword = cond.Value
+	}
+	return nil
+}
+
+// normalizeCustomFieldValue は数値・日付の値を検証してAPIに渡す形式にする
+func normalizeCustomFieldValue(fieldType backlog.CustomFieldType, value string) (string, error) {
+	if fieldType == backlog.CustomFieldDate {
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", fmt.Errorf("invalid date: %s. Use YYYY-MM-DD", value)
+		}
+		return value, nil
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid number: %s", value)
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64), nil
+}
+
+// query はAPIに渡すカスタム属性の取得条件を返す
+func (cf *customFieldFilter) query() backlog.CustomFieldQuery {
+	return backlog.CustomFieldQuery{ID: cf.ID, Keyword: cf.Keyword, ItemIDs: cf.ItemIDs, Min: cf.Min, Max: cf.Max}
+}
+
+// match は課題のカスタム属性が取得条件を満たすかどうかを判定する
+// 文字列の比較は大文字小文字を区別しない部分一致
+func (cf *customFieldFilter) match(issue *backlog.Issue) bool {
+	field := findCustomField(issue, cf.ID)
+	if field == nil {
+		return false
+	}
+
+	if cf.Keyword != "" {
+		text := ""
+		if field.Text != nil {
+			text = *field.Text
+		}
+		if !strings.Contains(strings.ToLower(text), strings.ToLower(cf.Keyword)) {
+			return false
+		}
+	}
+	if len(cf.ItemIDs) > 0 && !matchAnyID(cf.ItemIDs, field.Items, func(item *backlog.CustomFieldItem) int { return item.ID }) {
+		return false
+	}
+	if cf.Min == "" && cf.Max == "" {
+		return true
+	}
+
+	switch cf.Type {
+	case backlog.CustomFieldNumeric:
+		if field.Number == nil {
+			return false
+		}
+		if lower, err := strconv.ParseFloat(cf.Min, 64); err == nil && *field.Number < lower {
+			return false
+		}
+		if upper, err := strconv.ParseFloat(cf.Max, 64); err == nil && *field.Number > upper {
+			return false
+		}
+		return true
+	case backlog.CustomFieldDate:
+		date := ""
+		if field.Date != nil {
+			date = *field.Date
+		}
+		return inDateRange(date, cf.Min, cf.Max)
+	}
+	return true
+}
+
+// findCustomField は課題のカスタム属性を ID で検索する
+func findCustomField(issue *backlog.Issue, id int) *backlog.CustomField {
+	for _, field := range issue.CustomFields {
+		if field.ID == id {
+			return field
+		}
+	}
+	return nil
+}
+
+// customFieldColumn は出力する列としてのカスタム属性
+type customFieldColumn struct {
+	Name string
+	Type backlog.CustomFieldType
+}
+
+// customFieldColumns は課題に含まれるカスタム属性を最初に出現した順に列挙する（子課題を含む）
+// 表形式の出力で列を決めるために使用する
+// カスタム属性のIDはプロジェクトごとに異なるため、複数プロジェクトをまとめる場合も考えて名前で区別する
+func customFieldColumns(issues []*backlog.HierarchicalIssue) []customFieldColumn {
+	var columns []customFieldColumn
+	seen := make(map[string]bool)
+
+	var walk func(issues []*backlog.HierarchicalIssue)
+	walk = func(issues []*backlog.HierarchicalIssue) {
+		for _, hi := range issues {
+			for _, field := range hi.Issue.CustomFields {
+				if !seen[field.Name] {
+					seen[field.Name] = true
+					columns = append(columns, customFieldColumn{Name: field.Name, Type: field.FieldTypeID})
+				}
+			}
+			walk(hi.Children)
+		}
+	}
+	walk(issues)
+
+	return columns
+}
+
+// nonEmptyCustomFields は値が設定されているカスタム属性のみを返す
+func nonEmptyCustomFields(issue *backlog.Issue) []*backlog.CustomField {
+	var fields []*backlog.CustomField
+	for _, field := range issue.CustomFields {
+		if !field.IsEmpty() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,282 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func createTestCustomFieldDefinitions() []*backlog.CustomFieldDefinition {
+	return []*backlog.CustomFieldDefinition{
+		{ID: 1, TypeID: backlog.CustomFieldText, Name: "顧客"},
+		{ID: 3, TypeID: backlog.CustomFieldNumeric, Name: "見積"},
+		{ID: 4, TypeID: backlog.CustomFieldDate, Name: "リリース日"},
+		{ID: 5, TypeID: backlog.CustomFieldSingleList, Name: "工程", Items: []*backlog.CustomFieldItem{
+			{ID: 11, Name: "設計"}, {ID: 12, Name: "実装"}, {ID: 13, Name: "テスト"},
+		}},
+	}
+}
+
+// createTestCustomFields は課題のカスタム属性（見積 2.5、リリース日 2024-12-01、工程 設計）を作成する
+func createTestCustomFields() []*backlog.CustomField {
+	customer := "ACME"
+	estimate := 2.5
+	release := "2024-12-01"
+	return []*backlog.CustomField{
+		{ID: 1, FieldTypeID: backlog.CustomFieldText, Name: "顧客", Text: &customer},
+		{ID: 2, FieldTypeID: backlog.CustomFieldTextArea, Name: "備考"},
+		{ID: 3, FieldTypeID: backlog.CustomFieldNumeric, Name: "見積", Number: &estimate},
+		{ID: 4, FieldTypeID: backlog.CustomFieldDate, Name: "リリース日", Date: &release},
+		{ID: 5, FieldTypeID: backlog.CustomFieldSingleList, Name: "工程", Items: []*backlog.CustomFieldItem{{ID: 11, Name: "設計"}}},
+		{ID: 6, FieldTypeID: backlog.CustomFieldCheckbox, Name: "対象", Items: []*backlog.CustomFieldItem{{ID: 21, Name: "iOS"}, {ID: 22, Name: "Android"}}},
+	}
+}
+
+func createTestCustomFieldExportData() *backlog.ExportData {
+	data := createTestExportData()
+	data.Issues[0].Issue.CustomFields = createTestCustomFields()
+	return data
+}
+
+func TestExporter_ResolveCustomFields(t *testing.T) {
+	_, statuses, _ := createTestData()
+
+	mockClient := &backlog.MockClient{
+		GetCustomFieldsFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.CustomFieldDefinition, error) {
+			return createTestCustomFieldDefinitions(), nil
+		},
+	}
+	cfg := &config.Config{
+		CustomFields: []string{"顧客=acme", "見積>=2", "見積<=3.50", "工程=設計", "工程=12", "リリース日=2024-12-01"},
+	}
+	exp := NewExporterWithOutput(mockClient, cfg, &testOutput{})
+
+	filter, err := exp.resolveIssueFilter(context.Background(), "MYPROJ", statuses)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := filter.query(1)
+	want := []backlog.CustomFieldQuery{
+		{ID: 1, Keyword: "acme"},
+		{ID: 3, Min: "2", Max: "3.5"},
+		{ID: 5, ItemIDs: []int{11, 12}},
+		{ID: 4, Min: "2024-12-01", Max: "2024-12-01"},
+	}
+	got, _ := json.Marshal(query.CustomFields)
+	expected, _ := json.Marshal(want)
+	if string(got) != string(expected) {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	issue := &backlog.Issue{Status: &backlog.Status{ID: 1}, CustomFields: createTestCustomFields()}
+	if !filter.match(issue) {
+		t.Error("issue should match the custom field conditions")
+	}
+}
+
+func TestExporter_ResolveCustomFields_Errors(t *testing.T) {
+	_, statuses, _ := createTestData()
+
+	tests := []struct {
+		spec    string
+		message string
+	}{
+		{"担当工程=設計", `unknown custom field "担当工程"`},
+		{"工程=レビュー", `unknown item of 工程 "レビュー". Available: 設計 (11), 実装 (12), テスト (13)`},
+		{"工程>=設計", "list field"},
+		{"見積=多め", "invalid number: 多め"},
+		{"リリース日>=12/01", "invalid date: 12/01"},
+		{"顧客<=ACME", "text field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			mockClient := &backlog.MockClient{
+				GetCustomFieldsFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.CustomFieldDefinition, error) {
+					return createTestCustomFieldDefinitions(), nil
+				},
+			}
+			cfg := &config.Config{CustomFields: []string{tt.spec}}
+			exp := NewExporterWithOutput(mockClient, cfg, &testOutput{})
+
+			_, err := exp.resolveIssueFilter(context.Background(), "MYPROJ", statuses)
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestCustomFieldFilter_Match(t *testing.T) {
+	issue := &backlog.Issue{CustomFields: createTestCustomFields()}
+
+	tests := []struct {
+		name   string
+		filter customFieldFilter
+		want   bool
+	}{
+		{name: "keyword", filter: customFieldFilter{ID: 1, Type: backlog.CustomFieldText, Keyword: "cm"}, want: true},
+		{name: "keyword not found", filter: customFieldFilter{ID: 1, Type: backlog.CustomFieldText, Keyword: "Initech"}, want: false},
+		{name: "numeric in range", filter: customFieldFilter{ID: 3, Type: backlog.CustomFieldNumeric, Min: "2.5", Max: "3"}, want: true},
+		{name: "numeric below min", filter: customFieldFilter{ID: 3, Type: backlog.CustomFieldNumeric, Min: "3"}, want: false},
+		{name: "date until", filter: customFieldFilter{ID: 4, Type: backlog.CustomFieldDate, Max: "2024-11-30"}, want: false},
+		{name: "any item", filter: customFieldFilter{ID: 6, Type: backlog.CustomFieldCheckbox, ItemIDs: []int{22, 23}}, want: true},
+		{name: "empty value", filter: customFieldFilter{ID: 2, Type: backlog.CustomFieldTextArea, Keyword: "x"}, want: false},
+		{name: "field not set", filter: customFieldFilter{ID: 99, Type: backlog.CustomFieldNumeric, Min: "0"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.match(issue); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatter_CustomFields(t *testing.T) {
+	data := createTestCustomFieldExportData()
+
+	tests := []struct {
+		format   config.OutputFormat
+		contains []string
+		excludes []string
+	}{
+		{config.FormatTXT, []string{"  顧客: ACME\n", "  見積: 2.5\n", "  対象: iOS, Android\n"}, []string{"備考"}},
+		{config.FormatMarkdown, []string{"| 工程 | 設計 |\n", "| リリース日 | 2024-12-01 |\n"}, []string{"備考"}},
+		{config.FormatHTML, []string{`<div class="custom-fields"><span>顧客: ACME</span>`}, []string{"備考"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			output, err := NewFormatter(tt.format).Format(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result := string(output)
+			for _, s := range tt.contains {
+				if !strings.Contains(result, s) {
+					t.Errorf("expected output to contain %q", s)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(result, s) {
+					t.Errorf("expected output not to contain %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONFormatter_CustomFields(t *testing.T) {
+	output, err := NewFormatter(config.FormatJSON).Format(createTestCustomFieldExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result struct {
+		Issues []struct {
+			CustomFields []jsonCustomField `json:"customFields"`
+		} `json:"issues"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	fields := result.Issues[0].CustomFields
+	got, _ := json.Marshal(fields)
+	want := `[{"id":1,"name":"顧客","type":"text","value":"ACME"},` +
+		`{"id":2,"name":"備考","type":"textArea","value":null},` +
+		`{"id":3,"name":"見積","type":"numeric","value":2.5},` +
+		`{"id":4,"name":"リリース日","type":"date","value":"2024-12-01"},` +
+		`{"id":5,"name":"工程","type":"singleList","value":"設計"},` +
+		`{"id":6,"name":"対象","type":"checkbox","value":["iOS","Android"]}]`
+	if string(got) != want {
+		t.Errorf("unexpected custom fields:\n%s", got)
+	}
+	if result.Issues[1].CustomFields != nil {
+		t.Error("customFields should be omitted when the issue has none")
+	}
+}
+
+func TestCSVFormatter_CustomFields(t *testing.T) {
+	data := createTestCustomFieldExportData()
+
+	tests := []struct {
+		name    string
+		columns []string
+		header  string
+		row     string
+	}{
+		{
+			name:   "default columns",
+			header: strings.Join(DefaultCSVColumns, ",") + ",cf:顧客,cf:備考,cf:見積,cf:リリース日,cf:工程,cf:対象",
+			row:    "ACME,,2.5,2024-12-01,設計,\"iOS, Android\"",
+		},
+		{
+			name:    "selected columns",
+			columns: []string{"key", "cf:工程", "cf:3"},
+			header:  "key,cf:工程,cf:3",
+			row:     "MYPROJ-100,設計,2.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFormatterFromConfig(&config.Config{Format: config.FormatCSV, Columns: tt.columns})
+			output, err := f.Format(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			lines := strings.Split(string(output), "\n")
+			if lines[0] != tt.header {
+				t.Errorf("unexpected header: %s", lines[0])
+			}
+			if !strings.HasSuffix(lines[1], tt.row) {
+				t.Errorf("unexpected row: %s", lines[1])
+			}
+			// カスタム属性がない課題は空の値
+			records, err := csv.NewReader(bytes.NewReader(output)).ReadAll()
+			if err != nil {
+				t.Fatalf("invalid CSV output: %v", err)
+			}
+			if last := records[len(records)-1]; last[len(last)-1] != "" {
+				t.Errorf("expected empty custom field value, got %v", last)
+			}
+		})
+	}
+}
+
+func TestXLSXFormatter_CustomFields(t *testing.T) {
+	output, err := NewFormatter(config.FormatXLSX).Format(createTestCustomFieldExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	book, err := excelize.OpenReader(bytes.NewReader(output))
+	if err != nil {
+		t.Fatalf("invalid workbook: %v", err)
+	}
+	defer book.Close()
+
+	// 固定の13列に続けてカスタム属性の列
+	for cell, want := range map[string]string{"N1": "顧客", "P1": "見積", "N2": "ACME", "P2": "2.5", "R2": "設計", "S2": "iOS, Android"} {
+		if got, _ := book.GetCellValue(xlsxIssuesSheet, cell); got != want {
+			t.Errorf("%s: expected %q, got %q", cell, want, got)
+		}
+	}
+	if cellType, _ := book.GetCellType(xlsxIssuesSheet, "P2"); cellType == excelize.CellTypeInlineString || cellType == excelize.CellTypeSharedString {
+		t.Error("numeric custom field should be a number cell")
+	}
+	if release, _ := book.GetCellValue(xlsxIssuesSheet, "Q2"); release != "2024-12-01" {
+		t.Errorf("expected formatted date, got %q", release)
+	}
+}
@@ -0,0 +1,417 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// 差分として検出する項目
+const (
+	DiffFieldStatus   = "status"
+	DiffFieldAssignee = "assignee"
+	DiffFieldDueDate  = "dueDate"
+	DiffFieldParent   = "parent"
+)
+
+// diffFieldLabels はテキスト・Markdown出力での項目名
+var diffFieldLabels = map[string]string{
+	DiffFieldStatus:   "状態",
+	DiffFieldAssignee: "担当者",
+	DiffFieldDueDate:  "期限日",
+	DiffFieldParent:   "親課題",
+}
+
+// Snapshot はJSON形式でエクスポートした課題一覧を表す
+type Snapshot struct {
+	ExportedAt string
+	// Issues は課題キー -> 課題（親子関係は平坦化済み）
+	Issues map[string]*SnapshotIssue
+
+	// contextKeys は取得条件外の親課題（contextOnly）の課題ID -> キー（差分の対象にせず、親課題の判定にのみ使う）
+	contextKeys map[int]string
+}
+
+// SnapshotIssue はスナップショット内の課題を表す
+type SnapshotIssue struct {
+	ID       int    `json:"-"`
+	Key      string `json:"issueKey"`
+	Summary  string `json:"summary"`
+	Status   string `json:"status"`
+	Assignee string `json:"assignee"`
+	DueDate  string `json:"dueDate"`
+	// Parent は親課題のキー（スナップショットに親課題がない場合は "#ID"）
+	Parent string `json:"parent"`
+
+	parentID int
+}
+
+// SnapshotDiff は2つのスナップショットの差分を表す
+type SnapshotDiff struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Summary DiffSummary      `json:"summary"`
+	Added   []*SnapshotIssue `json:"added"`
+	// Removed は完了した課題や条件に合わなくなった課題
+	Removed []*SnapshotIssue `json:"removed"`
+	Changed []*IssueChange   `json:"changed"`
+}
+
+// DiffSummary は差分の件数を表す
+type DiffSummary struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+// IssueChange は1つの課題の変更内容を表す
+type IssueChange struct {
+	Key     string        `json:"issueKey"`
+	Summary string        `json:"summary"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// FieldChange は1つの項目の変更前後の値を表す
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// LoadSnapshot はJSONフォーマッターの出力（単一・まとめレポートのどちらも可）を読み込む
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var doc struct {
+		jsonExportData
+		Projects []jsonExportData `json:"projects"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: not a JSON export: %w", path, err)
+	}
+
+	exports := doc.Projects
+	if exports == nil {
+		if doc.Issues == nil && doc.Groups == nil {
+			return nil, fmt.Errorf("%s: not a JSON export: issues not found", path)
+		}
+		exports = []jsonExportData{doc.jsonExportData}
+	}
+
+	snapshot := &Snapshot{
+		ExportedAt:  doc.ExportedAt,
+		Issues:      make(map[string]*SnapshotIssue),
+		contextKeys: make(map[int]string),
+	}
+	for _, export := range exports {
+		for _, issue := range export.Issues {
+			snapshot.addIssue(issue, 0)
+		}
+		// 分類したエクスポートでは同じ課題が複数のグループに含まれることがある（addIssue で重複は除かれる）
+		snapshot.addGroups(export.Groups)
+	}
+	snapshot.resolveParents()
+
+	return snapshot, nil
+}
+
+// addGroups はグループ（入れ子のグループを含む）の課題を追加する
+func (s *Snapshot) addGroups(groups []jsonGroup) {
+	for _, g := range groups {
+		for _, issue := range g.Issues {
+			s.addIssue(issue, 0)
+		}
+		s.addGroups(g.Groups)
+	}
+}
+
+// addIssue は課題とその子孫を平坦化して追加する（取得条件外の親課題は子孫のみ追加する）
+func (s *Snapshot) addIssue(ji jsonIssue, nestedParentID int) {
+	if ji.ContextOnly {
+		s.contextKeys[ji.ID] = ji.IssueKey
+		for _, child := range ji.Children {
+			s.addIssue(child, ji.ID)
+		}
+		return
+	}
+
+	issue := &SnapshotIssue{
+		ID:       ji.ID,
+		Key:      ji.IssueKey,
+		Summary:  ji.Summary,
+		Status:   ji.Status,
+		DueDate:  csvDate(ji.DueDate),
+		parentID: nestedParentID,
+	}
+	if ji.Assignee != nil {
+		issue.Assignee = *ji.Assignee
+	}
+	// parentIssueId を含まない古い出力では入れ子の関係から親課題を判定する
+	if ji.ParentIssueID != nil {
+		issue.parentID = *ji.ParentIssueID
+	}
+	s.Issues[issue.Key] = issue
+
+	for _, child := range ji.Children {
+		s.addIssue(child, ji.ID)
+	}
+}
+
+// resolveParents は親課題のIDをキーに変換する
+func (s *Snapshot) resolveParents() {
+	keys := make(map[int]string, len(s.Issues)+len(s.contextKeys))
+	for id, key := range s.contextKeys {
+		keys[id] = key
+	}
+	for _, issue := range s.Issues {
+		keys[issue.ID] = issue.Key
+	}
+	for _, issue := range s.Issues {
+		if issue.parentID == 0 {
+			continue
+		}
+		if key, ok := keys[issue.parentID]; ok {
+			issue.Parent = key
+		} else {
+			issue.Parent = "#" + strconv.Itoa(issue.parentID)
+		}
+	}
+}
+
+// DiffSnapshots は2つのスナップショットを比較する
+func DiffSnapshots(oldSnap, newSnap *Snapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{
+		From:    oldSnap.ExportedAt,
+		To:      newSnap.ExportedAt,
+		Added:   []*SnapshotIssue{},
+		Removed: []*SnapshotIssue{},
+		Changed: []*IssueChange{},
+	}
+
+	for _, key := range sortedIssueKeys(newSnap.Issues) {
+		newIssue := newSnap.Issues[key]
+		oldIssue, ok := oldSnap.Issues[key]
+		if !ok {
+			diff.Added = append(diff.Added, newIssue)
+			continue
+		}
+		if changes := compareIssues(oldIssue, newIssue, oldSnap, newSnap); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, &IssueChange{
+				Key:     key,
+				Summary: newIssue.Summary,
+				Changes: changes,
+			})
+		}
+	}
+
+	for _, key := range sortedIssueKeys(oldSnap.Issues) {
+		if _, ok := newSnap.Issues[key]; !ok {
+			diff.Removed = append(diff.Removed, oldSnap.Issues[key])
+		}
+	}
+
+	diff.Summary = DiffSummary{
+		Added:   len(diff.Added),
+		Removed: len(diff.Removed),
+		Changed: len(diff.Changed),
+	}
+	return diff
+}
+
+func compareIssues(oldIssue, newIssue *SnapshotIssue, oldSnap, newSnap *Snapshot) []FieldChange {
+	var changes []FieldChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add(DiffFieldStatus, oldIssue.Status, newIssue.Status)
+	add(DiffFieldAssignee, oldIssue.Assignee, newIssue.Assignee)
+	add(DiffFieldDueDate, oldIssue.DueDate, newIssue.DueDate)
+
+	// 親課題が片方のスナップショットにしかない場合は "#ID" になるため、もう片方のキーで補う
+	oldParent, newParent := oldIssue.Parent, newIssue.Parent
+	if oldIssue.parentID == newIssue.parentID {
+		newParent = oldParent
+	} else {
+		oldParent = parentKey(oldIssue, newSnap)
+		newParent = parentKey(newIssue, oldSnap)
+	}
+	add(DiffFieldParent, oldParent, newParent)
+
+	return changes
+}
+
+// parentKey は課題の親課題のキーを返す（自身のスナップショットにない場合は other から探す）
+func parentKey(issue *SnapshotIssue, other *Snapshot) string {
+	if !strings.HasPrefix(issue.Parent, "#") {
+		return issue.Parent
+	}
+	for _, candidate := range other.Issues {
+		if candidate.ID == issue.parentID {
+			return candidate.Key
+		}
+	}
+	return issue.Parent
+}
+
+// sortedIssueKeys は課題キーをプロジェクトキー・課題番号の順に並べる
+func sortedIssueKeys(issues map[string]*SnapshotIssue) []string {
+	keys := make([]string, 0, len(issues))
+	for key := range issues {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessIssueKey(keys[i], keys[j])
+	})
+	return keys
+}
+
+func lessIssueKey(a, b string) bool {
+	ap, an := splitIssueKey(a)
+	bp, bn := splitIssueKey(b)
+	if ap != bp {
+		return ap < bp
+	}
+	if an != bn {
+		return an < bn
+	}
+	return a < b
+}
+
+// splitIssueKey は "PROJ-123" をプロジェクトキーと課題番号に分ける
+func splitIssueKey(key string) (string, int) {
+	i := strings.LastIndex(key, "-")
+	if i < 0 {
+		return key, 0
+	}
+	n, err := strconv.Atoi(key[i+1:])
+	if err != nil {
+		return key, 0
+	}
+	return key[:i], n
+}
+
+// FormatDiff は差分を指定されたフォーマット（txt, markdown, json）で出力する
+func FormatDiff(diff *SnapshotDiff, format config.OutputFormat) ([]byte, error) {
+	switch format {
+	case config.FormatTXT, "":
+		return formatDiffText(diff), nil
+	case config.FormatMarkdown:
+		return formatDiffMarkdown(diff), nil
+	case config.FormatJSON:
+		return json.MarshalIndent(diff, "", "  ")
+	default:
+		return nil, fmt.Errorf("invalid diff format: %s. Use txt, markdown, or json", format)
+	}
+}
+
+func formatDiffText(diff *SnapshotDiff) []byte {
+	var sb strings.Builder
+
+	sb.WriteString("================================================================================\n")
+	sb.WriteString(fmt.Sprintf("差分: %s → %s\n", diff.From, diff.To))
+	sb.WriteString(fmt.Sprintf("新規: %d件 / 完了・対象外: %d件 / 変更: %d件\n",
+		diff.Summary.Added, diff.Summary.Removed, diff.Summary.Changed))
+	sb.WriteString("================================================================================\n")
+
+	if len(diff.Added) > 0 {
+		sb.WriteString(fmt.Sprintf("\n■ 新規課題（%d件）\n", len(diff.Added)))
+		for _, issue := range diff.Added {
+			sb.WriteString(fmt.Sprintf("  [%s] %s（%s）\n", issue.Key, issue.Summary, describeDiffIssue(issue)))
+		}
+	}
+
+	if len(diff.Removed) > 0 {
+		sb.WriteString(fmt.Sprintf("\n■ 完了・対象外になった課題（%d件）\n", len(diff.Removed)))
+		for _, issue := range diff.Removed {
+			sb.WriteString(fmt.Sprintf("  [%s] %s\n", issue.Key, issue.Summary))
+		}
+	}
+
+	if len(diff.Changed) > 0 {
+		sb.WriteString(fmt.Sprintf("\n■ 変更された課題（%d件）\n", len(diff.Changed)))
+		for _, change := range diff.Changed {
+			sb.WriteString(fmt.Sprintf("  [%s] %s\n", change.Key, change.Summary))
+			for _, fc := range change.Changes {
+				sb.WriteString(fmt.Sprintf("    %s: %s → %s\n",
+					diffFieldLabels[fc.Field], diffValue(fc.Field, fc.Old), diffValue(fc.Field, fc.New)))
+			}
+		}
+	}
+
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) == 0 {
+		sb.WriteString("\n変更はありません\n")
+	}
+
+	return []byte(sb.String())
+}
+
+func formatDiffMarkdown(diff *SnapshotDiff) []byte {
+	var sb strings.Builder
+
+	sb.WriteString("# 課題の差分\n\n")
+	sb.WriteString(fmt.Sprintf("**期間**: %s → %s  \n", diff.From, diff.To))
+	sb.WriteString(fmt.Sprintf("**新規**: %d件 / **完了・対象外**: %d件 / **変更**: %d件\n",
+		diff.Summary.Added, diff.Summary.Removed, diff.Summary.Changed))
+
+	if len(diff.Added) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## 新規課題（%d件）\n\n", len(diff.Added)))
+		for _, issue := range diff.Added {
+			sb.WriteString(fmt.Sprintf("- **%s** %s（%s）\n", issue.Key, issue.Summary, describeDiffIssue(issue)))
+		}
+	}
+
+	if len(diff.Removed) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## 完了・対象外になった課題（%d件）\n\n", len(diff.Removed)))
+		for _, issue := range diff.Removed {
+			sb.WriteString(fmt.Sprintf("- **%s** %s\n", issue.Key, issue.Summary))
+		}
+	}
+
+	if len(diff.Changed) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## 変更された課題（%d件）\n\n", len(diff.Changed)))
+		for _, change := range diff.Changed {
+			sb.WriteString(fmt.Sprintf("- **%s** %s\n", change.Key, change.Summary))
+			for _, fc := range change.Changes {
+				sb.WriteString(fmt.Sprintf("  - %s: %s → %s\n",
+					diffFieldLabels[fc.Field], diffValue(fc.Field, fc.Old), diffValue(fc.Field, fc.New)))
+			}
+		}
+	}
+
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) == 0 {
+		sb.WriteString("\n変更はありません\n")
+	}
+
+	return []byte(sb.String())
+}
+
+// describeDiffIssue は新規課題の状態・担当者・期限日をまとめる
+func describeDiffIssue(issue *SnapshotIssue) string {
+	parts := []string{diffValue(DiffFieldStatus, issue.Status), "担当: " + diffValue(DiffFieldAssignee, issue.Assignee)}
+	if issue.DueDate != "" {
+		parts = append(parts, "期限: "+issue.DueDate)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diffValue は空の値を表示用の文字列に置き換える
+func diffValue(field, value string) string {
+	if value != "" {
+		return value
+	}
+	if field == DiffFieldAssignee {
+		return "(未割当)"
+	}
+	return "-"
+}
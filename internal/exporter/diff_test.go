@@ -0,0 +1,220 @@
+package exporter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func writeSnapshot(t *testing.T, data *backlog.ExportData) string {
+	t.Helper()
+	content, err := (&JSONFormatter{}).Format(data)
+	if err != nil {
+		t.Fatalf("failed to format snapshot: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	}
+	return path
+}
+
+func createChangedExportData() *backlog.ExportData {
+	data := createTestExportData()
+	data.ExportedAt = time.Date(2024, 11, 28, 9, 0, 0, 0, time.UTC)
+
+	parent := data.Issues[0]
+	single := data.Issues[1]
+
+	// 親課題: 状態と期限日が変更
+	parent.Issue.Status = &backlog.Status{ID: 3, Name: "処理済み"}
+	dueDate := "2024-12-15"
+	parent.Issue.DueDate = &dueDate
+
+	// 子課題は完了して消え、単独タスクは担当者が設定されて親課題の下に移動
+	parentID := parent.Issue.ID
+	single.Issue.Assignee = &backlog.User{ID: 2, Name: "鈴木"}
+	single.Issue.ParentIssueID = &parentID
+	parent.Children = []*backlog.HierarchicalIssue{single}
+
+	added := &backlog.HierarchicalIssue{
+		Issue: &backlog.Issue{
+			ID:       300,
+			IssueKey: "MYPROJ-300",
+			Summary:  "新しい課題",
+			Status:   &backlog.Status{ID: 1, Name: "未対応"},
+		},
+	}
+	data.Issues = []*backlog.HierarchicalIssue{parent, added}
+	return data
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	oldSnap, err := LoadSnapshot(writeSnapshot(t, createTestExportData()))
+	if err != nil {
+		t.Fatalf("failed to load old snapshot: %v", err)
+	}
+	newSnap, err := LoadSnapshot(writeSnapshot(t, createChangedExportData()))
+	if err != nil {
+		t.Fatalf("failed to load new snapshot: %v", err)
+	}
+
+	diff := DiffSnapshots(oldSnap, newSnap)
+
+	if len(diff.Added) != 1 || diff.Added[0].Key != "MYPROJ-300" {
+		t.Errorf("unexpected added issues: %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Key != "MYPROJ-101" {
+		t.Errorf("unexpected removed issues: %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 2 {
+		t.Fatalf("expected 2 changed issues, got %+v", diff.Changed)
+	}
+
+	parent := diff.Changed[0]
+	if parent.Key != "MYPROJ-100" {
+		t.Fatalf("expected MYPROJ-100 first, got %s", parent.Key)
+	}
+	expected := []FieldChange{
+		{Field: DiffFieldStatus, Old: "処理中", New: "処理済み"},
+		{Field: DiffFieldDueDate, Old: "2024-12-01", New: "2024-12-15"},
+	}
+	if len(parent.Changes) != len(expected) {
+		t.Fatalf("unexpected changes: %+v", parent.Changes)
+	}
+	for i, c := range expected {
+		if parent.Changes[i] != c {
+			t.Errorf("change %d: expected %+v, got %+v", i, c, parent.Changes[i])
+		}
+	}
+
+	single := diff.Changed[1]
+	expected = []FieldChange{
+		{Field: DiffFieldAssignee, Old: "", New: "鈴木"},
+		{Field: DiffFieldParent, Old: "", New: "MYPROJ-100"},
+	}
+	if len(single.Changes) != len(expected) {
+		t.Fatalf("unexpected changes: %+v", single.Changes)
+	}
+	for i, c := range expected {
+		if single.Changes[i] != c {
+			t.Errorf("change %d: expected %+v, got %+v", i, c, single.Changes[i])
+		}
+	}
+}
+
+func TestDiffSnapshots_NestedParentWithoutID(t *testing.T) {
+	// parentIssueId を含まない古い出力でも入れ子の関係から親課題を判定する
+	old := `{"exportedAt":"a","issues":[{"id":1,"issueKey":"P-1","children":[{"id":2,"issueKey":"P-2","children":[]}]}]}`
+	path := filepath.Join(t.TempDir(), "old.json")
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snap.Issues["P-2"].Parent != "P-1" {
+		t.Errorf("expected parent P-1, got %q", snap.Issues["P-2"].Parent)
+	}
+}
+
+func TestLoadSnapshot_ContextOnlyParent(t *testing.T) {
+	snap, err := LoadSnapshot(writeSnapshot(t, createTestNestedExportData()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 取得条件外の親課題は差分の対象にしないが、子課題の親課題としてキーを使う
+	if _, ok := snap.Issues["MYPROJ-1"]; ok {
+		t.Error("context only parent should not be included in the snapshot")
+	}
+	if len(snap.Issues) != 8 || snap.Issues["MYPROJ-2"].Parent != "MYPROJ-1" {
+		t.Errorf("unexpected snapshot: %d issues, parent %q", len(snap.Issues), snap.Issues["MYPROJ-2"].Parent)
+	}
+}
+
+func TestLoadSnapshot_Multi(t *testing.T) {
+	data := createTestMultiExportData()
+	data.Projects[1].Issues = []*backlog.HierarchicalIssue{
+		{Issue: &backlog.Issue{ID: 900, IssueKey: "OTHER-1", Summary: "別プロジェクトの課題"}},
+	}
+	content, err := (&JSONFormatter{}).FormatMulti(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "combined.json")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snap.Issues) != 4 || snap.Issues["OTHER-1"] == nil {
+		t.Errorf("expected issues from every project, got %d", len(snap.Issues))
+	}
+}
+
+func TestLoadSnapshot_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(path, []byte(`{"foo":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSnapshot(path); err == nil {
+		t.Error("expected error for non-export JSON")
+	}
+}
+
+func TestFormatDiff(t *testing.T) {
+	oldSnap, _ := LoadSnapshot(writeSnapshot(t, createTestExportData()))
+	newSnap, _ := LoadSnapshot(writeSnapshot(t, createChangedExportData()))
+	diff := DiffSnapshots(oldSnap, newSnap)
+
+	txt, err := FormatDiff(diff, config.FormatTXT)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, part := range []string{
+		"新規: 1件 / 完了・対象外: 1件 / 変更: 2件",
+		"[MYPROJ-300] 新しい課題（未対応, 担当: (未割当)）",
+		"[MYPROJ-101] 子課題",
+		"状態: 処理中 → 処理済み",
+		"担当者: (未割当) → 鈴木",
+		"親課題: - → MYPROJ-100",
+	} {
+		if !strings.Contains(string(txt), part) {
+			t.Errorf("expected txt to contain %q", part)
+		}
+	}
+
+	md, err := FormatDiff(diff, config.FormatMarkdown)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(md), "## 変更された課題（2件）") || !strings.Contains(string(md), "  - 期限日: 2024-12-01 → 2024-12-15") {
+		t.Errorf("unexpected markdown:\n%s", md)
+	}
+
+	js, err := FormatDiff(diff, config.FormatJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded SnapshotDiff
+	if err := json.Unmarshal(js, &decoded); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if decoded.Summary != (DiffSummary{Added: 1, Removed: 1, Changed: 2}) {
+		t.Errorf("unexpected summary: %+v", decoded.Summary)
+	}
+
+	if _, err := FormatDiff(diff, config.FormatCSV); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
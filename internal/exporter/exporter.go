@@ -3,23 +3,24 @@ package exporter
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/miyanaga/backlog-exporter/internal/backlog"
 	"github.com/miyanaga/backlog-exporter/internal/config"
 )
 
-// 完了状態のID（デフォルト）
-const defaultCompletedStatusID = 4
-
 // Exporter はBacklogタスクのエクスポートを行う
 type Exporter struct {
 	client    backlog.Client
 	config    *config.Config
 	formatter Formatter
 	output    Output
+	// stdout は --output - でレポートを書き出す先
+	stdout io.Writer
 }
 
 // Output は出力先を抽象化するインターフェース
@@ -34,14 +35,21 @@ func (s *StdOutput) Printf(format string, args ...interface{}) {
 	fmt.Printf(format, args...)
 }
 
+// StderrOutput は標準エラー出力への出力（レポートを標準出力に書き出す場合の進捗表示）
+type StderrOutput struct{}
+
+func (s *StderrOutput) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
 // NewExporter は新しいExporterを作成する
+// レポートを標準出力に書き出す場合（--output -）は進捗を標準エラー出力に表示する
 func NewExporter(client backlog.Client, cfg *config.Config) *Exporter {
-	return &Exporter{
-		client:    client,
-		config:    cfg,
-		formatter: NewFormatter(cfg.Format),
-		output:    &StdOutput{},
+	var output Output = &StdOutput{}
+	if cfg.Output == config.OutputStdout {
+		output = &StderrOutput{}
 	}
+	return NewExporterWithOutput(client, cfg, output)
 }
 
 // NewExporterWithOutput はカスタム出力を使用するExporterを作成する
@@ -49,127 +57,325 @@ func NewExporterWithOutput(client backlog.Client, cfg *config.Config, output Out
 	return &Exporter{
 		client:    client,
 		config:    cfg,
-		formatter: NewFormatter(cfg.Format),
+		formatter: NewFormatterFromConfig(cfg),
 		output:    output,
+		stdout:    os.Stdout,
 	}
 }
 
-// Run はエクスポート処理を実行する
+// Run はエクスポート処理を実行し、出力したファイルのパスを返す
+// 複数プロジェクトを個別のファイルに出力した場合は最初のファイルのパスを返す
 func (e *Exporter) Run(ctx context.Context) (string, error) {
-	// 1. プロジェクト情報を取得
+	paths, err := e.RunAll(ctx)
+	if err != nil {
+		return "", err
+	}
+	return paths[0], nil
+}
+
+// RunAll はエクスポート処理を実行し、出力したすべてのファイルのパスを返す
+func (e *Exporter) RunAll(ctx context.Context) ([]string, error) {
 	e.output.Printf("Connecting to %s.%s...\n", e.config.Space, e.config.Domain)
 
-	project, err := e.client.GetProject(ctx, e.config.Project)
+	projectKeys, err := e.resolveProjectKeys(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get project: %w", err)
+		return nil, err
 	}
 
-	e.output.Printf("Project: %s (%s)\n", project.ProjectKey, project.Name)
+	switch e.config.Format {
+	case config.FormatNDJSON:
+		return e.runStream(ctx, projectKeys)
+	case config.FormatSQLite:
+		return e.runSQLite(ctx, projectKeys)
+	}
+	if len(projectKeys) > 1 {
+		return e.runMulti(ctx, projectKeys)
+	}
 
-	// 2. 状態一覧を取得
-	e.output.Printf("Fetching statuses... ")
-	statuses, err := e.client.GetStatuses(ctx, e.config.Project)
+	exportData, err := e.exportProject(ctx, projectKeys[0])
 	if err != nil {
-		return "", fmt.Errorf("failed to get statuses: %w", err)
+		return nil, err
+	}
+
+	// サマリー表示
+	e.output.Printf("Summary:\n")
+	e.output.Printf("  Total issues: %d\n", exportData.Summary.Total)
+	e.output.Printf("  Parent issues: %d\n", exportData.Summary.ParentIssues)
+	e.output.Printf("  Child issues: %d\n\n", exportData.Summary.ChildIssues)
+
+	// フォーマットして保存
+	content, err := e.formatter.Format(exportData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format output: %w", err)
 	}
-	e.output.Printf("done\n")
 
-	// 3. 完了以外の状態IDを特定
-	incompleteStatusIDs := e.getIncompleteStatusIDs(statuses)
+	outputPath, err := e.writeReport(exportData.Project.ProjectKey, content)
+	if err != nil {
+		return nil, err
+	}
+
+	e.output.Printf("Output: %s\n", outputPath)
+	e.output.Printf("Done!\n")
+
+	return []string{outputPath}, nil
+}
 
-	// 4. 課題一覧を取得
-	issues, err := e.client.GetIssues(ctx, project.ID, incompleteStatusIDs, e.config.Assignee, func(fetched, total int) {
-		if total > 0 && fetched == total {
-			e.output.Printf("Fetching issues... %d/%d (complete)\n", fetched, total)
-		} else {
-			e.output.Printf("Fetching issues... %d\n", fetched)
+// Render は課題を取得してフォーマットした内容を返す（ファイルには保存しない）
+// 複数プロジェクトの場合は1つのレポートにまとめる
+func (e *Exporter) Render(ctx context.Context) ([]byte, error) {
+	projectKeys, err := e.resolveProjectKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(projectKeys) == 1 {
+		exportData, err := e.exportProject(ctx, projectKeys[0])
+		if err != nil {
+			return nil, err
 		}
-	})
+		content, err := e.formatter.Format(exportData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format output: %w", err)
+		}
+		return content, nil
+	}
+
+	results, err := e.exportProjects(ctx, projectKeys)
+	if err != nil {
+		return nil, err
+	}
+	return e.formatMulti(newMultiExportData(results))
+}
+
+// Extension は出力ファイルの拡張子を返す
+func (e *Exporter) Extension() string {
+	return e.formatter.Extension()
+}
+
+// exportProject は1つのプロジェクトの課題を取得してエクスポートデータを作成する
+func (e *Exporter) exportProject(ctx context.Context, projectIDOrKey string) (*backlog.ExportData, error) {
+	where, err := parseWhere(e.config.Where)
+	if err != nil {
+		return nil, err
+	}
+	sortKeys, err := parseSortKeys(e.config.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1-3. プロジェクト情報を取得し、取得条件をIDに解決
+	project, filter, err := e.prepareProject(ctx, projectIDOrKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to get issues: %w", err)
+		return nil, err
 	}
 
-	// 5. 親子関係を構造化
+	// 4. 課題一覧を取得（差分モードでは前回以降の変更のみ取得して反映）
+	var prev *exportState
+	if e.config.Incremental {
+		prev, err = e.loadState(project.ProjectKey)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case prev == nil:
+		case !filter.localMatch():
+			e.output.Printf("Keyword and parent-child filters cannot be applied to updated issues. Fetching all issues.\n")
+			prev = nil
+		case !prev.reusable(filter, e.config.WithComments, e.config.WithAttachments):
+			e.output.Printf("Export conditions changed since the last run. Fetching all issues.\n")
+			prev = nil
+		}
+	}
+
+	startedAt := time.Now()
+	var issues, changed []*backlog.Issue
+	if prev != nil {
+		issues, changed, err = e.fetchIssueChanges(ctx, project.ID, prev, filter)
+	} else {
+		issues, err = e.client.GetIssues(ctx, filter.query(project.ID), e.printIssueProgress)
+		issues = filter.apply(issues)
+		changed = issues
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issues: %w", err)
+	}
+
+	// 5. 条件式で絞り込み、親子関係を構造化して並べ替え（--with-parents では取得条件外の親課題で階層を補う）
+	matched := issues
+	if where != nil {
+		matched = where.filter(issues)
+		e.output.Printf("Filtered by --where: %d of %d issues\n", len(matched), len(issues))
+		// 差分モードでは条件式を変えても使えるよう、絞り込み前の課題のコメント・添付ファイルを取得する
+		if !e.config.Incremental {
+			changed = matched
+		}
+	}
+	var parents []*backlog.Issue
+	if e.config.WithParents {
+		parents, err = e.fetchParents(ctx, matched, issues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent issues: %w", err)
+		}
+	}
 	e.output.Printf("Building hierarchy... ")
-	hierarchicalIssues, summary := e.buildHierarchy(issues)
-	e.output.Printf("done\n\n")
+	hierarchicalIssues, summary := e.buildHierarchy(matched, parents)
+	sortIssues(hierarchicalIssues, sortKeys)
+	e.output.Printf("done\n")
 
-	// 6. サマリー表示
-	e.output.Printf("Summary:\n")
-	e.output.Printf("  Total issues: %d\n", summary.Total)
-	e.output.Printf("  Parent issues: %d\n", summary.ParentIssues)
-	e.output.Printf("  Child issues: %d\n\n", summary.ChildIssues)
+	state := &exportState{
+		ExportedAt:      startedAt,
+		Filter:          filter,
+		Issues:          issues,
+		WithComments:    e.config.WithComments,
+		WithAttachments: e.config.WithAttachments,
+	}
 
-	// 7. エクスポートデータを作成
-	exportData := &backlog.ExportData{
+	// 6. コメントを取得（オプション、差分モードでは更新された課題のみ）
+	if e.config.WithComments {
+		comments, err := e.fetchComments(ctx, changed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get comments: %w", err)
+		}
+		if prev != nil {
+			comments = mergeIssueValues(prev.Comments, comments, issues, changed)
+		}
+		attachComments(hierarchicalIssues, comments)
+		state.Comments = comments
+	}
+
+	// 7. 添付ファイルを保存（オプション、差分モードでは更新された課題のみ）
+	if e.config.WithAttachments {
+		attachments, err := e.downloadAttachments(ctx, changed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export attachments: %w", err)
+		}
+		if prev != nil {
+			attachments = mergeIssueValues(prev.Attachments, attachments, issues, changed)
+		}
+		attachAttachments(hierarchicalIssues, attachments)
+		state.Attachments = attachments
+	}
+
+	// 8. 次回の差分取得のために状態を保存
+	if e.config.Incremental {
+		if err := e.saveState(project.ProjectKey, state); err != nil {
+			return nil, err
+		}
+	}
+	e.output.Printf("\n")
+
+	return &backlog.ExportData{
 		Project:    project,
 		ExportedAt: time.Now(),
 		Summary:    summary,
 		Issues:     hierarchicalIssues,
+		GroupBy:    e.config.GroupBy,
+		Groups:     groupIssues(hierarchicalIssues, e.config.GroupBy),
+	}, nil
+}
+
+// prepareProject はプロジェクト情報と状態一覧を取得し、取得条件（状態・担当者・課題種別など）をIDに解決する
+func (e *Exporter) prepareProject(ctx context.Context, projectIDOrKey string) (*backlog.Project, *issueFilter, error) {
+	// 1. プロジェクト情報を取得
+	project, err := e.client.GetProject(ctx, projectIDOrKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get project: %w", err)
 	}
 
-	// 8. フォーマットして出力
-	content, err := e.formatter.Format(exportData)
+	e.output.Printf("Project: %s (%s)\n", project.ProjectKey, project.Name)
+
+	// 2. 状態一覧を取得
+	e.output.Printf("Fetching statuses... ")
+	statuses, err := e.client.GetStatuses(ctx, projectIDOrKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get statuses: %w", err)
+	}
+	e.output.Printf("done\n")
+
+	// 3. 取得条件をIDに解決
+	filter, err := e.resolveIssueFilter(ctx, projectIDOrKey, statuses)
 	if err != nil {
-		return "", fmt.Errorf("failed to format output: %w", err)
+		return nil, nil, err
 	}
+	return project, filter, nil
+}
 
-	// 9. ファイルに保存
-	filename := e.generateFilename(project.ProjectKey)
-	outputPath := filepath.Join(e.config.Output, filename)
+// writeReport はレポートを出力ディレクトリに保存してパスを返す
+// --output-file ではそのパスに保存し、--output - では標準出力に書き出して "-" を返す
+func (e *Exporter) writeReport(name string, content []byte) (string, error) {
+	if e.config.Output == config.OutputStdout {
+		if _, err := e.stdout.Write(content); err != nil {
+			return "", fmt.Errorf("failed to write output: %w", err)
+		}
+		return config.OutputStdout, nil
+	}
 
+	outputPath := e.reportPath(name)
 	if err := os.WriteFile(outputPath, content, 0644); err != nil {
 		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
-	e.output.Printf("Output: %s\n", outputPath)
-	e.output.Printf("Done!\n")
-
 	return outputPath, nil
 }
 
-// getIncompleteStatusIDs は完了以外の状態IDを取得する
-func (e *Exporter) getIncompleteStatusIDs(statuses []*backlog.Status) []int {
-	var ids []int
-	for _, s := range statuses {
-		// 「完了」を除外（デフォルトのID=4、または名前が「完了」のもの）
-		if s.ID == defaultCompletedStatusID || s.Name == "完了" {
-			continue
-		}
-		ids = append(ids, s.ID)
+// reportPath はレポートの保存先のパスを返す（--output-file が指定された場合はそのパス）
+func (e *Exporter) reportPath(name string) string {
+	if e.config.OutputFile != "" {
+		return e.config.OutputFile
 	}
-	return ids
+	return filepath.Join(e.config.Output, e.generateFilename(name))
 }
 
 // buildHierarchy は課題一覧から親子階層を構築する
-func (e *Exporter) buildHierarchy(issues []*backlog.Issue) ([]*backlog.HierarchicalIssue, backlog.ExportSummary) {
+// parents は取得条件に一致しない親課題（--with-parents）で、ContextOnly として階層に含めるが件数には含めない
+// 取得条件外の親課題は、最初に現れる子孫の位置にルートとして置く
+func (e *Exporter) buildHierarchy(issues, parents []*backlog.Issue) ([]*backlog.HierarchicalIssue, backlog.ExportSummary) {
 	// ID -> HierarchicalIssue のマップを作成
-	issueMap := make(map[int]*backlog.HierarchicalIssue)
+	issueMap := make(map[int]*backlog.HierarchicalIssue, len(issues)+len(parents))
 	for _, issue := range issues {
 		issueMap[issue.ID] = &backlog.HierarchicalIssue{
 			Issue:    issue,
 			Children: make([]*backlog.HierarchicalIssue, 0),
 		}
 	}
+	for _, issue := range parents {
+		issueMap[issue.ID] = &backlog.HierarchicalIssue{
+			Issue:       issue,
+			Children:    make([]*backlog.HierarchicalIssue, 0),
+			ContextOnly: true,
+		}
+	}
 
 	// 親子関係を構築
-	var roots []*backlog.HierarchicalIssue
 	childCount := 0
-
-	for _, issue := range issues {
+	parentOf := func(hi *backlog.HierarchicalIssue) *backlog.HierarchicalIssue {
+		if hi.Issue.ParentIssueID == nil {
+			return nil
+		}
+		return issueMap[*hi.Issue.ParentIssueID]
+	}
+	for _, issue := range append(slices.Clip(issues), parents...) {
 		hi := issueMap[issue.ID]
-		if issue.ParentIssueID != nil {
-			// 親課題がある場合
-			if parent, ok := issueMap[*issue.ParentIssueID]; ok {
-				parent.Children = append(parent.Children, hi)
+		if parent := parentOf(hi); parent != nil {
+			parent.Children = append(parent.Children, hi)
+			if !hi.ContextOnly {
 				childCount++
-			} else {
-				// 親課題が取得対象に含まれていない場合はルートとして扱う
-				roots = append(roots, hi)
 			}
-		} else {
-			// 親課題がない場合はルートに追加
-			roots = append(roots, hi)
+		}
+	}
+
+	// 親課題がない課題と、親課題が取得対象に含まれていない課題はルートとして扱う
+	var roots []*backlog.HierarchicalIssue
+	placed := make(map[int]bool)
+	for _, issue := range issues {
+		hi := issueMap[issue.ID]
+		root := hi
+		for parent := parentOf(root); parent != nil && parent.ContextOnly; parent = parentOf(root) {
+			root = parent
+		}
+		if parentOf(root) == nil && !placed[root.Issue.ID] {
+			placed[root.Issue.ID] = true
+			roots = append(roots, root)
 		}
 	}
 
@@ -182,8 +388,54 @@ func (e *Exporter) buildHierarchy(issues []*backlog.Issue) ([]*backlog.Hierarchi
 	return roots, summary
 }
 
+// fetchComments は各課題のコメントを取得する（課題ID -> コメント一覧）
+func (e *Exporter) fetchComments(ctx context.Context, issues []*backlog.Issue) (map[int][]*backlog.Comment, error) {
+	result := make(map[int][]*backlog.Comment, len(issues))
+
+	e.output.Printf("Fetching comments... ")
+	for _, issue := range issues {
+		comments, err := e.client.GetComments(ctx, issue.IssueKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", issue.IssueKey, err)
+		}
+
+		// 状態変更のみで本文のないコメントは除外
+		var withContent []*backlog.Comment
+		for _, c := range comments {
+			if c.Content != "" {
+				withContent = append(withContent, c)
+			}
+		}
+		result[issue.ID] = withContent
+	}
+	e.output.Printf("done\n")
+
+	return result, nil
+}
+
+// attachComments は取得したコメントを階層構造の各課題に設定する
+func attachComments(issues []*backlog.HierarchicalIssue, comments map[int][]*backlog.Comment) {
+	for _, hi := range issues {
+		hi.Comments = comments[hi.Issue.ID]
+		attachComments(hi.Children, comments)
+	}
+}
+
 // generateFilename は出力ファイル名を生成する
 func (e *Exporter) generateFilename(projectKey string) string {
 	timestamp := time.Now().Format("20060102_150405")
 	return fmt.Sprintf("%s_tasks_%s.%s", projectKey, timestamp, e.formatter.Extension())
 }
+
+// printIssueProgress は課題取得の進捗を表示する
+// 総数が分からない場合（-1）は取得数のみ表示する
+func (e *Exporter) printIssueProgress(fetched, total int) {
+	switch {
+	case total > 0 && fetched == total:
+		e.output.Printf("Fetching issues... %d/%d (complete)\n", fetched, total)
+	case total > 0:
+		e.output.Printf("Fetching issues... %d/%d\n", fetched, total)
+	default:
+		e.output.Printf("Fetching issues... %d\n", fetched)
+	}
+}
@@ -1,7 +1,9 @@
 package exporter
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -84,9 +86,9 @@ func TestExporter_Run(t *testing.T) {
 		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
 			return statuses, nil
 		},
-		GetIssuesFunc: func(ctx context.Context, projectID int, statusIDs []int, assigneeID *int, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
 			// 完了以外の状態のみを要求しているか確認
-			for _, id := range statusIDs {
+			for _, id := range query.StatusIDs {
 				if id == 4 {
 					t.Error("status ID 4 (完了) should not be requested")
 				}
@@ -162,7 +164,7 @@ func TestExporter_BuildHierarchy(t *testing.T) {
 	}
 	exp := NewExporter(&backlog.MockClient{}, cfg)
 
-	hierarchical, summary := exp.buildHierarchy(issues)
+	hierarchical, summary := exp.buildHierarchy(issues, nil)
 
 	// 親課題（またはスタンドアロン課題）は2つ
 	if len(hierarchical) != 2 {
@@ -208,7 +210,7 @@ func TestExporter_OutputFormats(t *testing.T) {
 		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
 			return statuses, nil
 		},
-		GetIssuesFunc: func(ctx context.Context, projectID int, statusIDs []int, assigneeID *int, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
 			return issues, nil
 		},
 	}
@@ -280,3 +282,202 @@ func TestExporter_OutputFormats(t *testing.T) {
 		})
 	}
 }
+
+func TestExporter_Run_WithComments(t *testing.T) {
+	project, statuses, issues := createTestData()
+
+	var requested []string
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			return issues, nil
+		},
+		GetCommentsFunc: func(ctx context.Context, issueIDOrKey string) ([]*backlog.Comment, error) {
+			requested = append(requested, issueIDOrKey)
+			if issueIDOrKey != "MYPROJ-101" {
+				return nil, nil
+			}
+			return []*backlog.Comment{
+				{ID: 1, Content: "", CreatedUser: &backlog.User{Name: "山田"}},
+				{ID: 2, Content: "子課題へのコメント", CreatedUser: &backlog.User{Name: "山田"}},
+			}, nil
+		},
+	}
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Project:      "MYPROJ",
+		Output:       tmpDir,
+		Format:       config.FormatJSON,
+		WithComments: true,
+	}
+
+	exp := NewExporterWithOutput(mockClient, cfg, &testOutput{})
+	outputPath, err := exp.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(requested) != len(issues) {
+		t.Errorf("expected comments to be fetched for %d issues, got %d", len(issues), len(requested))
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+
+	contentStr := string(content)
+	if !strings.Contains(contentStr, "子課題へのコメント") {
+		t.Error("output should contain child issue comment")
+	}
+	if strings.Count(contentStr, `"author"`) != 1 {
+		t.Error("comments without content should be skipped")
+	}
+}
+
+func TestExporter_Run_WithAttachments(t *testing.T) {
+	project, statuses, issues := createTestData()
+
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			return issues, nil
+		},
+		GetAttachmentsFunc: func(ctx context.Context, issueIDOrKey string) ([]*backlog.Attachment, error) {
+			if issueIDOrKey != "MYPROJ-100" {
+				return nil, nil
+			}
+			return []*backlog.Attachment{
+				{ID: 1, Name: "design.png", Size: 5},
+				{ID: 2, Name: "design.png", Size: 5},
+				{ID: 3, Name: "../evil.txt", Size: 5},
+			}, nil
+		},
+		DownloadAttachmentFunc: func(ctx context.Context, issueIDOrKey string, attachmentID int, w io.Writer) error {
+			_, err := w.Write([]byte("hello"))
+			return err
+		},
+	}
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Project:         "MYPROJ",
+		Output:          tmpDir,
+		Format:          config.FormatMarkdown,
+		WithAttachments: true,
+	}
+
+	exp := NewExporterWithOutput(mockClient, cfg, &testOutput{})
+	outputPath, err := exp.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"design.png", "2_design.png", ".._evil.txt"} {
+		path := filepath.Join(tmpDir, "MYPROJ-100", "attachments", name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("attachment %s was not saved: %v", name, err)
+			continue
+		}
+		if string(content) != "hello" {
+			t.Errorf("unexpected content in %s: %q", name, content)
+		}
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	if !strings.Contains(string(content), "[design.png](<MYPROJ-100/attachments/design.png>)") {
+		t.Error("markdown should link to saved attachment by relative path")
+	}
+}
+
+func TestExporter_Run_Stdout(t *testing.T) {
+	project, statuses, issues := createTestData()
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			return issues, nil
+		},
+	}
+
+	cfg := &config.Config{Project: "MYPROJ", Output: config.OutputStdout, Format: config.FormatJSON}
+	exp := NewExporter(mockClient, cfg)
+	if _, ok := exp.output.(*StderrOutput); !ok {
+		t.Errorf("progress should be written to stderr, got %T", exp.output)
+	}
+
+	var stdout bytes.Buffer
+	exp.output = &testOutput{}
+	exp.stdout = &stdout
+	outputPath, err := exp.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outputPath != config.OutputStdout {
+		t.Errorf("expected %q, got %q", config.OutputStdout, outputPath)
+	}
+	if !strings.HasPrefix(stdout.String(), "{") || !strings.Contains(stdout.String(), `"issueKey": "MYPROJ-101"`) {
+		t.Errorf("report should be written to stdout:\n%s", stdout.String())
+	}
+}
+
+func TestExporter_Run_OutputFile(t *testing.T) {
+	project, statuses, issues := createTestData()
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			return issues, nil
+		},
+	}
+
+	tmpDir := t.TempDir()
+	outputFile := filepath.Join(tmpDir, "weekly.md")
+	cfg := &config.Config{
+		Project:     "MYPROJ",
+		Output:      t.TempDir(),
+		OutputFile:  outputFile,
+		Format:      config.FormatMarkdown,
+		Incremental: true,
+	}
+
+	outputPath, err := NewExporterWithOutput(mockClient, cfg, &testOutput{}).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outputPath != outputFile {
+		t.Errorf("expected %s, got %s", outputFile, outputPath)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil || !strings.Contains(string(content), "# MYPROJ - マイプロジェクト") {
+		t.Errorf("unexpected output file: %v\n%s", err, content)
+	}
+
+	// 差分取得の状態ファイルは出力ファイルと同じディレクトリに保存する
+	if _, err := os.Stat(filepath.Join(tmpDir, ".backlog-tasks-state-MYPROJ.json")); err != nil {
+		t.Errorf("state file should be saved next to the output file: %v", err)
+	}
+}
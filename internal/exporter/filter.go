@@ -0,0 +1,253 @@
+package exporter
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// parentChildValues は --parent-child の値とAPIの parentChild の対応
+var parentChildValues = map[string]backlog.ParentChild{
+	"":                           backlog.ParentChildAll,
+	config.ParentChildAll:        backlog.ParentChildAll,
+	config.ParentChildNotChild:   backlog.ParentChildNotChild,
+	config.ParentChildChild:      backlog.ParentChildChild,
+	config.ParentChildStandalone: backlog.ParentChildStandalone,
+	config.ParentChildParent:     backlog.ParentChildParent,
+}
+
+// issueFilter は名前などの指定をIDに解決した課題の取得条件
+// 差分エクスポートでは前回の条件との比較のために状態ファイルに保存する
+type issueFilter struct {
+	StatusIDs []int `json:"statusIds"`
+	// AssigneeIDs はいずれかが担当者である課題のみ取得するユーザーID
+	AssigneeIDs []int `json:"assigneeIds,omitempty"`
+	// Unassigned が true の場合は担当者が未設定の課題も対象にする
+	Unassigned   bool   `json:"unassigned,omitempty"`
+	IssueTypeIDs []int  `json:"issueTypeIds,omitempty"`
+	CategoryIDs  []int  `json:"categoryIds,omitempty"`
+	MilestoneIDs []int  `json:"milestoneIds,omitempty"`
+	VersionIDs   []int  `json:"versionIds,omitempty"`
+	PriorityIDs  []int  `json:"priorityIds,omitempty"`
+	Keyword      string `json:"keyword,omitempty"`
+	// CreatedSince などは日付（YYYY-MM-DD）
+	CreatedSince string               `json:"createdSince,omitempty"`
+	CreatedUntil string               `json:"createdUntil,omitempty"`
+	UpdatedSince string               `json:"updatedSince,omitempty"`
+	UpdatedUntil string               `json:"updatedUntil,omitempty"`
+	DueSince     string               `json:"dueSince,omitempty"`
+	DueUntil     string               `json:"dueUntil,omitempty"`
+	ParentChild  backlog.ParentChild  `json:"parentChild,omitempty"`
+	CustomFields []*customFieldFilter `json:"customFields,omitempty"`
+}
+
+// resolveIssueFilter は設定の取得条件をプロジェクトの状態・ユーザーなどのIDに解決する
+func (e *Exporter) resolveIssueFilter(ctx context.Context, projectIDOrKey string, statuses []*backlog.Status) (*issueFilter, error) {
+	statusIDs, err := e.resolveStatusIDs(statuses)
+	if err != nil {
+		return nil, err
+	}
+	filter := &issueFilter{
+		StatusIDs:    statusIDs,
+		Keyword:      strings.TrimSpace(e.config.Keyword),
+		CreatedSince: e.config.CreatedSince,
+		CreatedUntil: e.config.CreatedUntil,
+		UpdatedSince: e.config.UpdatedSince,
+		UpdatedUntil: e.config.UpdatedUntil,
+		DueSince:     e.config.DueSince,
+		DueUntil:     e.config.DueUntil,
+		ParentChild:  parentChildValues[e.config.ParentChild],
+	}
+
+	if specs := e.assigneeSpecs(); len(specs) > 0 {
+		e.output.Printf("Resolving assignees... ")
+		filter.AssigneeIDs, filter.Unassigned, err = e.resolveAssignees(ctx, projectIDOrKey, specs)
+		if err != nil {
+			e.output.Printf("failed\n")
+			return nil, err
+		}
+		e.output.Printf("done\n")
+	}
+
+	if err := e.resolveLookups(ctx, projectIDOrKey, filter); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
+
+// query はAPIに渡す取得条件を返す
+// 未割当の課題はAPIの担当者指定では取得できないため、その場合は担当者で絞り込まずに取得して apply で絞り込む
+func (f *issueFilter) query(projectID int) backlog.IssueQuery {
+	query := backlog.IssueQuery{
+		ProjectID:    projectID,
+		StatusIDs:    f.StatusIDs,
+		IssueTypeIDs: f.IssueTypeIDs,
+		CategoryIDs:  f.CategoryIDs,
+		MilestoneIDs: f.MilestoneIDs,
+		VersionIDs:   f.VersionIDs,
+		PriorityIDs:  f.PriorityIDs,
+		Keyword:      f.Keyword,
+		CreatedSince: parseFilterDate(f.CreatedSince),
+		CreatedUntil: parseFilterDate(f.CreatedUntil),
+		UpdatedSince: parseFilterDate(f.UpdatedSince),
+		UpdatedUntil: parseFilterDate(f.UpdatedUntil),
+		DueDateSince: parseFilterDate(f.DueSince),
+		DueDateUntil: parseFilterDate(f.DueUntil),
+		ParentChild:  f.ParentChild,
+	}
+	if !f.Unassigned {
+		query.AssigneeIDs = f.AssigneeIDs
+	}
+	for _, cf := range f.CustomFields {
+		query.CustomFields = append(query.CustomFields, cf.query())
+	}
+	return query
+}
+
+// parseFilterDate は日付の条件を time.Time に変換する（空の場合は nil）
+// 日付の形式は設定の検証時に確認済み
+func parseFilterDate(date string) *time.Time {
+	if date == "" {
+		return nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// match は課題が取得条件を満たすかどうかを判定する
+// キーワードと親課題の有無は課題単体からは判定できないため確認しない（localMatch を参照）
+func (f *issueFilter) match(issue *backlog.Issue) bool {
+	if issue.Status == nil || !slices.Contains(f.StatusIDs, issue.Status.ID) {
+		return false
+	}
+	if !f.matchAssignee(issue) {
+		return false
+	}
+	if len(f.IssueTypeIDs) > 0 && (issue.IssueType == nil || !slices.Contains(f.IssueTypeIDs, issue.IssueType.ID)) {
+		return false
+	}
+	if len(f.PriorityIDs) > 0 && (issue.Priority == nil || !slices.Contains(f.PriorityIDs, issue.Priority.ID)) {
+		return false
+	}
+	if !matchAnyID(f.CategoryIDs, issue.Category, func(c *backlog.Category) int { return c.ID }) ||
+		!matchAnyID(f.MilestoneIDs, issue.Milestone, func(v *backlog.Version) int { return v.ID }) ||
+		!matchAnyID(f.VersionIDs, issue.Versions, func(v *backlog.Version) int { return v.ID }) {
+		return false
+	}
+	if !inDateRange(localDate(issue.Created), f.CreatedSince, f.CreatedUntil) ||
+		!inDateRange(localDate(issue.Updated), f.UpdatedSince, f.UpdatedUntil) ||
+		!inDateRange(csvDate(issue.DueDate), f.DueSince, f.DueUntil) {
+		return false
+	}
+	for _, cf := range f.CustomFields {
+		if !cf.match(issue) {
+			return false
+		}
+	}
+	switch f.ParentChild {
+	case backlog.ParentChildChild:
+		return issue.ParentIssueID != nil
+	case backlog.ParentChildNotChild, backlog.ParentChildStandalone, backlog.ParentChildParent:
+		return issue.ParentIssueID == nil
+	}
+	return true
+}
+
+func (f *issueFilter) matchAssignee(issue *backlog.Issue) bool {
+	if len(f.AssigneeIDs) == 0 && !f.Unassigned {
+		return true
+	}
+	if issue.Assignee == nil {
+		return f.Unassigned
+	}
+	return slices.Contains(f.AssigneeIDs, issue.Assignee.ID)
+}
+
+// localMatch は match だけで取得条件を判定できるかどうかを返す
+// 判定できない条件がある場合は差分エクスポートでも全件を取得する
+func (f *issueFilter) localMatch() bool {
+	return f.Keyword == "" && f.ParentChild != backlog.ParentChildStandalone && f.ParentChild != backlog.ParentChildParent
+}
+
+// matchAnyID は課題の値のいずれかが ids に含まれるかどうかを判定する（ids が空の場合は常に true）
+func matchAnyID[T any](ids []int, values []T, id func(T) int) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if slices.Contains(ids, id(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+// inDateRange は日付が範囲内（since・until を含む）かどうかを判定する
+func inDateRange(date, since, until string) bool {
+	if since == "" && until == "" {
+		return true
+	}
+	if date == "" {
+		return false
+	}
+	return (since == "" || date >= since) && (until == "" || date <= until)
+}
+
+// localDate は日時をローカルタイムゾーンの日付にする
+func localDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// apply はAPIで絞り込めなかった条件で課題一覧を絞り込む
+func (f *issueFilter) apply(issues []*backlog.Issue) []*backlog.Issue {
+	if !f.Unassigned {
+		return issues
+	}
+	filtered := make([]*backlog.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if f.matchAssignee(issue) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// equal は取得条件が同じかどうかを判定する（IDの指定の順序は区別しない）
+func (f *issueFilter) equal(other *issueFilter) bool {
+	return reflect.DeepEqual(f.normalized(), other.normalized())
+}
+
+// normalized はIDの一覧を並べ替えた取得条件を返す
+func (f *issueFilter) normalized() issueFilter {
+	n := *f
+	for _, ids := range []*[]int{&n.StatusIDs, &n.AssigneeIDs, &n.IssueTypeIDs, &n.CategoryIDs, &n.MilestoneIDs, &n.VersionIDs, &n.PriorityIDs} {
+		if len(*ids) == 0 {
+			*ids = nil
+		} else {
+			*ids = sortedInts(*ids)
+		}
+	}
+	n.CustomFields = nil
+	for _, cf := range f.CustomFields {
+		c := *cf
+		if len(c.ItemIDs) == 0 {
+			c.ItemIDs = nil
+		} else {
+			c.ItemIDs = sortedInts(c.ItemIDs)
+		}
+		n.CustomFields = append(n.CustomFields, &c)
+	}
+	return n
+}
@@ -0,0 +1,145 @@
+package exporter
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func createTestLookupClient() *backlog.MockClient {
+	return &backlog.MockClient{
+		GetIssueTypesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.IssueType, error) {
+			return []*backlog.IssueType{{ID: 10, Name: "タスク"}, {ID: 11, Name: "Bug"}}, nil
+		},
+		GetCategoriesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Category, error) {
+			return []*backlog.Category{{ID: 20, Name: "フロントエンド"}, {ID: 21, Name: "API"}}, nil
+		},
+		GetVersionsFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Version, error) {
+			return []*backlog.Version{{ID: 30, Name: "Sprint 12"}, {ID: 31, Name: "v1.0"}}, nil
+		},
+		GetPrioritiesFunc: func(ctx context.Context) ([]*backlog.Priority, error) {
+			return []*backlog.Priority{{ID: 2, Name: "高"}, {ID: 3, Name: "中"}, {ID: 4, Name: "低"}}, nil
+		},
+	}
+}
+
+func TestExporter_ResolveIssueFilter(t *testing.T) {
+	_, statuses, _ := createTestData()
+
+	cfg := &config.Config{
+		IssueTypes:   []string{"bug"},
+		Categories:   []string{"API", "20"},
+		Milestones:   []string{"Sprint 12"},
+		Versions:     []string{"v1.0"},
+		Priorities:   []string{"高", "中"},
+		Keyword:      " ログイン ",
+		CreatedSince: "2024-11-01",
+		DueUntil:     "2024-11-30",
+		ParentChild:  config.ParentChildNotChild,
+	}
+	exp := NewExporterWithOutput(createTestLookupClient(), cfg, &testOutput{})
+
+	filter, err := exp.resolveIssueFilter(context.Background(), "MYPROJ", statuses)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query := filter.query(1)
+	if !slices.Equal(query.IssueTypeIDs, []int{11}) ||
+		!slices.Equal(query.CategoryIDs, []int{21, 20}) ||
+		!slices.Equal(query.MilestoneIDs, []int{30}) ||
+		!slices.Equal(query.VersionIDs, []int{31}) ||
+		!slices.Equal(query.PriorityIDs, []int{2, 3}) {
+		t.Errorf("unexpected query IDs: %+v", query)
+	}
+	if query.Keyword != "ログイン" {
+		t.Errorf("expected trimmed keyword, got %q", query.Keyword)
+	}
+	if query.CreatedSince == nil || query.CreatedSince.Format("2006-01-02") != "2024-11-01" {
+		t.Errorf("unexpected createdSince: %v", query.CreatedSince)
+	}
+	if query.DueDateUntil == nil || query.DueDateUntil.Format("2006-01-02") != "2024-11-30" {
+		t.Errorf("unexpected dueDateUntil: %v", query.DueDateUntil)
+	}
+	if query.ParentChild != backlog.ParentChildNotChild {
+		t.Errorf("unexpected parentChild: %v", query.ParentChild)
+	}
+}
+
+func TestExporter_ResolveIssueFilter_Unknown(t *testing.T) {
+	_, statuses, _ := createTestData()
+
+	cfg := &config.Config{Milestones: []string{"Sprint 99"}}
+	exp := NewExporterWithOutput(createTestLookupClient(), cfg, &testOutput{})
+
+	_, err := exp.resolveIssueFilter(context.Background(), "MYPROJ", statuses)
+	if err == nil {
+		t.Fatal("expected error for unknown milestone")
+	}
+	for _, part := range []string{`unknown milestone "Sprint 99"`, "Sprint 12 (30)", "v1.0 (31)"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("expected error to contain %q, got %v", part, err)
+		}
+	}
+}
+
+func TestIssueFilter_Match(t *testing.T) {
+	dueDate := "2024-11-20T00:00:00Z"
+	parentID := 100
+	issue := &backlog.Issue{
+		ID:        101,
+		Status:    &backlog.Status{ID: 1},
+		IssueType: &backlog.IssueType{ID: 11},
+		Priority:  &backlog.Priority{ID: 2},
+		Category:  []*backlog.Category{{ID: 20}, {ID: 21}},
+		Milestone: []*backlog.Version{{ID: 30}},
+		DueDate:   &dueDate,
+		Created:   time.Date(2024, 11, 10, 12, 0, 0, 0, time.Local),
+	}
+	child := *issue
+	child.ParentIssueID = &parentID
+
+	tests := []struct {
+		name   string
+		filter issueFilter
+		issue  *backlog.Issue
+		want   bool
+	}{
+		{name: "status only", filter: issueFilter{StatusIDs: []int{1}}, issue: issue, want: true},
+		{name: "issue type", filter: issueFilter{StatusIDs: []int{1}, IssueTypeIDs: []int{10}}, issue: issue, want: false},
+		{name: "any category", filter: issueFilter{StatusIDs: []int{1}, CategoryIDs: []int{21, 99}}, issue: issue, want: true},
+		{name: "milestone", filter: issueFilter{StatusIDs: []int{1}, MilestoneIDs: []int{31}}, issue: issue, want: false},
+		{name: "version not set", filter: issueFilter{StatusIDs: []int{1}, VersionIDs: []int{31}}, issue: issue, want: false},
+		{name: "priority", filter: issueFilter{StatusIDs: []int{1}, PriorityIDs: []int{2, 3}}, issue: issue, want: true},
+		{name: "due in range", filter: issueFilter{StatusIDs: []int{1}, DueSince: "2024-11-20", DueUntil: "2024-11-20"}, issue: issue, want: true},
+		{name: "created before since", filter: issueFilter{StatusIDs: []int{1}, CreatedSince: "2024-11-11"}, issue: issue, want: false},
+		{name: "not child", filter: issueFilter{StatusIDs: []int{1}, ParentChild: backlog.ParentChildNotChild}, issue: &child, want: false},
+		{name: "child", filter: issueFilter{StatusIDs: []int{1}, ParentChild: backlog.ParentChildChild}, issue: &child, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.match(tt.issue); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssueFilter_Equal(t *testing.T) {
+	a := &issueFilter{StatusIDs: []int{1, 2}, CategoryIDs: []int{21, 20}}
+	b := &issueFilter{StatusIDs: []int{2, 1}, CategoryIDs: []int{20, 21}, PriorityIDs: []int{}}
+	if !a.equal(b) {
+		t.Error("filters with the same IDs in a different order should be equal")
+	}
+
+	b.Keyword = "ログイン"
+	if a.equal(b) {
+		t.Error("filters with different keywords should not be equal")
+	}
+}
@@ -23,11 +23,71 @@ func NewFormatter(format config.OutputFormat) Formatter {
 		return &JSONFormatter{}
 	case config.FormatMarkdown:
 		return &MarkdownFormatter{}
+	case config.FormatCSV:
+		return &CSVFormatter{Delimiter: ','}
+	case config.FormatTSV:
+		return &CSVFormatter{Delimiter: '\t'}
+	case config.FormatXLSX:
+		return &XLSXFormatter{}
+	case config.FormatHTML:
+		return &HTMLFormatter{}
+	case config.FormatNDJSON:
+		return &NDJSONFormatter{}
+	case config.FormatSQLite:
+		return &SQLiteFormatter{}
 	default:
 		return &TXTFormatter{}
 	}
 }
 
+// NewFormatterFromConfig は設定のフォーマットとオプションを反映したフォーマッターを作成する
+func NewFormatterFromConfig(cfg *config.Config) Formatter {
+	if cfg.Format == config.FormatTemplate {
+		return &TemplateFormatter{Path: cfg.Template}
+	}
+	f := NewFormatter(cfg.Format)
+	if cf, ok := f.(*CSVFormatter); ok {
+		cf.BOM = cfg.BOM
+		cf.Columns = cfg.Columns
+	}
+	return f
+}
+
+// contextOnlyMark は取得条件外の親課題（--with-parents）の件名の後に付ける表示
+const contextOnlyMark = "（取得条件外）"
+
+// issueTitle はTXT・Markdownの課題の見出し（[課題キー] 件名）を返す
+func issueTitle(hi *backlog.HierarchicalIssue) string {
+	title := fmt.Sprintf("[%s] %s", hi.Issue.IssueKey, hi.Issue.Summary)
+	if hi.ContextOnly {
+		title += contextOnlyMark
+	}
+	return title
+}
+
+// issueAttribute はTXT・Markdownで値がある場合のみ出力する課題の項目
+type issueAttribute struct {
+	Label string
+	Value string
+}
+
+// issueAttributes は課題のカテゴリー・マイルストーン・発生バージョン・完了理由のうち設定されているものを返す
+func issueAttributes(issue *backlog.Issue) []issueAttribute {
+	var attrs []issueAttribute
+	add := func(label string, values []string) {
+		if len(values) > 0 {
+			attrs = append(attrs, issueAttribute{label, strings.Join(values, ", ")})
+		}
+	}
+	add("カテゴリー", categoryNames(issue))
+	add("マイルストーン", versionNames(issue.Milestone))
+	add("発生バージョン", versionNames(issue.Versions))
+	if issue.Resolution != nil {
+		add("完了理由", []string{issue.Resolution.Name})
+	}
+	return attrs
+}
+
 // ============================================
 // TXT Formatter
 // ============================================
@@ -50,63 +110,156 @@ func (f *TXTFormatter) Format(data *backlog.ExportData) ([]byte, error) {
 		data.Summary.Total, data.Summary.ParentIssues, data.Summary.ChildIssues))
 	sb.WriteString("================================================================================\n\n")
 
-	// 課題一覧
-	for i, issue := range data.Issues {
-		f.formatIssue(&sb, issue, false)
+	// 課題一覧（分類する場合はグループごと）
+	if data.Groups == nil {
+		f.formatIssues(&sb, data.Issues)
+	}
+	f.formatGroups(&sb, data.Groups, 0)
 
-		if i < len(data.Issues)-1 {
+	return []byte(sb.String()), nil
+}
+
+// txtGroupMarks は入れ子のグループの深さごとの見出しの記号
+var txtGroupMarks = []string{"■", "●", "◆", "▲"}
+
+// formatGroups はグループごとに見出しと課題一覧を出力する（下位のグループは深さを1つ増やす）
+func (f *TXTFormatter) formatGroups(sb *strings.Builder, groups []*backlog.IssueGroup, depth int) {
+	mark := txtGroupMarks[min(depth, len(txtGroupMarks)-1)]
+	for i, g := range groups {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(fmt.Sprintf("%s%s %s（%d件）\n\n", strings.Repeat("  ", depth), mark, g.Name, g.Summary.Total))
+		f.formatIssues(sb, g.Issues)
+		f.formatGroups(sb, g.Groups, depth+1)
+	}
+}
+
+func (f *TXTFormatter) formatIssues(sb *strings.Builder, issues []*backlog.HierarchicalIssue) {
+	for i, issue := range issues {
+		f.formatIssue(sb, issue)
+
+		if i < len(issues)-1 {
 			sb.WriteString("\n--------------------------------------------------------------------------------\n\n")
 		}
 	}
+}
+
+// FormatMulti は複数プロジェクトを1つのテキストにまとめる
+func (f *TXTFormatter) FormatMulti(data *backlog.MultiExportData) ([]byte, error) {
+	var sb strings.Builder
+
+	// ヘッダー
+	keys := make([]string, 0, len(data.Projects))
+	for _, p := range data.Projects {
+		keys = append(keys, p.Project.ProjectKey)
+	}
+	sb.WriteString("================================================================================\n")
+	sb.WriteString(fmt.Sprintf("プロジェクト: %d件（%s）\n", len(data.Projects), strings.Join(keys, ", ")))
+	sb.WriteString(fmt.Sprintf("取得日時: %s\n", data.ExportedAt.Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("未完了タスク数: %d件（親課題: %d件、子課題: %d件）\n",
+		data.Summary.Total, data.Summary.ParentIssues, data.Summary.ChildIssues))
+	sb.WriteString("================================================================================\n")
+
+	// プロジェクトごとの節
+	for _, p := range data.Projects {
+		content, err := f.Format(p)
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString("\n\n")
+		sb.Write(content)
+	}
 
 	return []byte(sb.String()), nil
 }
 
-func (f *TXTFormatter) formatIssue(sb *strings.Builder, hi *backlog.HierarchicalIssue, isChild bool) {
+func (f *TXTFormatter) formatIssue(sb *strings.Builder, hi *backlog.HierarchicalIssue) {
 	issue := hi.Issue
-	prefix := ""
-	if isChild {
-		prefix = "     "
+
+	sb.WriteString(issueTitle(hi) + "\n")
+	sb.WriteString(fmt.Sprintf("  状態: %s\n", f.getStatusName(issue)))
+	sb.WriteString(fmt.Sprintf("  優先度: %s\n", f.getPriorityName(issue)))
+	sb.WriteString(fmt.Sprintf("  担当者: %s\n", f.getAssigneeName(issue)))
+	sb.WriteString(fmt.Sprintf("  期限日: %s\n", f.getDueDate(issue)))
+	sb.WriteString(fmt.Sprintf("  作成日: %s\n", issue.Created.Format("2006-01-02")))
+	sb.WriteString(fmt.Sprintf("  更新日: %s\n", issue.Updated.Format("2006-01-02")))
+	f.formatAttributes(sb, issue, "  ")
+	f.formatCustomFields(sb, issue, "  ")
+	f.formatComments(sb, hi.Comments, "  ")
+
+	f.formatChildren(sb, hi.Children, "  ")
+}
+
+// formatChildren は子課題を罫線でつないで出力する（孫課題以降も同じ形で字下げして続ける）
+// prefix は親課題の項目の行頭
+func (f *TXTFormatter) formatChildren(sb *strings.Builder, children []*backlog.HierarchicalIssue, prefix string) {
+	if len(children) == 0 {
+		return
 	}
 
-	sb.WriteString(fmt.Sprintf("%s[%s] %s\n", prefix, issue.IssueKey, issue.Summary))
-	sb.WriteString(fmt.Sprintf("%s  状態: %s\n", prefix, f.getStatusName(issue)))
-	sb.WriteString(fmt.Sprintf("%s  優先度: %s\n", prefix, f.getPriorityName(issue)))
-	sb.WriteString(fmt.Sprintf("%s  担当者: %s\n", prefix, f.getAssigneeName(issue)))
-	sb.WriteString(fmt.Sprintf("%s  期限日: %s\n", prefix, f.getDueDate(issue)))
-	if !isChild {
-		sb.WriteString(fmt.Sprintf("%s  作成日: %s\n", prefix, issue.Created.Format("2006-01-02")))
-		sb.WriteString(fmt.Sprintf("%s  更新日: %s\n", prefix, issue.Updated.Format("2006-01-02")))
+	sb.WriteString(strings.TrimRight(prefix, " ") + "\n")
+	for i, child := range children {
+		isLast := i == len(children)-1
+		branch, linePrefix := "├─ ", prefix+"│    "
+		if isLast {
+			branch, linePrefix = "└─ ", prefix+"     "
+		}
+		sb.WriteString(fmt.Sprintf("%s%s%s\n", prefix, branch, issueTitle(child)))
+		sb.WriteString(fmt.Sprintf("%s状態: %s\n", linePrefix, f.getStatusName(child.Issue)))
+		sb.WriteString(fmt.Sprintf("%s優先度: %s\n", linePrefix, f.getPriorityName(child.Issue)))
+		sb.WriteString(fmt.Sprintf("%s担当者: %s\n", linePrefix, f.getAssigneeName(child.Issue)))
+		sb.WriteString(fmt.Sprintf("%s期限日: %s\n", linePrefix, f.getDueDate(child.Issue)))
+		f.formatAttributes(sb, child.Issue, linePrefix)
+		f.formatCustomFields(sb, child.Issue, linePrefix)
+		f.formatComments(sb, child.Comments, linePrefix)
+		f.formatChildren(sb, child.Children, linePrefix)
+
+		if !isLast {
+			sb.WriteString(prefix + "│\n")
+		}
 	}
+}
 
-	// 子課題
-	if len(hi.Children) > 0 {
-		sb.WriteString("\n")
-		for i, child := range hi.Children {
-			isLast := i == len(hi.Children)-1
-			if isLast {
-				sb.WriteString("  └─ ")
-			} else {
-				sb.WriteString("  ├─ ")
-			}
-			sb.WriteString(fmt.Sprintf("[%s] %s\n", child.Issue.IssueKey, child.Issue.Summary))
-
-			linePrefix := "  │    "
-			if isLast {
-				linePrefix = "       "
-			}
-			sb.WriteString(fmt.Sprintf("%s状態: %s\n", linePrefix, f.getStatusName(child.Issue)))
-			sb.WriteString(fmt.Sprintf("%s優先度: %s\n", linePrefix, f.getPriorityName(child.Issue)))
-			sb.WriteString(fmt.Sprintf("%s担当者: %s\n", linePrefix, f.getAssigneeName(child.Issue)))
-			sb.WriteString(fmt.Sprintf("%s期限日: %s\n", linePrefix, f.getDueDate(child.Issue)))
-
-			if !isLast {
-				sb.WriteString("  │\n")
-			}
+// formatAttributes は設定されているカテゴリー・マイルストーン・発生バージョン・完了理由を出力する
+func (f *TXTFormatter) formatAttributes(sb *strings.Builder, issue *backlog.Issue, prefix string) {
+	for _, attr := range issueAttributes(issue) {
+		sb.WriteString(fmt.Sprintf("%s%s: %s\n", prefix, attr.Label, attr.Value))
+	}
+}
+
+// formatCustomFields は値が設定されているカスタム属性を出力する（複数行の値は字下げして続ける）
+func (f *TXTFormatter) formatCustomFields(sb *strings.Builder, issue *backlog.Issue, prefix string) {
+	for _, field := range nonEmptyCustomFields(issue) {
+		lines := strings.Split(strings.TrimRight(field.String(), "\n"), "\n")
+		sb.WriteString(fmt.Sprintf("%s%s: %s\n", prefix, field.Name, lines[0]))
+		for _, line := range lines[1:] {
+			sb.WriteString(fmt.Sprintf("%s  %s\n", prefix, line))
 		}
 	}
 }
 
+func (f *TXTFormatter) formatComments(sb *strings.Builder, comments []*backlog.Comment, prefix string) {
+	if len(comments) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("%sコメント:\n", prefix))
+	for _, c := range comments {
+		sb.WriteString(fmt.Sprintf("%s  - %s (%s)\n", prefix, f.getCommentAuthor(c), c.Created.Format("2006-01-02 15:04")))
+		for _, line := range strings.Split(strings.TrimRight(c.Content, "\n"), "\n") {
+			sb.WriteString(fmt.Sprintf("%s    %s\n", prefix, line))
+		}
+	}
+}
+
+func (f *TXTFormatter) getCommentAuthor(c *backlog.Comment) string {
+	if c.CreatedUser != nil {
+		return c.CreatedUser.Name
+	}
+	return "-"
+}
+
 func (f *TXTFormatter) getStatusName(issue *backlog.Issue) string {
 	if issue.Status != nil {
 		return issue.Status.Name
@@ -148,27 +301,77 @@ func (f *MarkdownFormatter) Extension() string {
 
 func (f *MarkdownFormatter) Format(data *backlog.ExportData) ([]byte, error) {
 	var sb strings.Builder
+	f.formatProject(&sb, data, 1)
+	return []byte(sb.String()), nil
+}
+
+// FormatMulti は複数プロジェクトを1つのMarkdownにまとめる（各プロジェクトは見出しレベル2の節）
+func (f *MarkdownFormatter) FormatMulti(data *backlog.MultiExportData) ([]byte, error) {
+	var sb strings.Builder
+
+	// ヘッダー
+	sb.WriteString(fmt.Sprintf("# 未完了タスク一覧（%dプロジェクト）\n\n", len(data.Projects)))
+	sb.WriteString(fmt.Sprintf("> 取得日時: %s  \n", data.ExportedAt.Format("2006-01-02 15:04:05")))
+	sb.WriteString(fmt.Sprintf("> 未完了タスク数: %d件（親課題: %d件、子課題: %d件）\n\n",
+		data.Summary.Total, data.Summary.ParentIssues, data.Summary.ChildIssues))
+
+	// プロジェクト別サマリー
+	sb.WriteString("| プロジェクト | 未完了タスク数 | 親課題 | 子課題 |\n")
+	sb.WriteString("|------|------|------|------|\n")
+	for _, p := range data.Projects {
+		sb.WriteString(fmt.Sprintf("| %s - %s | %d | %d | %d |\n",
+			p.Project.ProjectKey, p.Project.Name, p.Summary.Total, p.Summary.ParentIssues, p.Summary.ChildIssues))
+	}
+	sb.WriteString("\n---\n\n")
 
+	for _, p := range data.Projects {
+		f.formatProject(&sb, p, 2)
+	}
+
+	return []byte(sb.String()), nil
+}
+
+// formatProject はプロジェクトのヘッダーと課題一覧を level の見出しから出力する
+func (f *MarkdownFormatter) formatProject(sb *strings.Builder, data *backlog.ExportData, level int) {
 	// ヘッダー
-	sb.WriteString(fmt.Sprintf("# %s - %s 未完了タスク一覧\n\n", data.Project.ProjectKey, data.Project.Name))
+	sb.WriteString(fmt.Sprintf("%s %s - %s 未完了タスク一覧\n\n", f.heading(level), data.Project.ProjectKey, data.Project.Name))
 	sb.WriteString(fmt.Sprintf("> 取得日時: %s  \n", data.ExportedAt.Format("2006-01-02 15:04:05")))
 	sb.WriteString(fmt.Sprintf("> 未完了タスク数: %d件（親課題: %d件、子課題: %d件）\n\n",
 		data.Summary.Total, data.Summary.ParentIssues, data.Summary.ChildIssues))
 	sb.WriteString("---\n\n")
 
-	// 課題一覧
-	for _, issue := range data.Issues {
-		f.formatIssue(&sb, issue)
+	// 課題一覧（分類する場合はグループごとの見出しの下）
+	if data.Groups == nil {
+		f.formatIssues(sb, data.Issues, level+1)
+	}
+	f.formatGroups(sb, data.Groups, level+1)
+}
+
+// formatGroups はグループごとに level の見出しと課題一覧を出力する（下位のグループは1つ下の見出し）
+func (f *MarkdownFormatter) formatGroups(sb *strings.Builder, groups []*backlog.IssueGroup, level int) {
+	for _, g := range groups {
+		sb.WriteString(fmt.Sprintf("%s %s（%d件）\n\n", f.heading(level), g.Name, g.Summary.Total))
+		f.formatIssues(sb, g.Issues, level+1)
+		f.formatGroups(sb, g.Groups, level+1)
+	}
+}
+
+func (f *MarkdownFormatter) formatIssues(sb *strings.Builder, issues []*backlog.HierarchicalIssue, level int) {
+	for _, issue := range issues {
+		f.formatIssue(sb, issue, level)
 		sb.WriteString("\n---\n\n")
 	}
+}
 
-	return []byte(sb.String()), nil
+// heading は level の見出しの記号を返す（Markdownの見出しは6段までのため、それより深い場合は6段にする）
+func (f *MarkdownFormatter) heading(level int) string {
+	return strings.Repeat("#", min(max(level, 1), 6))
 }
 
-func (f *MarkdownFormatter) formatIssue(sb *strings.Builder, hi *backlog.HierarchicalIssue) {
+func (f *MarkdownFormatter) formatIssue(sb *strings.Builder, hi *backlog.HierarchicalIssue, level int) {
 	issue := hi.Issue
 
-	sb.WriteString(fmt.Sprintf("## [%s] %s\n", issue.IssueKey, issue.Summary))
+	sb.WriteString(fmt.Sprintf("%s %s\n", f.heading(level), issueTitle(hi)))
 	sb.WriteString("| 項目 | 内容 |\n")
 	sb.WriteString("|------|------|\n")
 	sb.WriteString(fmt.Sprintf("| 状態 | %s |\n", f.getStatusName(issue)))
@@ -177,23 +380,103 @@ func (f *MarkdownFormatter) formatIssue(sb *strings.Builder, hi *backlog.Hierarc
 	sb.WriteString(fmt.Sprintf("| 期限日 | %s |\n", f.getDueDate(issue)))
 	sb.WriteString(fmt.Sprintf("| 作成日 | %s |\n", issue.Created.Format("2006-01-02")))
 	sb.WriteString(fmt.Sprintf("| 更新日 | %s |\n", issue.Updated.Format("2006-01-02")))
+	f.formatAttributes(sb, issue)
+	f.formatCustomFields(sb, issue)
+	f.formatAttachments(sb, hi.Attachments)
+	f.formatComments(sb, hi.Comments)
 
-	// 子課題
-	if len(hi.Children) > 0 {
-		sb.WriteString("\n### 子課題\n\n")
-		for _, child := range hi.Children {
-			sb.WriteString(fmt.Sprintf("#### [%s] %s\n", child.Issue.IssueKey, child.Issue.Summary))
-			sb.WriteString("| 項目 | 内容 |\n")
-			sb.WriteString("|------|------|\n")
-			sb.WriteString(fmt.Sprintf("| 状態 | %s |\n", f.getStatusName(child.Issue)))
-			sb.WriteString(fmt.Sprintf("| 優先度 | %s |\n", f.getPriorityName(child.Issue)))
-			sb.WriteString(fmt.Sprintf("| 担当者 | %s |\n", f.getAssigneeName(child.Issue)))
-			sb.WriteString(fmt.Sprintf("| 期限日 | %s |\n", f.getDueDate(child.Issue)))
-			sb.WriteString("\n")
+	f.formatChildren(sb, hi.Children, level)
+}
+
+// formatChildren は子課題を「子課題」の見出しの下に出力する（孫課題以降も同じ形で見出しを2段ずつ下げる）
+// level は親課題の見出しのレベル
+func (f *MarkdownFormatter) formatChildren(sb *strings.Builder, children []*backlog.HierarchicalIssue, level int) {
+	if len(children) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("\n%s 子課題\n\n", f.heading(level+1)))
+	for _, child := range children {
+		sb.WriteString(fmt.Sprintf("%s %s\n", f.heading(level+2), issueTitle(child)))
+		sb.WriteString("| 項目 | 内容 |\n")
+		sb.WriteString("|------|------|\n")
+		sb.WriteString(fmt.Sprintf("| 状態 | %s |\n", f.getStatusName(child.Issue)))
+		sb.WriteString(fmt.Sprintf("| 優先度 | %s |\n", f.getPriorityName(child.Issue)))
+		sb.WriteString(fmt.Sprintf("| 担当者 | %s |\n", f.getAssigneeName(child.Issue)))
+		sb.WriteString(fmt.Sprintf("| 期限日 | %s |\n", f.getDueDate(child.Issue)))
+		f.formatAttributes(sb, child.Issue)
+		f.formatCustomFields(sb, child.Issue)
+		f.formatAttachments(sb, child.Attachments)
+		f.formatComments(sb, child.Comments)
+		f.formatChildren(sb, child.Children, level+2)
+		sb.WriteString("\n")
+	}
+}
+
+// formatAttributes は設定されているカテゴリー・マイルストーン・発生バージョン・完了理由を表の行として出力する
+func (f *MarkdownFormatter) formatAttributes(sb *strings.Builder, issue *backlog.Issue) {
+	for _, attr := range issueAttributes(issue) {
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", attr.Label, f.escapeCell(attr.Value)))
+	}
+}
+
+// formatCustomFields は値が設定されているカスタム属性を表の行として出力する
+func (f *MarkdownFormatter) formatCustomFields(sb *strings.Builder, issue *backlog.Issue) {
+	for _, field := range nonEmptyCustomFields(issue) {
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", f.escapeCell(field.Name), f.escapeCell(field.String())))
+	}
+}
+
+// escapeCell は表のセルに書けるように "|" と改行をエスケープする
+func (f *MarkdownFormatter) escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "<br>")
+}
+
+func (f *MarkdownFormatter) formatComments(sb *strings.Builder, comments []*backlog.Comment) {
+	if len(comments) == 0 {
+		return
+	}
+
+	sb.WriteString(fmt.Sprintf("\n**コメント（%d件）**\n\n", len(comments)))
+	for _, c := range comments {
+		sb.WriteString(fmt.Sprintf("> **%s** (%s)  \n", f.getCommentAuthor(c), c.Created.Format("2006-01-02 15:04")))
+		for _, line := range strings.Split(strings.TrimRight(c.Content, "\n"), "\n") {
+			sb.WriteString(fmt.Sprintf("> %s  \n", line))
 		}
+		sb.WriteString("\n")
+	}
+}
+
+func (f *MarkdownFormatter) formatAttachments(sb *strings.Builder, attachments []*backlog.ExportedAttachment) {
+	if len(attachments) == 0 {
+		return
+	}
+
+	sb.WriteString("\n**添付ファイル**\n\n")
+	for _, a := range attachments {
+		sb.WriteString(fmt.Sprintf("- [%s](<%s>) (%s)\n", a.Attachment.Name, a.Path, f.formatSize(a.Attachment.Size)))
+	}
+}
+
+func (f *MarkdownFormatter) formatSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
 	}
 }
 
+func (f *MarkdownFormatter) getCommentAuthor(c *backlog.Comment) string {
+	if c.CreatedUser != nil {
+		return c.CreatedUser.Name
+	}
+	return "-"
+}
+
 func (f *MarkdownFormatter) getStatusName(issue *backlog.Issue) string {
 	if issue.Status != nil {
 		return issue.Status.Name
@@ -234,11 +517,30 @@ func (f *JSONFormatter) Extension() string {
 }
 
 // jsonExportData はJSON出力用のデータ構造
+// 分類する場合は issues の代わりに groups に課題を出力する
 type jsonExportData struct {
-	Project    jsonProject    `json:"project"`
-	ExportedAt string         `json:"exportedAt"`
-	Summary    jsonSummary    `json:"summary"`
-	Issues     []jsonIssue    `json:"issues"`
+	Project    jsonProject `json:"project"`
+	ExportedAt string      `json:"exportedAt"`
+	Summary    jsonSummary `json:"summary"`
+	GroupBy    []string    `json:"groupBy,omitempty"`
+	Issues     []jsonIssue `json:"issues,omitzero"`
+	Groups     []jsonGroup `json:"groups,omitzero"`
+}
+
+// jsonGroup は分類した課題のグループ
+// 入れ子に分類する場合は issues の代わりに groups に下位のグループを出力する
+type jsonGroup struct {
+	Name    string      `json:"name"`
+	Summary jsonSummary `json:"summary"`
+	Issues  []jsonIssue `json:"issues,omitzero"`
+	Groups  []jsonGroup `json:"groups,omitzero"`
+}
+
+// jsonMultiExportData は複数プロジェクトをまとめたJSON出力用のデータ構造
+type jsonMultiExportData struct {
+	ExportedAt string           `json:"exportedAt"`
+	Summary    jsonSummary      `json:"summary"`
+	Projects   []jsonExportData `json:"projects"`
 }
 
 type jsonProject struct {
@@ -254,54 +556,169 @@ type jsonSummary struct {
 }
 
 type jsonIssue struct {
-	ID        int         `json:"id"`
-	IssueKey  string      `json:"issueKey"`
-	Summary   string      `json:"summary"`
-	Status    string      `json:"status"`
-	Priority  string      `json:"priority"`
-	Assignee  *string     `json:"assignee"`
-	DueDate   *string     `json:"dueDate"`
-	CreatedAt string      `json:"createdAt"`
-	UpdatedAt string      `json:"updatedAt"`
-	Children  []jsonIssue `json:"children"`
+	ID            int               `json:"id"`
+	IssueKey      string            `json:"issueKey"`
+	ParentIssueID *int              `json:"parentIssueId,omitempty"`
+	ContextOnly   bool              `json:"contextOnly,omitempty"`
+	Summary       string            `json:"summary"`
+	Status        string            `json:"status"`
+	Priority      string            `json:"priority"`
+	Resolution    *string           `json:"resolution"`
+	Category      []string          `json:"category"`
+	Milestone     []string          `json:"milestone"`
+	Versions      []string          `json:"versions"`
+	Assignee      *string           `json:"assignee"`
+	DueDate       *string           `json:"dueDate"`
+	CreatedAt     string            `json:"createdAt"`
+	UpdatedAt     string            `json:"updatedAt"`
+	CustomFields  []jsonCustomField `json:"customFields,omitempty"`
+	Comments      []jsonComment     `json:"comments,omitempty"`
+	Attachments   []jsonAttachment  `json:"attachments,omitempty"`
+	Children      []jsonIssue       `json:"children"`
+}
+
+// jsonCustomField はカスタム属性の値
+// value は種類に応じて文字列・数値・日付（YYYY-MM-DD）、複数選択の場合は項目名の配列（未設定の場合は null）
+type jsonCustomField struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	Value      any     `json:"value"`
+	OtherValue *string `json:"otherValue,omitempty"`
+}
+
+type jsonAttachment struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Path string `json:"path"`
+}
+
+type jsonComment struct {
+	ID        int     `json:"id"`
+	Author    *string `json:"author"`
+	Content   string  `json:"content"`
+	CreatedAt string  `json:"createdAt"`
+	UpdatedAt string  `json:"updatedAt"`
 }
 
 func (f *JSONFormatter) Format(data *backlog.ExportData) ([]byte, error) {
-	output := jsonExportData{
-		Project: jsonProject{
-			ID:   data.Project.ID,
-			Key:  data.Project.ProjectKey,
-			Name: data.Project.Name,
-		},
+	return json.MarshalIndent(f.convertExportData(data), "", "  ")
+}
+
+// FormatMulti は複数プロジェクトを1つのJSONにまとめる
+func (f *JSONFormatter) FormatMulti(data *backlog.MultiExportData) ([]byte, error) {
+	output := jsonMultiExportData{
 		ExportedAt: data.ExportedAt.Format(time.RFC3339),
 		Summary: jsonSummary{
 			Total:        data.Summary.Total,
 			ParentIssues: data.Summary.ParentIssues,
 			ChildIssues:  data.Summary.ChildIssues,
 		},
-		Issues: make([]jsonIssue, 0, len(data.Issues)),
+		Projects: make([]jsonExportData, 0, len(data.Projects)),
 	}
 
-	for _, hi := range data.Issues {
-		output.Issues = append(output.Issues, f.convertIssue(hi))
+	for _, p := range data.Projects {
+		output.Projects = append(output.Projects, f.convertExportData(p))
 	}
 
 	return json.MarshalIndent(output, "", "  ")
 }
 
+func (f *JSONFormatter) convertExportData(data *backlog.ExportData) jsonExportData {
+	output := jsonExportData{
+		Project: jsonProject{
+			ID:   data.Project.ID,
+			Key:  data.Project.ProjectKey,
+			Name: data.Project.Name,
+		},
+		ExportedAt: data.ExportedAt.Format(time.RFC3339),
+		Summary:    f.convertSummary(data.Summary),
+		GroupBy:    data.GroupBy,
+	}
+
+	if data.Groups == nil {
+		output.Issues = f.convertIssues(data.Issues)
+	}
+	output.Groups = f.convertGroups(data.Groups)
+
+	return output
+}
+
+func (f *JSONFormatter) convertGroups(groups []*backlog.IssueGroup) []jsonGroup {
+	if groups == nil {
+		return nil
+	}
+	result := make([]jsonGroup, 0, len(groups))
+	for _, g := range groups {
+		group := jsonGroup{
+			Name:    g.Name,
+			Summary: f.convertSummary(g.Summary),
+			Groups:  f.convertGroups(g.Groups),
+		}
+		if g.Groups == nil {
+			group.Issues = f.convertIssues(g.Issues)
+		}
+		result = append(result, group)
+	}
+	return result
+}
+
+func (f *JSONFormatter) convertSummary(summary backlog.ExportSummary) jsonSummary {
+	return jsonSummary{
+		Total:        summary.Total,
+		ParentIssues: summary.ParentIssues,
+		ChildIssues:  summary.ChildIssues,
+	}
+}
+
+func (f *JSONFormatter) convertIssues(issues []*backlog.HierarchicalIssue) []jsonIssue {
+	result := make([]jsonIssue, 0, len(issues))
+	for _, hi := range issues {
+		result = append(result, f.convertIssue(hi))
+	}
+	return result
+}
+
 func (f *JSONFormatter) convertIssue(hi *backlog.HierarchicalIssue) jsonIssue {
 	issue := hi.Issue
 	ji := jsonIssue{
-		ID:        issue.ID,
-		IssueKey:  issue.IssueKey,
-		Summary:   issue.Summary,
-		Status:    f.getStatusName(issue),
-		Priority:  f.getPriorityName(issue),
-		Assignee:  f.getAssigneeName(issue),
-		DueDate:   issue.DueDate,
-		CreatedAt: issue.Created.Format(time.RFC3339),
-		UpdatedAt: issue.Updated.Format(time.RFC3339),
-		Children:  make([]jsonIssue, 0, len(hi.Children)),
+		ID:            issue.ID,
+		IssueKey:      issue.IssueKey,
+		ParentIssueID: issue.ParentIssueID,
+		ContextOnly:   hi.ContextOnly,
+		Summary:       issue.Summary,
+		Status:        f.getStatusName(issue),
+		Priority:      f.getPriorityName(issue),
+		Category:      categoryNames(issue),
+		Milestone:     versionNames(issue.Milestone),
+		Versions:      versionNames(issue.Versions),
+		Assignee:      f.getAssigneeName(issue),
+		DueDate:       issue.DueDate,
+		CreatedAt:     issue.Created.Format(time.RFC3339),
+		UpdatedAt:     issue.Updated.Format(time.RFC3339),
+		Children:      make([]jsonIssue, 0, len(hi.Children)),
+	}
+
+	if issue.Resolution != nil {
+		ji.Resolution = &issue.Resolution.Name
+	}
+
+	for _, field := range issue.CustomFields {
+		ji.CustomFields = append(ji.CustomFields, f.convertCustomField(field))
+	}
+
+	for _, c := range hi.Comments {
+		ji.Comments = append(ji.Comments, f.convertComment(c))
+	}
+
+	for _, a := range hi.Attachments {
+		ji.Attachments = append(ji.Attachments, jsonAttachment{
+			ID:   a.Attachment.ID,
+			Name: a.Attachment.Name,
+			Size: a.Attachment.Size,
+			Path: a.Path,
+		})
 	}
 
 	for _, child := range hi.Children {
@@ -311,6 +728,46 @@ func (f *JSONFormatter) convertIssue(hi *backlog.HierarchicalIssue) jsonIssue {
 	return ji
 }
 
+func (f *JSONFormatter) convertCustomField(field *backlog.CustomField) jsonCustomField {
+	jf := jsonCustomField{
+		ID:         field.ID,
+		Name:       field.Name,
+		Type:       field.FieldTypeID.String(),
+		OtherValue: field.OtherValue,
+	}
+	switch {
+	case field.Text != nil:
+		jf.Value = *field.Text
+	case field.Number != nil:
+		jf.Value = *field.Number
+	case field.Date != nil:
+		jf.Value = *field.Date
+	case field.FieldTypeID == backlog.CustomFieldMultipleList || field.FieldTypeID == backlog.CustomFieldCheckbox:
+		names := make([]string, 0, len(field.Items))
+		for _, item := range field.Items {
+			names = append(names, item.Name)
+		}
+		jf.Value = names
+	case len(field.Items) > 0:
+		jf.Value = field.Items[0].Name
+	}
+	return jf
+}
+
+func (f *JSONFormatter) convertComment(c *backlog.Comment) jsonComment {
+	jc := jsonComment{
+		ID:        c.ID,
+		Content:   c.Content,
+		CreatedAt: c.Created.Format(time.RFC3339),
+		UpdatedAt: c.Updated.Format(time.RFC3339),
+	}
+	if c.CreatedUser != nil {
+		name := c.CreatedUser.Name
+		jc.Author = &name
+	}
+	return jc
+}
+
 func (f *JSONFormatter) getStatusName(issue *backlog.Issue) string {
 	if issue.Status != nil {
 		return issue.Status.Name
@@ -312,3 +312,185 @@ func TestFormatter_NilValues(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatter_Comments(t *testing.T) {
+	data := createTestExportData()
+	data.Issues[0].Comments = []*backlog.Comment{
+		{
+			ID:          1,
+			Content:     "設計レビューお願いします\n資料は共有フォルダにあります",
+			CreatedUser: &backlog.User{ID: 1, Name: "山田"},
+			Created:     time.Date(2024, 11, 20, 9, 15, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 11, 20, 9, 15, 0, 0, time.UTC),
+		},
+	}
+	data.Issues[0].Children[0].Comments = []*backlog.Comment{
+		{
+			ID:          2,
+			Content:     "対応しました",
+			CreatedUser: &backlog.User{ID: 2, Name: "鈴木"},
+			Created:     time.Date(2024, 11, 21, 18, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 11, 21, 18, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := []struct {
+		format   config.OutputFormat
+		contains []string
+	}{
+		{
+			format: config.FormatTXT,
+			contains: []string{
+				"  コメント:\n",
+				"  - 山田 (2024-11-20 09:15)\n",
+				"    資料は共有フォルダにあります\n",
+				"- 鈴木 (2024-11-21 18:00)",
+			},
+		},
+		{
+			format: config.FormatMarkdown,
+			contains: []string{
+				"**コメント（1件）**",
+				"> **山田** (2024-11-20 09:15)",
+				"> 資料は共有フォルダにあります",
+				"> **鈴木** (2024-11-21 18:00)",
+			},
+		},
+		{
+			format: config.FormatJSON,
+			contains: []string{
+				`"comments"`,
+				`"author": "山田"`,
+				`"content": "対応しました"`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.format), func(t *testing.T) {
+			output, err := NewFormatter(tc.format).Format(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			content := string(output)
+			for _, expected := range tc.contains {
+				if !strings.Contains(content, expected) {
+					t.Errorf("output should contain %q", expected)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONFormatter_Format_OmitsCommentsByDefault(t *testing.T) {
+	output, err := (&JSONFormatter{}).Format(createTestExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(string(output), `"comments"`) {
+		t.Error("comments should be omitted when not fetched")
+	}
+}
+
+func TestJSONFormatter_Attachments(t *testing.T) {
+	data := createTestExportData()
+	data.Issues[0].Attachments = []*backlog.ExportedAttachment{
+		{
+			Attachment: &backlog.Attachment{ID: 8, Name: "spec.pdf", Size: 2048},
+			Path:       "MYPROJ-100/attachments/spec.pdf",
+		},
+	}
+
+	output, err := (&JSONFormatter{}).Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result jsonExportData
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+
+	attachments := result.Issues[0].Attachments
+	if len(attachments) != 1 {
+		t.Fatalf("expected 1 attachment, got %d", len(attachments))
+	}
+	if attachments[0].Path != "MYPROJ-100/attachments/spec.pdf" {
+		t.Errorf("unexpected attachment path: %s", attachments[0].Path)
+	}
+	if result.Issues[1].Attachments != nil {
+		t.Error("issues without attachments should omit the field")
+	}
+}
+
+func createTestMultiExportData() *backlog.MultiExportData {
+	first := createTestExportData()
+	second := createTestExportData()
+	second.Project = &backlog.Project{ID: 2, ProjectKey: "OTHER", Name: "別プロジェクト"}
+
+	return &backlog.MultiExportData{
+		ExportedAt: first.ExportedAt,
+		Summary:    backlog.ExportSummary{Total: 6, ParentIssues: 4, ChildIssues: 2},
+		Projects:   []*backlog.ExportData{first, second},
+	}
+}
+
+func TestFormatter_FormatMulti(t *testing.T) {
+	data := createTestMultiExportData()
+
+	tests := []struct {
+		format   config.OutputFormat
+		contains []string
+	}{
+		{
+			format: config.FormatTXT,
+			contains: []string{
+				"プロジェクト: 2件（MYPROJ, OTHER）",
+				"未完了タスク数: 6件（親課題: 4件、子課題: 2件）",
+				"プロジェクト: OTHER - 別プロジェクト",
+			},
+		},
+		{
+			format: config.FormatMarkdown,
+			contains: []string{
+				"# 未完了タスク一覧（2プロジェクト）",
+				"| MYPROJ - マイプロジェクト | 3 | 2 | 1 |",
+				"\n## OTHER - 別プロジェクト 未完了タスク一覧",
+				"\n### [MYPROJ-100] 親課題",
+				"\n#### 子課題",
+				"\n##### [MYPROJ-101] 子課題",
+			},
+		},
+		{
+			format: config.FormatJSON,
+			contains: []string{
+				`"projects"`,
+				`"key": "OTHER"`,
+				`"total": 6`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.format), func(t *testing.T) {
+			mf, ok := NewFormatter(tc.format).(MultiFormatter)
+			if !ok {
+				t.Fatalf("%s formatter should support combined output", tc.format)
+			}
+
+			output, err := mf.FormatMulti(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			content := string(output)
+			for _, expected := range tc.contains {
+				if !strings.Contains(content, expected) {
+					t.Errorf("output should contain %q", expected)
+				}
+			}
+		})
+	}
+}
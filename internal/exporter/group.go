@@ -0,0 +1,165 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// noGroupName は分類の値が設定されていない課題のグループ名
+const noGroupName = "(未設定)"
+
+// groupValue は課題を分類する値（グループ名と並び順）
+type groupValue struct {
+	name  string
+	order int
+}
+
+// groupValueFuncs は --group-by の値ごとに課題の分類の値を返す関数
+// 値が複数ある課題はそれぞれのグループに含める
+var groupValueFuncs = map[string]func(issue *backlog.Issue) []groupValue{
+	config.GroupByMilestone: func(issue *backlog.Issue) []groupValue { return versionGroupValues(issue.Milestone) },
+	config.GroupByVersion:   func(issue *backlog.Issue) []groupValue { return versionGroupValues(issue.Versions) },
+	config.GroupByCategory: func(issue *backlog.Issue) []groupValue {
+		values := make([]groupValue, 0, len(issue.Category))
+		for _, c := range issue.Category {
+			values = append(values, groupValue{name: c.Name, order: c.DisplayOrder})
+		}
+		return values
+	},
+	// 担当者は名前の順
+	config.GroupByAssignee: func(issue *backlog.Issue) []groupValue {
+		if issue.Assignee == nil {
+			return nil
+		}
+		return []groupValue{{name: issue.Assignee.Name}}
+	},
+	config.GroupByStatus: func(issue *backlog.Issue) []groupValue {
+		if issue.Status == nil {
+			return nil
+		}
+		return []groupValue{{name: issue.Status.Name, order: issue.Status.DisplayOrder}}
+	},
+	// 優先度IDは高いほど小さいため、IDの順に並べる
+	config.GroupByPriority: func(issue *backlog.Issue) []groupValue {
+		if issue.Priority == nil {
+			return nil
+		}
+		return []groupValue{{name: issue.Priority.Name, order: issue.Priority.ID}}
+	},
+	config.GroupByIssueType: func(issue *backlog.Issue) []groupValue {
+		if issue.IssueType == nil {
+			return nil
+		}
+		return []groupValue{{name: issue.IssueType.Name, order: issue.IssueType.DisplayOrder}}
+	},
+	// 期限日の週（月曜日〜日曜日）は名前が日付なので名前の順
+	config.GroupByDueWeek: func(issue *backlog.Issue) []groupValue {
+		date := csvDate(issue.DueDate)
+		if date == "" {
+			return nil
+		}
+		due, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil
+		}
+		monday := due.AddDate(0, 0, -(int(due.Weekday())+6)%7)
+		name := fmt.Sprintf("%s 〜 %s", monday.Format("2006-01-02"), monday.AddDate(0, 0, 6).Format("2006-01-02"))
+		return []groupValue{{name: name}}
+	},
+}
+
+func versionGroupValues(versions []*backlog.Version) []groupValue {
+	values := make([]groupValue, 0, len(versions))
+	for _, v := range versions {
+		values = append(values, groupValue{name: v.Name, order: v.DisplayOrder})
+	}
+	return values
+}
+
+// groupIssues はルート課題を groupBy の値で分類する（子課題は親課題と同じグループに含める）
+// groupBy が複数の場合は、先頭の分類方法のグループを残りの分類方法で入れ子に分類する
+// グループはBacklogの表示順に並べ、値が設定されていない課題のグループは末尾にする
+func groupIssues(issues []*backlog.HierarchicalIssue, groupBy []string) []*backlog.IssueGroup {
+	if len(groupBy) == 0 {
+		return nil
+	}
+	values, ok := groupValueFuncs[groupBy[0]]
+	if !ok {
+		return nil
+	}
+
+	groups := make(map[string]*backlog.IssueGroup)
+	orders := make(map[string]int)
+	var names []string
+	var ungrouped *backlog.IssueGroup
+
+	for _, hi := range issues {
+		vs := values(hi.Issue)
+		if len(vs) == 0 {
+			if ungrouped == nil {
+				ungrouped = &backlog.IssueGroup{Name: noGroupName}
+			}
+			addToGroup(ungrouped, hi)
+			continue
+		}
+		for _, v := range vs {
+			g, ok := groups[v.name]
+			if !ok {
+				g = &backlog.IssueGroup{Name: v.name}
+				groups[v.name] = g
+				orders[v.name] = v.order
+				names = append(names, v.name)
+			}
+			addToGroup(g, hi)
+		}
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+		if orders[names[i]] != orders[names[j]] {
+			return orders[names[i]] < orders[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	result := make([]*backlog.IssueGroup, 0, len(names)+1)
+	for _, name := range names {
+		result = append(result, groups[name])
+	}
+	if ungrouped != nil {
+		result = append(result, ungrouped)
+	}
+
+	if len(groupBy) > 1 {
+		for _, g := range result {
+			g.Groups = groupIssues(g.Issues, groupBy[1:])
+			g.Issues = nil
+		}
+	}
+	return result
+}
+
+// addToGroup はルート課題をグループに加えて件数を更新する（取得条件外の親課題は数えない）
+func addToGroup(g *backlog.IssueGroup, hi *backlog.HierarchicalIssue) {
+	g.Issues = append(g.Issues, hi)
+	if !hi.ContextOnly {
+		g.Summary.ParentIssues++
+	}
+	g.Summary.ChildIssues += countDescendants(hi)
+	g.Summary.Total = g.Summary.ParentIssues + g.Summary.ChildIssues
+}
+
+// countDescendants は課題の子孫の数を返す（取得条件外の親課題は数えない）
+func countDescendants(hi *backlog.HierarchicalIssue) int {
+	n := 0
+	for _, child := range hi.Children {
+		if !child.ContextOnly {
+			n++
+		}
+		n += countDescendants(child)
+	}
+	return n
+}
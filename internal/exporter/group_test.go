@@ -0,0 +1,231 @@
+package exporter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// createTestGroupedExportData は親課題を v1.0、単独タスクを v1.0 と v2.0 のマイルストーンに設定した出力データを作成する
+func createTestGroupedExportData() *backlog.ExportData {
+	data := createTestExportData()
+	v1 := &backlog.Version{ID: 1, Name: "v1.0", DisplayOrder: 0}
+	v2 := &backlog.Version{ID: 2, Name: "v2.0", DisplayOrder: 1}
+
+	parent := data.Issues[0].Issue
+	parent.Milestone = []*backlog.Version{v1}
+	parent.Category = []*backlog.Category{{ID: 10, Name: "画面"}}
+	parent.Resolution = &backlog.Resolution{ID: 0, Name: "対応済み"}
+	data.Issues[1].Issue.Milestone = []*backlog.Version{v2, v1}
+
+	data.GroupBy = []string{config.GroupByMilestone}
+	data.Groups = groupIssues(data.Issues, data.GroupBy)
+	return data
+}
+
+func TestGroupIssues(t *testing.T) {
+	data := createTestExportData()
+	data.Issues[0].Issue.Category = []*backlog.Category{{ID: 2, Name: "API", DisplayOrder: 1}}
+	data.Issues[0].Children[0].Issue.Category = []*backlog.Category{{ID: 1, Name: "画面", DisplayOrder: 0}}
+	data.Issues[1].Issue.Category = nil
+
+	groups := groupIssues(data.Issues, []string{config.GroupByCategory})
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(groups))
+	}
+
+	// 子課題は親課題のグループに含める
+	api := groups[0]
+	if api.Name != "API" || len(api.Issues) != 1 || len(api.Issues[0].Children) != 1 {
+		t.Errorf("unexpected first group: %+v", api)
+	}
+	if api.Summary != (backlog.ExportSummary{Total: 2, ParentIssues: 1, ChildIssues: 1}) {
+		t.Errorf("unexpected summary: %+v", api.Summary)
+	}
+
+	// 値が設定されていない課題は末尾のグループ
+	if groups[1].Name != noGroupName || groups[1].Issues[0].Issue.IssueKey != "MYPROJ-200" {
+		t.Errorf("unexpected last group: %+v", groups[1])
+	}
+
+	if groupIssues(data.Issues, nil) != nil {
+		t.Error("expected no groups without group-by")
+	}
+}
+
+func TestGroupIssues_MultipleValues(t *testing.T) {
+	data := createTestGroupedExportData()
+
+	var names []string
+	for _, g := range data.Groups {
+		names = append(names, g.Name)
+	}
+	if strings.Join(names, ",") != "v1.0,v2.0" {
+		t.Fatalf("unexpected groups: %v", names)
+	}
+	// 複数のマイルストーンがある課題はそれぞれのグループに含める
+	if len(data.Groups[0].Issues) != 2 || data.Groups[1].Issues[0].Issue.IssueKey != "MYPROJ-200" {
+		t.Error("issue with several milestones should appear in each group")
+	}
+}
+
+func TestFormatter_Groups(t *testing.T) {
+	data := createTestGroupedExportData()
+
+	tests := []struct {
+		format   config.OutputFormat
+		contains []string
+	}{
+		{config.FormatTXT, []string{"■ v1.0（3件）\n", "■ v2.0（1件）\n", "  マイルストーン: v1.0\n", "  完了理由: 対応済み\n"}},
+		{config.FormatMarkdown, []string{"## v1.0（3件）\n", "### [MYPROJ-100] 親課題\n", "| カテゴリー | 画面 |\n"}},
+		{config.FormatHTML, []string{`<h3 class="group-name depth-0">v1.0（3件）</h3>`, `<h3 class="group-name depth-0">v2.0（1件）</h3>`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			output, err := NewFormatter(tt.format).Format(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(string(output), s) {
+					t.Errorf("expected output to contain %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONFormatter_Groups(t *testing.T) {
+	output, err := NewFormatter(config.FormatJSON).Format(createTestGroupedExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if _, ok := result["issues"]; ok {
+		t.Error("issues should be omitted when grouped")
+	}
+
+	var groups []struct {
+		Name    string         `json:"name"`
+		Summary map[string]int `json:"summary"`
+		Issues  []jsonIssue    `json:"issues"`
+	}
+	if err := json.Unmarshal(result["groups"], &groups); err != nil {
+		t.Fatalf("invalid groups: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Name != "v1.0" || groups[0].Summary["total"] != 3 {
+		t.Fatalf("unexpected groups: %s", result["groups"])
+	}
+	issue := groups[0].Issues[0]
+	if issue.Resolution == nil || *issue.Resolution != "対応済み" {
+		t.Errorf("unexpected resolution: %v", issue.Resolution)
+	}
+	if strings.Join(issue.Milestone, ",") != "v1.0" || strings.Join(issue.Category, ",") != "画面" {
+		t.Errorf("unexpected milestone/category: %v %v", issue.Milestone, issue.Category)
+	}
+	if len(issue.Children) != 1 {
+		t.Error("children should stay under their parent")
+	}
+}
+
+func TestLoadSnapshot_Groups(t *testing.T) {
+	snap, err := LoadSnapshot(writeSnapshot(t, createTestGroupedExportData()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 複数のグループにある課題は1件として読み込む
+	if len(snap.Issues) != 3 || snap.Issues["MYPROJ-101"] == nil {
+		t.Errorf("expected issues from every group, got %d", len(snap.Issues))
+	}
+}
+
+func TestGroupIssues_Nested(t *testing.T) {
+	data := createTestExportData()
+	nextWeek := "2024-12-04T00:00:00Z"
+	data.Issues[1].Issue.DueDate = &nextWeek
+	data.Issues[1].Issue.Assignee = &backlog.User{ID: 1, Name: "山田"}
+
+	groups := groupIssues(data.Issues, []string{config.GroupByDueWeek, config.GroupByAssignee})
+
+	var names []string
+	for _, g := range groups {
+		if g.Issues != nil {
+			t.Errorf("%s: issues should be in the nested groups", g.Name)
+		}
+		for _, sub := range g.Groups {
+			names = append(names, g.Name+"/"+sub.Name)
+		}
+	}
+	// 2024-12-01（日）はその前の月曜日から始まる週
+	want := "2024-11-25 〜 2024-12-01/山田,2024-12-02 〜 2024-12-08/山田"
+	if strings.Join(names, ",") != want {
+		t.Errorf("unexpected groups: %v", names)
+	}
+	if groups[0].Summary.Total != 2 || groups[0].Groups[0].Summary.Total != 2 {
+		t.Errorf("unexpected summary: %+v", groups[0].Summary)
+	}
+}
+
+func TestFormatter_NestedGroups(t *testing.T) {
+	data := createTestExportData()
+	data.GroupBy = []string{config.GroupByPriority, config.GroupByStatus}
+	data.Groups = groupIssues(data.Issues, data.GroupBy)
+
+	tests := []struct {
+		format   config.OutputFormat
+		contains []string
+	}{
+		{config.FormatTXT, []string{"■ 高（2件）\n\n  ● 処理中（2件）\n\n[MYPROJ-100]", "■ 中（1件）\n\n  ● 未対応（1件）\n"}},
+		{config.FormatMarkdown, []string{"## 高（2件）\n\n### 処理中（2件）\n\n#### [MYPROJ-100] 親課題\n"}},
+		{config.FormatHTML, []string{`<h3 class="group-name depth-0">高（2件）</h3>`, `<h3 class="group-name depth-1">処理中（2件）</h3>`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			output, err := NewFormatter(tt.format).Format(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(string(output), s) {
+					t.Errorf("expected output to contain %q", s)
+				}
+			}
+		})
+	}
+
+	output, err := NewFormatter(config.FormatJSON).Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result struct {
+		GroupBy []string    `json:"groupBy"`
+		Groups  []jsonGroup `json:"groups"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	top := result.Groups[0]
+	if strings.Join(result.GroupBy, ",") != "priority,status" || top.Issues != nil || top.Groups[0].Name != "処理中" {
+		t.Errorf("unexpected nested groups: %s", output)
+	}
+	if len(top.Groups[0].Issues[0].Children) != 1 {
+		t.Error("children should stay under their parent")
+	}
+
+	snap, err := LoadSnapshot(writeSnapshot(t, data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snap.Issues) != 3 {
+		t.Errorf("expected issues from nested groups, got %d", len(snap.Issues))
+	}
+}
@@ -0,0 +1,241 @@
+package exporter
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+// defaultStatusColor は状態に色が設定されていない場合のバッジの色
+const defaultStatusColor = "#999999"
+
+// statusColorPattern はバッジの背景色として許可する色の形式
+var statusColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+//go:embed templates/report.html
+var htmlReportTemplate string
+
+var htmlTemplate = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// HTMLFormatter は単一ファイルで閲覧できるHTML形式のフォーマッター
+// CSSとJavaScriptはすべてインラインで埋め込む
+type HTMLFormatter struct{}
+
+func (f *HTMLFormatter) Extension() string {
+	return "html"
+}
+
+// htmlReport はテンプレートに渡すレポート全体のデータ
+type htmlReport struct {
+	Title      string
+	ExportedAt string
+	Summary    backlog.ExportSummary
+	Multi      bool
+	Projects   []*htmlProject
+	Statuses   []string
+	Assignees  []string
+	Priorities []string
+}
+
+// htmlProject はプロジェクトごとの課題一覧
+type htmlProject struct {
+	Key     string
+	Name    string
+	Summary backlog.ExportSummary
+	// Sections は課題一覧の区分（--group-by 未指定の場合は名前のない1つの区分）
+	Sections []*htmlSection
+}
+
+// htmlSection は --group-by のグループごとの課題一覧
+// 入れ子のグループは親グループの区分の後に続け、Depth で見出しの深さを表す
+type htmlSection struct {
+	Name    string
+	Depth   int
+	Summary backlog.ExportSummary
+	// HasIssues は課題一覧を表示する区分かどうか（下位のグループがある区分は見出しのみ）
+	HasIssues bool
+	// Groups はルート課題ごとの行（ルート課題とその子孫）
+	Groups [][]*htmlRow
+}
+
+// htmlRow は課題一覧の1行
+type htmlRow struct {
+	Key           string
+	ParentKey     string
+	Depth         int
+	HasChildren   bool
+	Summary       string
+	Status        string
+	StatusColor   template.CSS
+	Priority      string
+	PriorityOrder string
+	Assignee      string
+	DueDate       string
+	Updated       string
+	// CustomFields は値が設定されているカスタム属性（件名の下に表示する）
+	CustomFields []htmlCustomField
+	// ContextOnly は取得条件外の親課題（--with-parents）の行かどうか
+	ContextOnly bool
+}
+
+// htmlCustomField はカスタム属性の名前と表示用の値
+type htmlCustomField struct {
+	Name  string
+	Value string
+}
+
+// Indent は階層の深さに応じた左余白（em）を返す
+func (r *htmlRow) Indent() float64 {
+	return 0.5 + float64(r.Depth)*1.5
+}
+
+func (f *HTMLFormatter) Format(data *backlog.ExportData) ([]byte, error) {
+	report := &htmlReport{
+		Title:      fmt.Sprintf("プロジェクト: %s - %s", data.Project.ProjectKey, data.Project.Name),
+		ExportedAt: data.ExportedAt.Format("2006-01-02 15:04:05"),
+		Summary:    data.Summary,
+		Projects:   []*htmlProject{newHTMLProject(data)},
+	}
+	return f.render(report)
+}
+
+// FormatMulti は複数プロジェクトを1つのHTMLにまとめる
+func (f *HTMLFormatter) FormatMulti(data *backlog.MultiExportData) ([]byte, error) {
+	keys := make([]string, 0, len(data.Projects))
+	projects := make([]*htmlProject, 0, len(data.Projects))
+	for _, p := range data.Projects {
+		keys = append(keys, p.Project.ProjectKey)
+		projects = append(projects, newHTMLProject(p))
+	}
+
+	report := &htmlReport{
+		Title:      fmt.Sprintf("プロジェクト: %d件（%s）", len(data.Projects), strings.Join(keys, ", ")),
+		ExportedAt: data.ExportedAt.Format("2006-01-02 15:04:05"),
+		Summary:    data.Summary,
+		Multi:      true,
+		Projects:   projects,
+	}
+	return f.render(report)
+}
+
+func (f *HTMLFormatter) render(report *htmlReport) ([]byte, error) {
+	report.Statuses, report.Assignees, report.Priorities = collectFilterOptions(report.Projects)
+
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, report); err != nil {
+		return nil, fmt.Errorf("failed to render html: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func newHTMLProject(data *backlog.ExportData) *htmlProject {
+	p := &htmlProject{
+		Key:     data.Project.ProjectKey,
+		Name:    data.Project.Name,
+		Summary: data.Summary,
+	}
+	if data.Groups == nil {
+		p.Sections = []*htmlSection{newHTMLSection(&backlog.IssueGroup{Summary: data.Summary, Issues: data.Issues}, 0)}
+		return p
+	}
+	appendHTMLSections(&p.Sections, data.Groups, 0)
+	return p
+}
+
+// appendHTMLSections はグループとその下位のグループを順に区分に変換する
+func appendHTMLSections(sections *[]*htmlSection, groups []*backlog.IssueGroup, depth int) {
+	for _, g := range groups {
+		*sections = append(*sections, newHTMLSection(g, depth))
+		appendHTMLSections(sections, g.Groups, depth+1)
+	}
+}
+
+func newHTMLSection(g *backlog.IssueGroup, depth int) *htmlSection {
+	s := &htmlSection{Name: g.Name, Depth: depth, Summary: g.Summary, HasIssues: g.Groups == nil}
+	for _, hi := range g.Issues {
+		var rows []*htmlRow
+		appendHTMLRows(&rows, hi, "", 0)
+		s.Groups = append(s.Groups, rows)
+	}
+	return s
+}
+
+// appendHTMLRows は課題とその子孫を深さ優先で行に変換する
+func appendHTMLRows(rows *[]*htmlRow, hi *backlog.HierarchicalIssue, parentKey string, depth int) {
+	issue := hi.Issue
+	row := &htmlRow{
+		Key:         issue.IssueKey,
+		ParentKey:   parentKey,
+		Depth:       depth,
+		HasChildren: len(hi.Children) > 0,
+		ContextOnly: hi.ContextOnly,
+		Summary:     issue.Summary,
+		StatusColor: defaultStatusColor,
+		Updated:     issue.Updated.Format("2006-01-02"),
+	}
+	if issue.Status != nil {
+		row.Status = issue.Status.Name
+		if statusColorPattern.MatchString(issue.Status.Color) {
+			row.StatusColor = template.CSS(issue.Status.Color)
+		}
+	}
+	if issue.Priority != nil {
+		row.Priority = issue.Priority.Name
+		// 優先度IDは高いほど小さいため、IDの順に並べる
+		row.PriorityOrder = fmt.Sprintf("%04d", issue.Priority.ID)
+	}
+	if issue.Assignee != nil {
+		row.Assignee = issue.Assignee.Name
+	}
+	row.DueDate = csvDate(issue.DueDate)
+	for _, field := range nonEmptyCustomFields(issue) {
+		row.CustomFields = append(row.CustomFields, htmlCustomField{Name: field.Name, Value: field.String()})
+	}
+	*rows = append(*rows, row)
+
+	for _, child := range hi.Children {
+		appendHTMLRows(rows, child, issue.IssueKey, depth+1)
+	}
+}
+
+// collectFilterOptions はフィルターの選択肢（状態・担当者・優先度）を集める
+func collectFilterOptions(projects []*htmlProject) (statuses, assignees, priorities []string) {
+	statusSet := map[string]bool{}
+	assigneeSet := map[string]bool{}
+	priorityOrder := map[string]string{}
+
+	for _, p := range projects {
+		for _, section := range p.Sections {
+			for _, group := range section.Groups {
+				for _, row := range group {
+					if row.Status != "" && !statusSet[row.Status] {
+						statusSet[row.Status] = true
+						statuses = append(statuses, row.Status)
+					}
+					if row.Assignee != "" && !assigneeSet[row.Assignee] {
+						assigneeSet[row.Assignee] = true
+						assignees = append(assignees, row.Assignee)
+					}
+					if row.Priority != "" {
+						if _, ok := priorityOrder[row.Priority]; !ok {
+							priorityOrder[row.Priority] = row.PriorityOrder
+							priorities = append(priorities, row.Priority)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	sort.Strings(assignees)
+	sort.SliceStable(priorities, func(i, j int) bool {
+		return priorityOrder[priorities[i]] < priorityOrder[priorities[j]]
+	})
+	return statuses, assignees, priorities
+}
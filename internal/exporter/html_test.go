@@ -0,0 +1,128 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func TestHTMLFormatter_Format(t *testing.T) {
+	f := NewFormatter(config.FormatHTML)
+	if f.Extension() != "html" {
+		t.Errorf("expected extension html, got %s", f.Extension())
+	}
+
+	data := createTestExportData()
+	data.Issues[0].Issue.Status.Color = "#4488c5"
+
+	output, err := f.Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := string(output)
+
+	expectedParts := []string{
+		"<!DOCTYPE html>",
+		"プロジェクト: MYPROJ - マイプロジェクト",
+		"取得日時: 2024-11-27 14:30:52",
+		"未完了タスク数: 3件（親課題: 2件、子課題: 1件）",
+		`data-key="MYPROJ-101" data-parent="MYPROJ-100" data-depth="1"`,
+		`background-color: #4488c5`,
+		"2024-12-01",
+		"(未割当)",
+		"<script>",
+	}
+	for _, part := range expectedParts {
+		if !strings.Contains(result, part) {
+			t.Errorf("expected output to contain %q", part)
+		}
+	}
+
+	// 外部リソースを参照しない
+	for _, external := range []string{"<link ", "src="} {
+		if strings.Contains(result, external) {
+			t.Errorf("output should be self-contained, found %q", external)
+		}
+	}
+
+	// 親課題ごとに tbody を分ける
+	if count := strings.Count(result, `<tbody class="group">`); count != 2 {
+		t.Errorf("expected 2 issue groups, got %d", count)
+	}
+}
+
+func TestHTMLFormatter_FilterOptions(t *testing.T) {
+	output, err := (&HTMLFormatter{}).Format(createTestExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := string(output)
+
+	// 優先度は優先度IDの順
+	high := strings.Index(result, "<option>高</option>")
+	mid := strings.Index(result, "<option>中</option>")
+	if high < 0 || mid < 0 || high > mid {
+		t.Errorf("priority options should be ordered by priority: 高=%d 中=%d", high, mid)
+	}
+	for _, option := range []string{"<option>処理中</option>", "<option>山田</option>", "<option>鈴木</option>"} {
+		if !strings.Contains(result, option) {
+			t.Errorf("expected filter option %q", option)
+		}
+	}
+}
+
+func TestHTMLFormatter_Escape(t *testing.T) {
+	data := createTestExportData()
+	data.Issues[0].Issue.Summary = `<script>alert("x")</script>`
+	data.Issues[0].Issue.Status.Color = `red; background-image: url(http://example.com)`
+
+	output, err := (&HTMLFormatter{}).Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := string(output)
+
+	if strings.Contains(result, `<script>alert("x")</script>`) {
+		t.Error("summary should be escaped")
+	}
+	if strings.Contains(result, "example.com") {
+		t.Error("invalid status color should not be rendered")
+	}
+	if !strings.Contains(result, "background-color: "+defaultStatusColor) {
+		t.Error("invalid status color should fall back to the default color")
+	}
+}
+
+func TestHTMLFormatter_FormatMulti(t *testing.T) {
+	output, err := (&HTMLFormatter{}).FormatMulti(createTestMultiExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := string(output)
+
+	if !strings.Contains(result, "プロジェクト: 2件（MYPROJ, OTHER）") {
+		t.Error("expected combined title")
+	}
+	if count := strings.Count(result, `<section class="project">`); count != 2 {
+		t.Errorf("expected 2 project sections, got %d", count)
+	}
+	if !strings.Contains(result, "<h2>OTHER - ") {
+		t.Error("expected project heading for OTHER")
+	}
+}
+
+func TestHTMLFormatter_Empty(t *testing.T) {
+	data := &backlog.ExportData{
+		Project: &backlog.Project{ProjectKey: "EMPTY", Name: "空"},
+		Issues:  []*backlog.HierarchicalIssue{},
+	}
+	output, err := (&HTMLFormatter{}).Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(output), "該当する課題はありません") {
+		t.Error("expected empty message")
+	}
+}
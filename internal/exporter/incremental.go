@@ -0,0 +1,173 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+// stateVersion は状態ファイルの形式のバージョン
+const stateVersion = 3
+
+// exportState は差分エクスポートのために保存する前回の実行結果
+type exportState struct {
+	Version int `json:"version"`
+	// ExportedAt は前回の課題取得を開始した日時
+	ExportedAt time.Time `json:"exportedAt"`
+	// Filter は前回の取得条件（条件が変わった場合は全件を取り直す）
+	Filter *issueFilter `json:"filter"`
+	// Issues は前回エクスポートした課題のスナップショット
+	Issues          []*backlog.Issue                      `json:"issues"`
+	WithComments    bool                                  `json:"withComments"`
+	Comments        map[int][]*backlog.Comment            `json:"comments,omitempty"`
+	WithAttachments bool                                  `json:"withAttachments"`
+	Attachments     map[int][]*backlog.ExportedAttachment `json:"attachments,omitempty"`
+}
+
+// stateFilePath はプロジェクトの状態ファイルのパスを返す
+func (e *Exporter) stateFilePath(projectKey string) string {
+	return filepath.Join(e.config.OutputDir(), fmt.Sprintf(".backlog-tasks-state-%s.json", projectKey))
+}
+
+// loadState は前回の状態を読み込む（状態ファイルがない場合は nil）
+func (e *Exporter) loadState(projectKey string) (*exportState, error) {
+	data, err := os.ReadFile(e.stateFilePath(projectKey))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state exportState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+	if state.Version != stateVersion {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// saveState は今回の状態を保存する
+// 書き込み途中で中断しても前回の状態が壊れないよう、一時ファイルから置き換える
+func (e *Exporter) saveState(projectKey string, state *exportState) error {
+	state.Version = stateVersion
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	statePath := e.stateFilePath(projectKey)
+	tmpPath := statePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmpPath, statePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// reusable は前回の状態を今回の条件で差分取得に使えるかどうかを判定する
+func (s *exportState) reusable(filter *issueFilter, withComments, withAttachments bool) bool {
+	if s.Filter == nil || !s.Filter.equal(filter) {
+		return false
+	}
+	// コメント・添付ファイルは前回取得していなければ全件取り直す
+	if (withComments && !s.WithComments) || (withAttachments && !s.WithAttachments) {
+		return false
+	}
+	return true
+}
+
+func sortedInts(values []int) []int {
+	sorted := slices.Clone(values)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// fetchIssueChanges は前回以降に更新された課題を取得してスナップショットに反映する
+// 戻り値は反映後の課題一覧と、追加・更新された課題
+func (e *Exporter) fetchIssueChanges(ctx context.Context, projectID int, prev *exportState, filter *issueFilter) ([]*backlog.Issue, []*backlog.Issue, error) {
+	// updatedSince は日単位のため、タイムゾーンの差も考慮して1日前から取得する
+	since := prev.ExportedAt.AddDate(0, 0, -1)
+	e.output.Printf("Fetching issues updated since %s...\n", since.Format("2006-01-02"))
+
+	// 完了になった課題や担当者が変わった課題を検出するため、状態・担当者では絞り込まない
+	updated, err := e.client.GetIssues(ctx, backlog.IssueQuery{
+		ProjectID:    projectID,
+		UpdatedSince: &since,
+	}, e.printIssueProgress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	issues, changed, removed := mergeIssueChanges(prev.Issues, updated, filter.match)
+	e.output.Printf("Merged changes: %d updated, %d removed\n", len(changed), removed)
+
+	return issues, changed, nil
+}
+
+// mergeIssueChanges は更新された課題を前回のスナップショットに反映する
+// match を満たさなくなった課題（完了した課題など）はスナップショットから除く
+func mergeIssueChanges(snapshot, updated []*backlog.Issue, match func(*backlog.Issue) bool) (issues, changed []*backlog.Issue, removed int) {
+	byID := make(map[int]*backlog.Issue, len(snapshot))
+	for _, issue := range snapshot {
+		byID[issue.ID] = issue
+	}
+
+	for _, issue := range updated {
+		if match(issue) {
+			byID[issue.ID] = issue
+			changed = append(changed, issue)
+		} else if _, ok := byID[issue.ID]; ok {
+			delete(byID, issue.ID)
+			removed++
+		}
+	}
+
+	issues = make([]*backlog.Issue, 0, len(byID))
+	for _, issue := range byID {
+		issues = append(issues, issue)
+	}
+	// 全件取得時と同じく作成日時の昇順に並べる
+	sort.Slice(issues, func(i, j int) bool {
+		if !issues[i].Created.Equal(issues[j].Created) {
+			return issues[i].Created.Before(issues[j].Created)
+		}
+		return issues[i].ID < issues[j].ID
+	})
+
+	return issues, changed, removed
+}
+
+// mergeIssueValues は今回取得し直した課題の値を前回の値に重ね、現在の課題に関するものだけを残す
+func mergeIssueValues[T any](prev, fetched map[int][]T, issues, changed []*backlog.Issue) map[int][]T {
+	refetched := make(map[int]bool, len(changed))
+	for _, issue := range changed {
+		refetched[issue.ID] = true
+	}
+
+	result := make(map[int][]T, len(issues))
+	for _, issue := range issues {
+		values := prev[issue.ID]
+		if refetched[issue.ID] {
+			values = fetched[issue.ID]
+		}
+		if values != nil {
+			result[issue.ID] = values
+		}
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+package exporter
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func TestExporter_Run_Incremental(t *testing.T) {
+	project, statuses, issues := createTestData()
+
+	var queries []backlog.IssueQuery
+	var updated []*backlog.Issue
+	var commented []string
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			queries = append(queries, query)
+			if query.UpdatedSince != nil {
+				return updated, nil
+			}
+			return issues, nil
+		},
+		GetCommentsFunc: func(ctx context.Context, issueIDOrKey string) ([]*backlog.Comment, error) {
+			commented = append(commented, issueIDOrKey)
+			return []*backlog.Comment{{ID: 1, Content: issueIDOrKey + "のコメント"}}, nil
+		},
+	}
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Project:      "MYPROJ",
+		Output:       tmpDir,
+		Format:       config.FormatJSON,
+		Incremental:  true,
+		WithComments: true,
+	}
+
+	// 1回目: 状態ファイルがないため全件取得
+	exp := NewExporterWithOutput(mockClient, cfg, &testOutput{})
+	if _, err := exp.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if queries[0].UpdatedSince != nil {
+		t.Error("first run should fetch all issues")
+	}
+	if _, err := os.Stat(exp.stateFilePath("MYPROJ")); err != nil {
+		t.Fatalf("state file should be written: %v", err)
+	}
+
+	// 2回目: 子課題が完了、単独タスクが更新、新しい課題が追加
+	completed := *issues[1]
+	completed.Status = &backlog.Status{ID: 4, Name: "完了"}
+	renamed := *issues[2]
+	renamed.Summary = "単独タスク（更新）"
+	added := &backlog.Issue{
+		ID:       300,
+		IssueKey: "MYPROJ-300",
+		Summary:  "新しい課題",
+		Status:   &backlog.Status{ID: 1, Name: "未対応"},
+		Created:  time.Date(2024, 11, 28, 10, 0, 0, 0, time.UTC),
+	}
+	updated = []*backlog.Issue{&completed, &renamed, added}
+	queries = nil
+	commented = nil
+
+	outputPath, err := exp.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(queries) != 1 || queries[0].UpdatedSince == nil {
+		t.Fatalf("second run should fetch only updated issues: %+v", queries)
+	}
+	if len(queries[0].StatusIDs) != 0 {
+		t.Error("updated issues should be fetched regardless of status to detect completed issues")
+	}
+	if strings.Join(commented, ",") != "MYPROJ-200,MYPROJ-300" {
+		t.Errorf("comments should be fetched only for changed issues, got %v", commented)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+	result := string(content)
+
+	if strings.Contains(result, "MYPROJ-101") {
+		t.Error("completed issue should be removed")
+	}
+	for _, part := range []string{"MYPROJ-100", "単独タスク（更新）", "MYPROJ-300", "MYPROJ-100のコメント", "MYPROJ-300のコメント"} {
+		if !strings.Contains(result, part) {
+			t.Errorf("expected output to contain %q", part)
+		}
+	}
+}
+
+func TestExporter_Run_IncrementalConditionsChanged(t *testing.T) {
+	project, statuses, issues := createTestData()
+
+	var queries []backlog.IssueQuery
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			queries = append(queries, query)
+			return issues, nil
+		},
+	}
+
+	cfg := &config.Config{
+		Project:     "MYPROJ",
+		Output:      t.TempDir(),
+		Incremental: true,
+	}
+	exp := NewExporterWithOutput(mockClient, cfg, &testOutput{})
+	if _, err := exp.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 状態の条件が変わった場合は全件取得し直す
+	cfg.AllStatuses = true
+	if _, err := exp.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queries) != 2 || queries[1].UpdatedSince != nil {
+		t.Errorf("changed conditions should trigger a full fetch: %+v", queries)
+	}
+}
+
+func TestMergeIssueChanges(t *testing.T) {
+	_, _, issues := createTestData()
+
+	moved := *issues[0]
+	moved.Assignee = &backlog.User{ID: 2, Name: "鈴木"}
+	unknown := &backlog.Issue{ID: 999, Assignee: &backlog.User{ID: 2}}
+
+	match := func(issue *backlog.Issue) bool {
+		return issue.Assignee != nil && issue.Assignee.ID == 1
+	}
+	merged, changed, removed := mergeIssueChanges(issues, []*backlog.Issue{&moved, unknown}, match)
+
+	if removed != 1 {
+		t.Errorf("expected 1 removed issue, got %d", removed)
+	}
+	if len(changed) != 0 {
+		t.Errorf("expected no changed issues, got %d", len(changed))
+	}
+	if len(merged) != 2 || merged[0].ID != 101 || merged[1].ID != 200 {
+		t.Errorf("unexpected merged issues: %v", merged)
+	}
+}
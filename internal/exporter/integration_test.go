@@ -3,6 +3,7 @@ package exporter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -39,6 +40,9 @@ func setupMockServer(t *testing.T) *httptest.Server {
 			}
 			json.NewEncoder(w).Encode(statuses)
 
+		case strings.HasSuffix(r.URL.Path, "/issues/count"):
+			json.NewEncoder(w).Encode(map[string]int{"count": 4})
+
 		case strings.HasSuffix(r.URL.Path, "/issues"):
 			// 課題一覧を返す
 			dueDate1 := "2024-12-15"
@@ -314,7 +318,12 @@ func TestIntegration_ErrorHandling(t *testing.T) {
 
 	_, err = exp.Run(ctx)
 	if err == nil {
-		t.Error("expected error for non-existent project")
+		t.Fatal("expected error for non-existent project")
+	}
+
+	var notFoundErr *backlog.NotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Errorf("expected NotFoundError to be wrapped, got %T: %v", err, err)
 	}
 }
 
@@ -337,6 +346,9 @@ func TestIntegration_EmptyIssues(t *testing.T) {
 			}
 			json.NewEncoder(w).Encode(statuses)
 
+		case strings.HasSuffix(r.URL.Path, "/issues/count"):
+			json.NewEncoder(w).Encode(map[string]int{"count": 0})
+
 		case strings.HasSuffix(r.URL.Path, "/issues"):
 			// 空の課題一覧
 			json.NewEncoder(w).Encode([]*backlog.Issue{})
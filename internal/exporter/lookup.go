@@ -0,0 +1,144 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+// resolveLookups は課題種別・カテゴリー・マイルストーン・発生バージョン・優先度・カスタム属性の指定をIDに解決する
+// 指定がある項目についてのみ一覧を取得する
+func (e *Exporter) resolveLookups(ctx context.Context, projectIDOrKey string, filter *issueFilter) error {
+	cfg := e.config
+	if len(cfg.IssueTypes) == 0 && len(cfg.Categories) == 0 && len(cfg.Milestones) == 0 &&
+		len(cfg.Versions) == 0 && len(cfg.Priorities) == 0 && len(cfg.CustomFields) == 0 {
+		return nil
+	}
+
+	e.output.Printf("Resolving filters... ")
+	if err := e.fetchLookups(ctx, projectIDOrKey, filter); err != nil {
+		e.output.Printf("failed\n")
+		return err
+	}
+	e.output.Printf("done\n")
+	return nil
+}
+
+func (e *Exporter) fetchLookups(ctx context.Context, projectIDOrKey string, filter *issueFilter) error {
+	cfg := e.config
+
+	if len(cfg.IssueTypes) > 0 {
+		issueTypes, err := e.client.GetIssueTypes(ctx, projectIDOrKey)
+		if err != nil {
+			return fmt.Errorf("failed to get issue types: %w", err)
+		}
+		filter.IssueTypeIDs, err = findIDs(issueTypes, cfg.IssueTypes, "issue type", func(t *backlog.IssueType) (int, string) {
+			return t.ID, t.Name
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.Categories) > 0 {
+		categories, err := e.client.GetCategories(ctx, projectIDOrKey)
+		if err != nil {
+			return fmt.Errorf("failed to get categories: %w", err)
+		}
+		filter.CategoryIDs, err = findIDs(categories, cfg.Categories, "category", func(c *backlog.Category) (int, string) {
+			return c.ID, c.Name
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// マイルストーンと発生バージョンは同じバージョン一覧から選択する
+	if len(cfg.Milestones) > 0 || len(cfg.Versions) > 0 {
+		versions, err := e.client.GetVersions(ctx, projectIDOrKey)
+		if err != nil {
+			return fmt.Errorf("failed to get versions: %w", err)
+		}
+		describe := func(v *backlog.Version) (int, string) { return v.ID, v.Name }
+		if len(cfg.Milestones) > 0 {
+			if filter.MilestoneIDs, err = findIDs(versions, cfg.Milestones, "milestone", describe); err != nil {
+				return err
+			}
+		}
+		if len(cfg.Versions) > 0 {
+			if filter.VersionIDs, err = findIDs(versions, cfg.Versions, "version", describe); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(cfg.Priorities) > 0 {
+		priorities, err := e.client.GetPriorities(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get priorities: %w", err)
+		}
+		filter.PriorityIDs, err = findIDs(priorities, cfg.Priorities, "priority", func(p *backlog.Priority) (int, string) {
+			return p.ID, p.Name
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.CustomFields) > 0 {
+		if err := e.resolveCustomFields(ctx, projectIDOrKey, filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findIDs は名前またはIDの指定から項目のIDを検索する（重複は除く）
+// findStatus と同じく、名前は完全一致を優先し、見つからなければ大文字小文字を区別せずに比較する
+func findIDs[T any](items []T, specs []string, kind string, describe func(T) (int, string)) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+
+	for _, spec := range specs {
+		id, ok := findID(items, strings.TrimSpace(spec), describe)
+		if !ok {
+			names := make([]string, 0, len(items))
+			for _, item := range items {
+				id, name := describe(item)
+				names = append(names, fmt.Sprintf("%s (%d)", name, id))
+			}
+			return nil, fmt.Errorf("unknown %s %q. Available: %s", kind, spec, strings.Join(names, ", "))
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func findID[T any](items []T, spec string, describe func(T) (int, string)) (int, bool) {
+	if n, err := strconv.Atoi(spec); err == nil {
+		for _, item := range items {
+			if id, _ := describe(item); id == n {
+				return id, true
+			}
+		}
+	}
+	for _, item := range items {
+		if id, name := describe(item); name == spec {
+			return id, true
+		}
+	}
+	for _, item := range items {
+		if id, name := describe(item); strings.EqualFold(name, spec) {
+			return id, true
+		}
+	}
+	return 0, false
+}
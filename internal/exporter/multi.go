@@ -0,0 +1,238 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+const (
+	// defaultConcurrency は複数プロジェクトを同時に取得する数のデフォルト
+	defaultConcurrency = 4
+	// combinedReportName はまとめレポートのファイル名に使う名前
+	combinedReportName = "combined"
+)
+
+// MultiFormatter は複数プロジェクトを1つのレポートにまとめて出力できるフォーマッター
+type MultiFormatter interface {
+	FormatMulti(data *backlog.MultiExportData) ([]byte, error)
+}
+
+// resolveProjectKeys は対象のプロジェクトIDまたはキーの一覧を決定する
+func (e *Exporter) resolveProjectKeys(ctx context.Context) ([]string, error) {
+	if !e.config.AllProjects {
+		keys := e.config.ProjectKeys()
+		if len(keys) == 0 {
+			return nil, errors.New("project is required")
+		}
+		return keys, nil
+	}
+
+	e.output.Printf("Fetching projects... ")
+	projects, err := e.client.GetProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+	e.output.Printf("done (%d projects)\n", len(projects))
+
+	if len(projects) == 0 {
+		return nil, errors.New("no projects found")
+	}
+
+	keys := make([]string, 0, len(projects))
+	for _, p := range projects {
+		keys = append(keys, p.ProjectKey)
+	}
+	return keys, nil
+}
+
+// runMulti は複数プロジェクトを並行して取得し、まとめて、または個別に出力する
+func (e *Exporter) runMulti(ctx context.Context, projectKeys []string) ([]string, error) {
+	results, err := e.exportProjects(ctx, projectKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	data := newMultiExportData(results)
+
+	// サマリー表示
+	e.output.Printf("Summary (%d projects):\n", len(results))
+	for _, r := range results {
+		e.output.Printf("  %s: %d issues (parent: %d, child: %d)\n",
+			r.Project.ProjectKey, r.Summary.Total, r.Summary.ParentIssues, r.Summary.ChildIssues)
+	}
+	e.output.Printf("  Total issues: %d\n", data.Summary.Total)
+	e.output.Printf("  Parent issues: %d\n", data.Summary.ParentIssues)
+	e.output.Printf("  Child issues: %d\n\n", data.Summary.ChildIssues)
+
+	var paths []string
+	if e.config.Combined {
+		content, err := e.formatMulti(data)
+		if err != nil {
+			return nil, err
+		}
+
+		path, err := e.writeReport(combinedReportName, content)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	} else {
+		for _, r := range results {
+			content, err := e.formatter.Format(r)
+			if err != nil {
+				return nil, fmt.Errorf("%s: failed to format output: %w", r.Project.ProjectKey, err)
+			}
+
+			path, err := e.writeReport(r.Project.ProjectKey, content)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, path)
+		}
+	}
+
+	for _, path := range paths {
+		e.output.Printf("Output: %s\n", path)
+	}
+	e.output.Printf("Done!\n")
+
+	return paths, nil
+}
+
+// formatMulti は複数プロジェクトを1つのレポートにまとめる
+func (e *Exporter) formatMulti(data *backlog.MultiExportData) ([]byte, error) {
+	mf, ok := e.formatter.(MultiFormatter)
+	if !ok {
+		return nil, fmt.Errorf("format %s does not support combined output", e.config.Format)
+	}
+
+	content, err := mf.FormatMulti(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format output: %w", err)
+	}
+	return content, nil
+}
+
+// newMultiExportData は各プロジェクトの結果と合計をまとめる
+func newMultiExportData(results []*backlog.ExportData) *backlog.MultiExportData {
+	data := &backlog.MultiExportData{
+		ExportedAt: time.Now(),
+		Projects:   results,
+	}
+	for _, r := range results {
+		data.Summary.Total += r.Summary.Total
+		data.Summary.ParentIssues += r.Summary.ParentIssues
+		data.Summary.ChildIssues += r.Summary.ChildIssues
+	}
+	return data
+}
+
+// exportProjects は複数プロジェクトを同時実行数を制限しながら取得する
+// 結果は projectKeys と同じ順序で返し、いずれかが失敗した場合は残りを中断する
+func (e *Exporter) exportProjects(ctx context.Context, projectKeys []string) ([]*backlog.ExportData, error) {
+	concurrency := e.config.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		outputMu sync.Mutex
+		errOnce  sync.Once
+		firstErr error
+		results  = make([]*backlog.ExportData, len(projectKeys))
+		sem      = make(chan struct{}, concurrency)
+	)
+
+	for i, key := range projectKeys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			out := &prefixedOutput{out: e.output, mu: &outputMu, prefix: key}
+			data, err := e.withOutput(out).exportProject(ctx, key)
+			out.flush()
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("%s: %w", key, err)
+					cancel()
+				})
+				return
+			}
+			results[i] = data
+		}(i, key)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// withOutput は出力先だけを差し替えたExporterを返す
+func (e *Exporter) withOutput(output Output) *Exporter {
+	clone := *e
+	clone.output = output
+	return &clone
+}
+
+// prefixedOutput は行単位でプロジェクト名を前置して出力する
+// 並行実行時に複数プロジェクトの進捗表示が行の途中で混ざらないようにする
+type prefixedOutput struct {
+	out    Output
+	mu     *sync.Mutex
+	prefix string
+	buf    strings.Builder
+}
+
+func (p *prefixedOutput) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&p.buf, format, args...)
+
+	pending := p.buf.String()
+	lines := strings.Split(pending, "\n")
+	p.buf.Reset()
+	p.buf.WriteString(lines[len(lines)-1])
+
+	for _, line := range lines[:len(lines)-1] {
+		p.writeLine(line)
+	}
+}
+
+// flush は改行で終わっていない残りの出力を書き出す
+func (p *prefixedOutput) flush() {
+	if p.buf.Len() > 0 {
+		p.writeLine(p.buf.String())
+		p.buf.Reset()
+	}
+}
+
+func (p *prefixedOutput) writeLine(line string) {
+	if line == "" {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.out.Printf("[%s] %s\n", p.prefix, line)
+}
@@ -0,0 +1,181 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// createMultiProjectClient は複数プロジェクトを返すモッククライアントを作成する
+func createMultiProjectClient() *backlog.MockClient {
+	projects := map[string]*backlog.Project{
+		"ALPHA": {ID: 1, ProjectKey: "ALPHA", Name: "アルファ"},
+		"BETA":  {ID: 2, ProjectKey: "BETA", Name: "ベータ"},
+		"GAMMA": {ID: 3, ProjectKey: "GAMMA", Name: "ガンマ"},
+	}
+
+	return &backlog.MockClient{
+		GetProjectsFunc: func(ctx context.Context) ([]*backlog.Project, error) {
+			return []*backlog.Project{projects["ALPHA"], projects["BETA"], projects["GAMMA"]}, nil
+		},
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			p, ok := projects[projectIDOrKey]
+			if !ok {
+				return nil, &backlog.NotFoundError{Err: &backlog.APIError{StatusCode: 404}}
+			}
+			return p, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return []*backlog.Status{{ID: 1, Name: "未対応"}, {ID: 4, Name: "完了"}}, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			projectID := query.ProjectID
+			var issues []*backlog.Issue
+			for i := 0; i < projectID; i++ {
+				issues = append(issues, &backlog.Issue{
+					ID:        projectID*100 + i,
+					ProjectID: projectID,
+					IssueKey:  "KEY-" + string(rune('0'+projectID)) + string(rune('0'+i)),
+					Summary:   "課題",
+					Created:   time.Date(2024, 11, 1, 10, 0, 0, 0, time.UTC),
+					Updated:   time.Date(2024, 11, 1, 10, 0, 0, 0, time.UTC),
+				})
+			}
+			if progressFn != nil {
+				progressFn(len(issues), len(issues))
+			}
+			return issues, nil
+		},
+	}
+}
+
+func TestExporter_RunAll_SeparateFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Projects: []string{"ALPHA", "BETA", "GAMMA"},
+		Output:   tmpDir,
+		Format:   config.FormatTXT,
+	}
+
+	exp := NewExporterWithOutput(createMultiProjectClient(), cfg, &testOutput{})
+	paths, err := exp.RunAll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paths) != 3 {
+		t.Fatalf("expected 3 output files, got %d", len(paths))
+	}
+	for i, key := range []string{"ALPHA", "BETA", "GAMMA"} {
+		if !strings.HasPrefix(filepath.Base(paths[i]), key+"_tasks_") {
+			t.Errorf("expected output %d to be for %s, got %s", i, key, paths[i])
+		}
+	}
+}
+
+func TestExporter_RunAll_Combined(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		AllProjects: true,
+		Combined:    true,
+		Concurrency: 2,
+		Output:      tmpDir,
+		Format:      config.FormatJSON,
+	}
+
+	exp := NewExporterWithOutput(createMultiProjectClient(), cfg, &testOutput{})
+	paths, err := exp.RunAll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paths) != 1 {
+		t.Fatalf("expected 1 combined output, got %d", len(paths))
+	}
+	if !strings.HasPrefix(filepath.Base(paths[0]), "combined_tasks_") {
+		t.Errorf("unexpected combined filename: %s", paths[0])
+	}
+
+	content, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatalf("failed to read output file: %v", err)
+	}
+
+	var data jsonMultiExportData
+	if err := json.Unmarshal(content, &data); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if len(data.Projects) != 3 {
+		t.Fatalf("expected 3 projects, got %d", len(data.Projects))
+	}
+	// 並行取得しても指定順を保つ
+	for i, key := range []string{"ALPHA", "BETA", "GAMMA"} {
+		if data.Projects[i].Project.Key != key {
+			t.Errorf("expected project %d to be %s, got %s", i, key, data.Projects[i].Project.Key)
+		}
+	}
+	if data.Summary.Total != 6 {
+		t.Errorf("expected combined total 6, got %d", data.Summary.Total)
+	}
+}
+
+func TestExporter_RunAll_ProjectError(t *testing.T) {
+	cfg := &config.Config{
+		Projects: []string{"ALPHA", "MISSING", "GAMMA"},
+		Output:   t.TempDir(),
+		Format:   config.FormatTXT,
+	}
+
+	exp := NewExporterWithOutput(createMultiProjectClient(), cfg, &testOutput{})
+	_, err := exp.RunAll(context.Background())
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	var notFoundErr *backlog.NotFoundError
+	if !errors.As(err, &notFoundErr) {
+		t.Errorf("expected NotFoundError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "MISSING") {
+		t.Errorf("error should name the failing project: %v", err)
+	}
+}
+
+// recordingOutput は出力内容を記録する
+type recordingOutput struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (r *recordingOutput) Printf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"))
+}
+
+func TestPrefixedOutput(t *testing.T) {
+	rec := &recordingOutput{}
+	out := &prefixedOutput{out: rec, mu: &sync.Mutex{}, prefix: "ALPHA"}
+
+	out.Printf("Fetching statuses... ")
+	out.Printf("done\n")
+	out.Printf("\n")
+	out.Printf("Building hierarchy... ")
+	out.flush()
+
+	expected := []string{"[ALPHA] Fetching statuses... done", "[ALPHA] Building hierarchy... "}
+	if strings.Join(rec.lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %q, got %q", expected, rec.lines)
+	}
+}
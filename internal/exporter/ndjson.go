@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+// NDJSONFormatter は1行に1課題のJSONを出力するフォーマッター
+// 親子関係は入れ子にせず parentIssueId で表すため、課題を取得しながら1件ずつ書き出せる
+type NDJSONFormatter struct{}
+
+func (f *NDJSONFormatter) Extension() string {
+	return "ndjson"
+}
+
+// ndjsonIssue はNDJSONの1行分の課題
+// 項目はJSON形式の課題と同じで、children の代わりにプロジェクトキーを持つ
+type ndjsonIssue struct {
+	Project string `json:"project"`
+	jsonIssue
+	// Children は jsonIssue の children を隠すためのフィールドで、常に空
+	Children []jsonIssue `json:"children,omitempty"`
+}
+
+// Format は課題を親課題、子課題の順に1行ずつ出力する
+func (f *NDJSONFormatter) Format(data *backlog.ExportData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.writeIssues(&buf, data.Project, data.Issues); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FormatMulti は複数プロジェクトの課題を続けて出力する
+func (f *NDJSONFormatter) FormatMulti(data *backlog.MultiExportData) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, p := range data.Projects {
+		if err := f.writeIssues(&buf, p.Project, p.Issues); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *NDJSONFormatter) writeIssues(w io.Writer, project *backlog.Project, issues []*backlog.HierarchicalIssue) error {
+	for _, hi := range issues {
+		if err := f.writeIssue(w, project, hi); err != nil {
+			return err
+		}
+		if err := f.writeIssues(w, project, hi.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeIssue は課題を1行出力する（子課題は含めない）
+func (f *NDJSONFormatter) writeIssue(w io.Writer, project *backlog.Project, hi *backlog.HierarchicalIssue) error {
+	issue := *hi
+	issue.Children = nil
+
+	line, err := json.Marshal(ndjsonIssue{
+		Project:   project.ProjectKey,
+		jsonIssue: (&JSONFormatter{}).convertIssue(&issue),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
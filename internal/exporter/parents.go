@@ -0,0 +1,50 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+// fetchParents は取得した課題の親課題のうち matched に含まれないものを祖先までさかのぼって返す（--with-parents）
+// 取得済みの課題（条件式で除外した課題）はそのまま使い、それ以外は完了した課題も含めてIDで取得する
+func (e *Exporter) fetchParents(ctx context.Context, matched, fetched []*backlog.Issue) ([]*backlog.Issue, error) {
+	known := make(map[int]bool, len(matched))
+	for _, issue := range matched {
+		known[issue.ID] = true
+	}
+	fetchedByID := make(map[int]*backlog.Issue, len(fetched))
+	for _, issue := range fetched {
+		fetchedByID[issue.ID] = issue
+	}
+
+	e.output.Printf("Fetching parent issues... ")
+	var parents []*backlog.Issue
+	for pending := matched; len(pending) > 0; {
+		var next []*backlog.Issue
+		for _, issue := range pending {
+			if issue.ParentIssueID == nil || known[*issue.ParentIssueID] {
+				continue
+			}
+			id := *issue.ParentIssueID
+			known[id] = true
+
+			parent, ok := fetchedByID[id]
+			if !ok {
+				var err error
+				parent, err = e.client.GetIssue(ctx, strconv.Itoa(id))
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", issue.IssueKey, err)
+				}
+			}
+			next = append(next, parent)
+		}
+		parents = append(parents, next...)
+		pending = next
+	}
+	e.output.Printf("done\n")
+
+	return parents, nil
+}
@@ -0,0 +1,272 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// testIssue は親課題のIDを指定してテスト用の課題を作成する（parentID が 0 の場合は親課題なし）
+func testIssue(id int, key, summary string, parentID int) *backlog.Issue {
+	issue := &backlog.Issue{
+		ID:       id,
+		IssueKey: key,
+		Summary:  summary,
+		Status:   &backlog.Status{ID: 1, Name: "未対応"},
+		Created:  time.Date(2024, 11, 1, 10, 0, 0, 0, time.UTC),
+		Updated:  time.Date(2024, 11, 2, 10, 0, 0, 0, time.UTC),
+	}
+	if parentID != 0 {
+		issue.ParentIssueID = &parentID
+	}
+	return issue
+}
+
+func TestExporter_BuildHierarchy_Parents(t *testing.T) {
+	issues := []*backlog.Issue{
+		testIssue(200, "MYPROJ-200", "単独タスク", 0),
+		testIssue(102, "MYPROJ-102", "孫課題", 101),
+		testIssue(101, "MYPROJ-101", "子課題", 100),
+		testIssue(301, "MYPROJ-301", "別の子課題", 300),
+	}
+	parents := []*backlog.Issue{
+		testIssue(100, "MYPROJ-100", "完了した親課題", 0),
+		testIssue(300, "MYPROJ-300", "完了した親課題2", 0),
+	}
+
+	exp := &Exporter{}
+	roots, summary := exp.buildHierarchy(issues, parents)
+
+	var keys []string
+	for _, hi := range roots {
+		keys = append(keys, hi.Issue.IssueKey)
+	}
+	// 取得条件外の親課題は最初に現れる子孫の位置に置く
+	if strings.Join(keys, ",") != "MYPROJ-200,MYPROJ-100,MYPROJ-300" {
+		t.Fatalf("unexpected roots: %v", keys)
+	}
+	if !roots[1].ContextOnly || !roots[2].ContextOnly || roots[0].ContextOnly {
+		t.Error("only fetched parents should be context only")
+	}
+	child := roots[1].Children[0]
+	if child.Issue.IssueKey != "MYPROJ-101" || child.ContextOnly {
+		t.Fatalf("unexpected child: %+v", child)
+	}
+	if len(child.Children) != 1 || child.Children[0].Issue.IssueKey != "MYPROJ-102" {
+		t.Errorf("grandchild should be nested under the child: %+v", child.Children)
+	}
+
+	// 取得条件外の親課題は件数に含めない
+	want := backlog.ExportSummary{Total: 4, ParentIssues: 1, ChildIssues: 3}
+	if summary != want {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	groups := groupIssues(roots, []string{config.GroupByStatus})
+	if groups[0].Summary != want {
+		t.Errorf("unexpected group summary: %+v", groups[0].Summary)
+	}
+}
+
+func TestExporter_Run_WithParents(t *testing.T) {
+	project, statuses, _ := createTestData()
+	issues := []*backlog.Issue{
+		testIssue(102, "MYPROJ-102", "孫課題", 101),
+		testIssue(201, "MYPROJ-201", "条件式で除外した課題の子課題", 200),
+		testIssue(200, "MYPROJ-200", "条件式で除外する課題", 0),
+	}
+	done := map[string]*backlog.Issue{
+		"101": testIssue(101, "MYPROJ-101", "完了した子課題", 100),
+		"100": testIssue(100, "MYPROJ-100", "完了した親課題", 0),
+	}
+
+	var requested []string
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			return issues, nil
+		},
+		GetIssueFunc: func(ctx context.Context, issueIDOrKey string) (*backlog.Issue, error) {
+			requested = append(requested, issueIDOrKey)
+			if issue, ok := done[issueIDOrKey]; ok {
+				return issue, nil
+			}
+			return nil, &backlog.NotFoundError{}
+		},
+	}
+
+	cfg := &config.Config{
+		Project:     "MYPROJ",
+		Format:      config.FormatJSON,
+		Where:       `summary != "条件式で除外する課題"`,
+		WithParents: true,
+	}
+	content, err := NewExporterWithOutput(mockClient, cfg, &testOutput{}).Render(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 条件式で除外した課題は取得済みのものを使い、それ以外の親課題を祖先までIDで取得する
+	if strings.Join(requested, ",") != "101,100" {
+		t.Errorf("unexpected requested parents: %v", requested)
+	}
+
+	var doc jsonExportData
+	if err := json.Unmarshal(content, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.Summary != (jsonSummary{Total: 2, ParentIssues: 0, ChildIssues: 2}) {
+		t.Errorf("unexpected summary: %+v", doc.Summary)
+	}
+	if len(doc.Issues) != 2 {
+		t.Fatalf("expected 2 roots, got %d", len(doc.Issues))
+	}
+	root := doc.Issues[0]
+	if root.IssueKey != "MYPROJ-100" || !root.ContextOnly {
+		t.Fatalf("unexpected root: %+v", root)
+	}
+	child := root.Children[0]
+	if child.IssueKey != "MYPROJ-101" || !child.ContextOnly || child.Children[0].IssueKey != "MYPROJ-102" || child.Children[0].ContextOnly {
+		t.Errorf("unexpected hierarchy: %+v", child)
+	}
+	if doc.Issues[1].IssueKey != "MYPROJ-200" || !doc.Issues[1].ContextOnly {
+		t.Errorf("filtered parent should be context only: %+v", doc.Issues[1])
+	}
+}
+
+func TestExporter_Run_WithParents_Error(t *testing.T) {
+	project, statuses, issues := createTestData()
+	issues = issues[1:]
+
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			return issues, nil
+		},
+		GetIssueFunc: func(ctx context.Context, issueIDOrKey string) (*backlog.Issue, error) {
+			return nil, errors.New("connection reset")
+		},
+	}
+
+	cfg := &config.Config{Project: "MYPROJ", Format: config.FormatJSON, WithParents: true}
+	_, err := NewExporterWithOutput(mockClient, cfg, &testOutput{}).Render(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "failed to get parent issues: MYPROJ-101") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// createTestNestedExportData は取得条件外の親課題と孫課題を含む出力データを作成する
+func createTestNestedExportData() *backlog.ExportData {
+	data := createTestExportData()
+	parent := data.Issues[0]
+	parent.Children[0].Children = []*backlog.HierarchicalIssue{
+		{Issue: testIssue(102, "MYPROJ-102", "孫課題1", 101)},
+		{Issue: testIssue(103, "MYPROJ-103", "孫課題2", 101), Children: []*backlog.HierarchicalIssue{
+			{Issue: testIssue(104, "MYPROJ-104", "ひ孫課題", 103)},
+		}},
+	}
+	parent.Children = append(parent.Children, &backlog.HierarchicalIssue{Issue: testIssue(105, "MYPROJ-105", "子課題2", 100)})
+	data.Issues = append(data.Issues, &backlog.HierarchicalIssue{
+		Issue:       testIssue(1, "MYPROJ-1", "完了した親課題", 0),
+		ContextOnly: true,
+		Children:    []*backlog.HierarchicalIssue{{Issue: testIssue(2, "MYPROJ-2", "子課題3", 1)}},
+	})
+	data.Summary = backlog.ExportSummary{Total: 8, ParentIssues: 2, ChildIssues: 6}
+	return data
+}
+
+func TestTXTFormatter_NestedHierarchy(t *testing.T) {
+	output, err := (&TXTFormatter{}).Format(createTestNestedExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `  ├─ [MYPROJ-101] 子課題
+  │    状態: 処理済み
+  │    優先度: 高
+  │    担当者: 鈴木
+  │    期限日: -
+  │
+  │    ├─ [MYPROJ-102] 孫課題1
+  │    │    状態: 未対応
+  │    │    優先度: -
+  │    │    担当者: (未割当)
+  │    │    期限日: -
+  │    │
+  │    └─ [MYPROJ-103] 孫課題2
+  │         状態: 未対応
+  │         優先度: -
+  │         担当者: (未割当)
+  │         期限日: -
+  │
+  │         └─ [MYPROJ-104] ひ孫課題
+  │              状態: 未対応
+  │              優先度: -
+  │              担当者: (未割当)
+  │              期限日: -
+  │
+  └─ [MYPROJ-105] 子課題2
+`
+	if !strings.Contains(string(output), want) {
+		t.Errorf("nested children should be rendered recursively:\n%s", output)
+	}
+	if !strings.Contains(string(output), "[MYPROJ-1] 完了した親課題（取得条件外）\n") {
+		t.Errorf("context only parent should be marked:\n%s", output)
+	}
+}
+
+func TestFormatters_NestedHierarchy(t *testing.T) {
+	data := createTestNestedExportData()
+
+	md, err := (&MarkdownFormatter{}).Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"## [MYPROJ-1] 完了した親課題（取得条件外）\n",
+		"#### [MYPROJ-101] 子課題\n",
+		"##### 子課題\n\n###### [MYPROJ-102] 孫課題1\n",
+		// Markdownの見出しは6段まで
+		"###### [MYPROJ-104] ひ孫課題\n",
+	} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("markdown output should contain %q:\n%s", want, md)
+		}
+	}
+
+	csv, err := (&CSVFormatter{Delimiter: ',', Columns: []string{"key", "parentKey", "contextOnly"}}).Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"MYPROJ-104,MYPROJ-103,false\n", "MYPROJ-1,,true\n", "MYPROJ-2,MYPROJ-1,false\n"} {
+		if !strings.Contains(string(csv), want) {
+			t.Errorf("csv output should contain %q:\n%s", want, csv)
+		}
+	}
+
+	html, err := (&HTMLFormatter{}).Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(html), `data-key="MYPROJ-104" data-parent="MYPROJ-103" data-depth="3"`) {
+		t.Error("html output should contain the great-grandchild row")
+	}
+	if strings.Count(string(html), `<span class="context-only">`) != 1 {
+		t.Error("html output should mark the context only parent")
+	}
+}
@@ -0,0 +1,102 @@
+package exporter
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// issueCompareFunc は2つの課題を比較する（desc が true の場合は降順）
+type issueCompareFunc func(a, b *backlog.Issue, desc bool) int
+
+// issueCompareFuncs は --sort の項目ごとの比較関数
+var issueCompareFuncs = map[string]issueCompareFunc{
+	config.SortDueDate: compareBy(func(issue *backlog.Issue) (string, bool) {
+		date := csvDate(issue.DueDate)
+		return date, date != ""
+	}),
+	// 優先度IDは高いほど小さいため、昇順は優先度の高い順になる
+	config.SortPriority: compareBy(func(issue *backlog.Issue) (int, bool) {
+		if issue.Priority == nil {
+			return 0, false
+		}
+		return issue.Priority.ID, true
+	}),
+	config.SortUpdated: compareBy(func(issue *backlog.Issue) (int64, bool) {
+		return issue.Updated.UnixNano(), !issue.Updated.IsZero()
+	}),
+	config.SortStatus: compareBy(func(issue *backlog.Issue) (int, bool) {
+		if issue.Status == nil {
+			return 0, false
+		}
+		return issue.Status.DisplayOrder, true
+	}),
+	config.SortAssignee: compareBy(func(issue *backlog.Issue) (string, bool) {
+		if issue.Assignee == nil {
+			return "", false
+		}
+		return issue.Assignee.Name, true
+	}),
+	config.SortKeyID: compareBy(func(issue *backlog.Issue) (int, bool) {
+		return issue.KeyID, true
+	}),
+}
+
+// compareBy は課題の値で比較する関数を作成する
+// 値が設定されていない課題（ok が false）は昇順・降順にかかわらず末尾にする
+func compareBy[T cmp.Ordered](value func(issue *backlog.Issue) (T, bool)) issueCompareFunc {
+	return func(a, b *backlog.Issue, desc bool) int {
+		av, aok := value(a)
+		bv, bok := value(b)
+		switch {
+		case !aok || !bok:
+			// 設定されているほうを先にする
+			return cmp.Compare(boolOrder(!aok), boolOrder(!bok))
+		case desc:
+			return cmp.Compare(bv, av)
+		default:
+			return cmp.Compare(av, bv)
+		}
+	}
+}
+
+func boolOrder(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// parseSortKeys は --sort の指定を解析する
+func parseSortKeys(specs []string) ([]config.SortKey, error) {
+	keys := make([]config.SortKey, 0, len(specs))
+	for _, spec := range specs {
+		key, err := config.ParseSortKey(spec)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// sortIssues はルート課題と各課題の子課題をそれぞれ keys の順に並べ替える
+// 先頭のキーを優先し、すべてのキーが等しい課題は元の順序（取得順）を保つ
+func sortIssues(issues []*backlog.HierarchicalIssue, keys []config.SortKey) {
+	if len(keys) == 0 {
+		return
+	}
+	slices.SortStableFunc(issues, func(a, b *backlog.HierarchicalIssue) int {
+		for _, key := range keys {
+			if c := issueCompareFuncs[key.Field](a.Issue, b.Issue, key.Desc); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	for _, hi := range issues {
+		sortIssues(hi.Children, keys)
+	}
+}
@@ -0,0 +1,115 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func createSortTestIssues() []*backlog.HierarchicalIssue {
+	due := func(s string) *string { return &s }
+	high := &backlog.Priority{ID: 2, Name: "高"}
+	normal := &backlog.Priority{ID: 3, Name: "中"}
+	issue := func(keyID int, priority *backlog.Priority, dueDate *string, children ...*backlog.HierarchicalIssue) *backlog.HierarchicalIssue {
+		return &backlog.HierarchicalIssue{
+			Issue: &backlog.Issue{
+				KeyID:    keyID,
+				IssueKey: fmt.Sprintf("P-%d", keyID),
+				Priority: priority,
+				DueDate:  dueDate,
+				Updated:  time.Date(2024, 11, keyID, 0, 0, 0, 0, time.UTC),
+			},
+			Children: children,
+		}
+	}
+	return []*backlog.HierarchicalIssue{
+		issue(1, normal, due("2024-12-10")),
+		issue(2, high, nil, issue(5, normal, due("2024-12-03")), issue(6, high, due("2024-12-05"))),
+		issue(3, high, due("2024-12-20")),
+		issue(4, nil, due("2024-12-01")),
+	}
+}
+
+func issueKeys(issues []*backlog.HierarchicalIssue) string {
+	keys := make([]string, 0, len(issues))
+	for _, hi := range issues {
+		keys = append(keys, hi.Issue.IssueKey)
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestSortIssues(t *testing.T) {
+	tests := []struct {
+		name     string
+		specs    []string
+		want     string
+		children string
+	}{
+		{name: "no keys", want: "P-1,P-2,P-3,P-4", children: "P-5,P-6"},
+		{name: "due date", specs: []string{"dueDate"}, want: "P-4,P-1,P-3,P-2", children: "P-5,P-6"},
+		{name: "due date desc keeps empty last", specs: []string{"dueDate:desc"}, want: "P-3,P-1,P-4,P-2", children: "P-6,P-5"},
+		{name: "priority then due date", specs: []string{"priority", "dueDate"}, want: "P-3,P-2,P-1,P-4", children: "P-6,P-5"},
+		{name: "updated desc", specs: []string{"updated:desc"}, want: "P-4,P-3,P-2,P-1", children: "P-6,P-5"},
+		{name: "key id desc", specs: []string{"keyId:desc"}, want: "P-4,P-3,P-2,P-1", children: "P-6,P-5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseSortKeys(tt.specs)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			issues := createSortTestIssues()
+			sortIssues(issues, keys)
+			if got := issueKeys(issues); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+			// 子課題は親課題の下で並べ替える
+			for _, hi := range issues {
+				if hi.Issue.KeyID == 2 {
+					if got := issueKeys(hi.Children); got != tt.children {
+						t.Errorf("expected children %s, got %s", tt.children, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestExporter_Run_SortAndGroup(t *testing.T) {
+	project, statuses, issues := createTestData()
+
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			return issues, nil
+		},
+	}
+	cfg := &config.Config{
+		Format:  config.FormatJSON,
+		GroupBy: []string{config.GroupByStatus},
+		Sort:    []string{"updated"},
+	}
+	exp := NewExporterWithOutput(mockClient, cfg, &testOutput{})
+
+	data, err := exp.exportProject(context.Background(), "MYPROJ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Issues == nil || len(data.Groups) == 0 {
+		t.Fatal("expected sorted issues and groups")
+	}
+	if got := issueKeys(data.Issues); got != "MYPROJ-200,MYPROJ-100" {
+		t.Errorf("issues should be sorted by updated: %s", got)
+	}
+}
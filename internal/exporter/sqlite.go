@@ -0,0 +1,327 @@
+package exporter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+
+	// SQLiteのドライバー（CGOを使わない実装）
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema はSQLite出力のテーブル定義
+// 同じファイルに繰り返し出力して履歴を蓄積できるよう、既存のテーブルはそのまま使う
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS projects (
+	id          INTEGER PRIMARY KEY,
+	project_key TEXT NOT NULL,
+	name        TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS statuses (
+	id    INTEGER PRIMARY KEY,
+	name  TEXT NOT NULL,
+	color TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS users (
+	id           INTEGER PRIMARY KEY,
+	user_id      TEXT NOT NULL,
+	name         TEXT NOT NULL,
+	mail_address TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS issue_types (
+	id         INTEGER PRIMARY KEY,
+	project_id INTEGER NOT NULL,
+	name       TEXT NOT NULL,
+	color      TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS priorities (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS issues (
+	id               INTEGER PRIMARY KEY,
+	project_id       INTEGER NOT NULL REFERENCES projects (id),
+	issue_key        TEXT NOT NULL,
+	key_id           INTEGER NOT NULL,
+	issue_type_id    INTEGER REFERENCES issue_types (id),
+	summary          TEXT NOT NULL,
+	description      TEXT NOT NULL,
+	status_id        INTEGER REFERENCES statuses (id),
+	priority_id      INTEGER REFERENCES priorities (id),
+	resolution       TEXT,
+	assignee_id      INTEGER REFERENCES users (id),
+	start_date       TEXT,
+	due_date         TEXT,
+	estimated_hours  REAL,
+	actual_hours     REAL,
+	created_user_id  INTEGER REFERENCES users (id),
+	created          TEXT NOT NULL,
+	updated_user_id  INTEGER REFERENCES users (id),
+	updated          TEXT NOT NULL,
+	last_exported_at TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS issues_issue_key ON issues (issue_key);
+CREATE TABLE IF NOT EXISTS issue_parents (
+	issue_id        INTEGER PRIMARY KEY REFERENCES issues (id),
+	parent_issue_id INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS issue_parents_parent_issue_id ON issue_parents (parent_issue_id);
+CREATE TABLE IF NOT EXISTS comments (
+	id        INTEGER PRIMARY KEY,
+	issue_id  INTEGER NOT NULL REFERENCES issues (id),
+	author_id INTEGER REFERENCES users (id),
+	content   TEXT NOT NULL,
+	created   TEXT NOT NULL,
+	updated   TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS exports (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	project_id  INTEGER NOT NULL REFERENCES projects (id),
+	exported_at TEXT NOT NULL,
+	total       INTEGER NOT NULL
+);
+`
+
+// SQLiteFormatter はSQLiteのデータベースに出力するフォーマッター
+// 課題・状態・ユーザーなどをテーブルに分けて保存し、SQLで集計できるようにする
+type SQLiteFormatter struct{}
+
+func (f *SQLiteFormatter) Extension() string {
+	return "sqlite"
+}
+
+// Format は新しいデータベースを作成してファイルの内容を返す
+func (f *SQLiteFormatter) Format(data *backlog.ExportData) ([]byte, error) {
+	return f.formatNew(data)
+}
+
+// FormatMulti は複数プロジェクトを1つの新しいデータベースにまとめる
+func (f *SQLiteFormatter) FormatMulti(data *backlog.MultiExportData) ([]byte, error) {
+	return f.formatNew(data.Projects...)
+}
+
+func (f *SQLiteFormatter) formatNew(projects ...*backlog.ExportData) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "backlog-exporter-sqlite")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "tasks.sqlite")
+	if err := f.WriteFile(context.Background(), path, projects...); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// WriteFile は path のデータベースに課題を書き込む
+// ファイルが既にある場合は同じIDの行を更新し、出力から外れた課題（完了した課題など）の行は残す
+func (f *SQLiteFormatter) WriteFile(ctx context.Context, path string, projects ...*backlog.ExportData) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		return fmt.Errorf("failed to create tables: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	w := &sqliteWriter{ctx: ctx, tx: tx}
+	for _, data := range projects {
+		w.writeProject(data)
+	}
+	if w.err != nil {
+		return w.err
+	}
+	return tx.Commit()
+}
+
+// sqliteWriter はトランザクション内で行を書き込む
+// 最初に発生したエラーを err に保持し、以降の書き込みは行わない
+type sqliteWriter struct {
+	ctx context.Context
+	tx  *sql.Tx
+	err error
+}
+
+// upsert は key が重複した場合に残りの列を更新する
+func (w *sqliteWriter) upsert(table, key string, columns []string, values ...any) {
+	if w.err != nil {
+		return
+	}
+
+	var updates []string
+	for _, c := range columns {
+		if c != key {
+			updates = append(updates, fmt.Sprintf("%s = excluded.%s", c, c))
+		}
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s",
+		table, strings.Join(columns, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "), key, strings.Join(updates, ", "))
+	if _, err := w.tx.ExecContext(w.ctx, query, values...); err != nil {
+		w.err = fmt.Errorf("failed to write %s: %w", table, err)
+	}
+}
+
+func (w *sqliteWriter) exec(query string, args ...any) {
+	if w.err != nil {
+		return
+	}
+	if _, err := w.tx.ExecContext(w.ctx, query, args...); err != nil {
+		w.err = fmt.Errorf("failed to write: %w", err)
+	}
+}
+
+func (w *sqliteWriter) writeProject(data *backlog.ExportData) {
+	p := data.Project
+	w.upsert("projects", "id", []string{"id", "project_key", "name"}, p.ID, p.ProjectKey, p.Name)
+
+	exportedAt := data.ExportedAt.Format(time.RFC3339)
+	w.writeIssues(p, data.Issues, nil, exportedAt)
+	w.exec("INSERT INTO exports (project_id, exported_at, total) VALUES (?, ?, ?)", p.ID, exportedAt, data.Summary.Total)
+}
+
+// writeIssues は課題と子孫を書き込む（parent は階層上の親課題、ルートの場合は nil）
+func (w *sqliteWriter) writeIssues(project *backlog.Project, issues []*backlog.HierarchicalIssue, parent *backlog.Issue, exportedAt string) {
+	for _, hi := range issues {
+		w.writeIssue(project, hi, parent, exportedAt)
+		w.writeIssues(project, hi.Children, hi.Issue, exportedAt)
+	}
+}
+
+func (w *sqliteWriter) writeIssue(project *backlog.Project, hi *backlog.HierarchicalIssue, parent *backlog.Issue, exportedAt string) {
+	issue := hi.Issue
+
+	var issueTypeID, statusID, priorityID any
+	if t := issue.IssueType; t != nil {
+		w.upsert("issue_types", "id", []string{"id", "project_id", "name", "color"}, t.ID, project.ID, t.Name, t.Color)
+		issueTypeID = t.ID
+	}
+	if s := issue.Status; s != nil {
+		w.upsert("statuses", "id", []string{"id", "name", "color"}, s.ID, s.Name, s.Color)
+		statusID = s.ID
+	}
+	if p := issue.Priority; p != nil {
+		w.upsert("priorities", "id", []string{"id", "name"}, p.ID, p.Name)
+		priorityID = p.ID
+	}
+	var resolution any
+	if issue.Resolution != nil {
+		resolution = issue.Resolution.Name
+	}
+
+	w.upsert("issues", "id", []string{
+		"id", "project_id", "issue_key", "key_id", "issue_type_id", "summary", "description",
+		"status_id", "priority_id", "resolution", "assignee_id", "start_date", "due_date",
+		"estimated_hours", "actual_hours", "created_user_id", "created", "updated_user_id", "updated", "last_exported_at",
+	},
+		issue.ID, project.ID, issue.IssueKey, issue.KeyID, issueTypeID, issue.Summary, issue.Description,
+		statusID, priorityID, resolution, w.user(issue.Assignee), sqliteDate(issue.StartDate), sqliteDate(issue.DueDate),
+		issue.EstimatedHours, issue.ActualHours, w.user(issue.CreatedUser), issue.Created.Format(time.RFC3339),
+		w.user(issue.UpdatedUser), issue.Updated.Format(time.RFC3339), exportedAt,
+	)
+
+	// 親課題が取得対象外の場合も parentIssueId で親子関係を残し、親課題が外された場合は行を削除する
+	parentID := issue.ParentIssueID
+	if parent != nil {
+		parentID = &parent.ID
+	}
+	if parentID != nil {
+		w.upsert("issue_parents", "issue_id", []string{"issue_id", "parent_issue_id"}, issue.ID, *parentID)
+	} else {
+		w.exec("DELETE FROM issue_parents WHERE issue_id = ?", issue.ID)
+	}
+
+	for _, c := range hi.Comments {
+		w.upsert("comments", "id", []string{"id", "issue_id", "author_id", "content", "created", "updated"},
+			c.ID, issue.ID, w.user(c.CreatedUser), c.Content, c.Created.Format(time.RFC3339), c.Updated.Format(time.RFC3339))
+	}
+}
+
+// user はユーザーを書き込んでIDを返す（未設定の場合は nil）
+func (w *sqliteWriter) user(u *backlog.User) any {
+	if u == nil {
+		return nil
+	}
+	w.upsert("users", "id", []string{"id", "user_id", "name", "mail_address"}, u.ID, u.UserID, u.Name, u.MailAddress)
+	return u.ID
+}
+
+// sqliteDate は日付を YYYY-MM-DD で返す（未設定の場合は nil）
+func sqliteDate(d *string) any {
+	if date := csvDate(d); date != "" {
+		return date
+	}
+	return nil
+}
+
+// runSQLite は課題を取得してSQLiteのデータベースに書き込む（--format sqlite）
+// ファイル名に日時を付けず、再実行すると同じファイルを更新する
+func (e *Exporter) runSQLite(ctx context.Context, projectKeys []string) ([]string, error) {
+	var results []*backlog.ExportData
+	if len(projectKeys) == 1 {
+		data, err := e.exportProject(ctx, projectKeys[0])
+		if err != nil {
+			return nil, err
+		}
+		results = []*backlog.ExportData{data}
+	} else {
+		var err error
+		if results, err = e.exportProjects(ctx, projectKeys); err != nil {
+			return nil, err
+		}
+	}
+
+	// サマリー表示
+	total := newMultiExportData(results).Summary
+	e.output.Printf("Summary:\n")
+	e.output.Printf("  Total issues: %d\n", total.Total)
+	e.output.Printf("  Parent issues: %d\n", total.ParentIssues)
+	e.output.Printf("  Child issues: %d\n\n", total.ChildIssues)
+
+	reports := [][]*backlog.ExportData{results}
+	if len(results) > 1 && !e.config.Combined {
+		reports = reports[:0]
+		for _, r := range results {
+			reports = append(reports, []*backlog.ExportData{r})
+		}
+	}
+
+	formatter := &SQLiteFormatter{}
+	var paths []string
+	for _, projects := range reports {
+		name := projects[0].Project.ProjectKey
+		if len(projects) > 1 {
+			name = combinedReportName
+		}
+		path := e.config.OutputFile
+		if path == "" {
+			path = filepath.Join(e.config.Output, fmt.Sprintf("%s_tasks.%s", name, formatter.Extension()))
+		}
+
+		if err := formatter.WriteFile(ctx, path, projects...); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+
+	for _, path := range paths {
+		e.output.Printf("Output: %s\n", path)
+	}
+	e.output.Printf("Done!\n")
+
+	return paths, nil
+}
@@ -0,0 +1,148 @@
+package exporter
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func openTestSQLite(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// queryString は1行1列の結果を文字列で返す（NULL の場合は "NULL"）
+func queryString(t *testing.T, db *sql.DB, query string, args ...any) string {
+	t.Helper()
+	var v sql.NullString
+	if err := db.QueryRow(query, args...).Scan(&v); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	if !v.Valid {
+		return "NULL"
+	}
+	return v.String
+}
+
+func TestSQLiteFormatter_WriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.sqlite")
+	f := &SQLiteFormatter{}
+
+	data := createTestNestedExportData()
+	data.Issues[0].Comments = []*backlog.Comment{
+		{ID: 1, Content: "確認しました", CreatedUser: &backlog.User{ID: 3, UserID: "sato", Name: "佐藤"}},
+	}
+	if err := f.WriteFile(context.Background(), path, data); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	db := openTestSQLite(t, path)
+	for query, want := range map[string]string{
+		"SELECT count(*) FROM issues":                                      "9",
+		"SELECT count(*) FROM users":                                       "3",
+		"SELECT count(*) FROM statuses":                                    "3",
+		"SELECT count(*) FROM priorities":                                  "2",
+		"SELECT name FROM statuses WHERE id = 2":                           "処理中",
+		"SELECT due_date FROM issues WHERE issue_key = 'MYPROJ-100'":       "2024-12-01",
+		"SELECT assignee_id FROM issues WHERE issue_key = 'MYPROJ-200'":    "NULL",
+		"SELECT content FROM comments WHERE issue_id = 100":                "確認しました",
+		"SELECT u.name FROM comments c JOIN users u ON u.id = c.author_id": "佐藤",
+		"SELECT parent_issue_id FROM issue_parents WHERE issue_id = 101":   "100",
+		"SELECT parent_issue_id FROM issue_parents WHERE issue_id = 104":   "103",
+		"SELECT count(*) FROM issue_parents":                               "6",
+		"SELECT total FROM exports":                                        "8",
+		"SELECT project_key FROM projects WHERE id = 1":                    "MYPROJ",
+	} {
+		if got := queryString(t, db, query); got != want {
+			t.Errorf("%s: expected %s, got %s", query, want, got)
+		}
+	}
+
+	// 再実行すると同じ課題の行を更新し、出力から外れた課題の行は残す
+	next := createTestExportData()
+	next.ExportedAt = time.Date(2024, 11, 28, 9, 0, 0, 0, time.UTC)
+	next.Issues[0].Issue.Status = &backlog.Status{ID: 3, Name: "処理済み"}
+	next.Issues[0].Children = nil
+	next.Issues = append(next.Issues, &backlog.HierarchicalIssue{Issue: testIssue(101, "MYPROJ-101", "親課題から外した子課題", 0)})
+	if err := f.WriteFile(context.Background(), path, next); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for query, want := range map[string]string{
+		"SELECT count(*) FROM issues":                                       "9",
+		"SELECT status_id FROM issues WHERE id = 100":                       "3",
+		"SELECT summary FROM issues WHERE id = 101":                         "親課題から外した子課題",
+		"SELECT count(*) FROM issue_parents WHERE issue_id = 101":           "0",
+		"SELECT last_exported_at FROM issues WHERE id = 100":                "2024-11-28T09:00:00Z",
+		"SELECT count(*) FROM issues WHERE last_exported_at < '2024-11-28'": "6",
+		"SELECT count(*) FROM exports":                                      "2",
+		"SELECT count(*) FROM comments":                                     "1",
+	} {
+		if got := queryString(t, db, query); got != want {
+			t.Errorf("%s: expected %s, got %s", query, want, got)
+		}
+	}
+}
+
+func TestSQLiteFormatter_Format(t *testing.T) {
+	content, err := (&SQLiteFormatter{}).Format(createTestExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "SQLite format 3\x00") {
+		t.Errorf("expected a SQLite database, got %q", content[:min(len(content), 16)])
+	}
+}
+
+func TestExporter_Run_SQLite(t *testing.T) {
+	project, statuses, issues := createTestData()
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			return issues, nil
+		},
+	}
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{Project: "MYPROJ", Output: tmpDir, Format: config.FormatSQLite}
+
+	// ファイル名に日時を付けないため、再実行すると同じファイルを更新する
+	for i := 0; i < 2; i++ {
+		outputPath, err := NewExporterWithOutput(mockClient, cfg, &testOutput{}).Run(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outputPath != filepath.Join(tmpDir, "MYPROJ_tasks.sqlite") {
+			t.Fatalf("unexpected output path: %s", outputPath)
+		}
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("expected a single database file, got %d files", len(entries))
+	}
+	db := openTestSQLite(t, filepath.Join(tmpDir, "MYPROJ_tasks.sqlite"))
+	if got := queryString(t, db, "SELECT count(*) FROM issues"); got != "3" {
+		t.Errorf("expected 3 issues, got %s", got)
+	}
+	if got := queryString(t, db, "SELECT count(*) FROM exports"); got != "2" {
+		t.Errorf("expected 2 exports, got %s", got)
+	}
+}
@@ -0,0 +1,127 @@
+package exporter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+// Backlog の既定の「完了」状態
+// 状態を明示的に指定しなかった場合はこれを除外して未完了課題のみを取得する
+const (
+	defaultCompletedStatusID   = 4
+	defaultCompletedStatusName = "完了"
+)
+
+// resolveStatusIDs は設定に従って取得対象の状態IDを決定する
+//
+//   - AllStatuses が指定された場合はすべての状態
+//   - IncludeStatuses が指定された場合はその状態のみ
+//   - それ以外はプロジェクトのすべての状態
+//
+// から ExcludeStatuses（未指定かつ IncludeStatuses も未指定の場合は既定の「完了」）を除外する
+func (e *Exporter) resolveStatusIDs(statuses []*backlog.Status) ([]int, error) {
+	if e.config.AllStatuses {
+		ids := make([]int, 0, len(statuses))
+		for _, s := range statuses {
+			ids = append(ids, s.ID)
+		}
+		return ids, nil
+	}
+
+	candidates := statuses
+	if len(e.config.IncludeStatuses) > 0 {
+		included, err := findStatuses(statuses, e.config.IncludeStatuses)
+		if err != nil {
+			return nil, err
+		}
+		candidates = included
+	}
+
+	excluded := make(map[int]bool)
+	switch {
+	case len(e.config.ExcludeStatuses) > 0:
+		found, err := findStatuses(statuses, e.config.ExcludeStatuses)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range found {
+			excluded[s.ID] = true
+		}
+	case len(e.config.IncludeStatuses) == 0:
+		for _, s := range statuses {
+			if isDefaultCompletedStatus(s) {
+				excluded[s.ID] = true
+			}
+		}
+	}
+
+	var ids []int
+	for _, s := range candidates {
+		if !excluded[s.ID] {
+			ids = append(ids, s.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no statuses selected: check --include-status and --exclude-status")
+	}
+
+	return ids, nil
+}
+
+// isDefaultCompletedStatus は既定の「完了」状態かどうかを判定する
+func isDefaultCompletedStatus(s *backlog.Status) bool {
+	return s.ID == defaultCompletedStatusID || s.Name == defaultCompletedStatusName
+}
+
+// findStatuses は名前またはIDの指定から状態を検索する（重複は除く）
+func findStatuses(statuses []*backlog.Status, specs []string) ([]*backlog.Status, error) {
+	var result []*backlog.Status
+	seen := make(map[int]bool)
+
+	for _, spec := range specs {
+		s, err := findStatus(statuses, spec)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[s.ID] {
+			seen[s.ID] = true
+			result = append(result, s)
+		}
+	}
+
+	return result, nil
+}
+
+// findStatus は名前またはIDで状態を検索する
+// 名前は完全一致を優先し、見つからなければ大文字小文字を区別せずに比較する
+func findStatus(statuses []*backlog.Status, spec string) (*backlog.Status, error) {
+	spec = strings.TrimSpace(spec)
+
+	if id, err := strconv.Atoi(spec); err == nil {
+		for _, s := range statuses {
+			if s.ID == id {
+				return s, nil
+			}
+		}
+	}
+	for _, s := range statuses {
+		if s.Name == spec {
+			return s, nil
+		}
+	}
+	for _, s := range statuses {
+		if strings.EqualFold(s.Name, spec) {
+			return s, nil
+		}
+	}
+
+	names := make([]string, 0, len(statuses))
+	for _, s := range statuses {
+		names = append(names, fmt.Sprintf("%s (%d)", s.Name, s.ID))
+	}
+	return nil, fmt.Errorf("unknown status %q. Available statuses: %s", spec, strings.Join(names, ", "))
+}
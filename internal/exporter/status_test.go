@@ -0,0 +1,89 @@
+package exporter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func TestExporter_ResolveStatusIDs(t *testing.T) {
+	statuses := []*backlog.Status{
+		{ID: 1, Name: "未対応"},
+		{ID: 2, Name: "処理中"},
+		{ID: 3, Name: "処理済み"},
+		{ID: 4, Name: "完了"},
+		{ID: 101, Name: "Won't fix"},
+		{ID: 102, Name: "Released"},
+	}
+
+	tests := []struct {
+		name     string
+		config   *config.Config
+		expected []int
+		errMsg   string
+	}{
+		{
+			name:     "default excludes 完了",
+			config:   &config.Config{},
+			expected: []int{1, 2, 3, 101, 102},
+		},
+		{
+			name:     "exclude by name and ID",
+			config:   &config.Config{ExcludeStatuses: []string{"完了", "won't fix", "102"}},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:     "include only",
+			config:   &config.Config{IncludeStatuses: []string{"処理中", "4"}},
+			expected: []int{2, 4},
+		},
+		{
+			name:     "include and exclude",
+			config:   &config.Config{IncludeStatuses: []string{"1", "2", "3"}, ExcludeStatuses: []string{"処理済み"}},
+			expected: []int{1, 2},
+		},
+		{
+			name:     "all statuses",
+			config:   &config.Config{AllStatuses: true},
+			expected: []int{1, 2, 3, 4, 101, 102},
+		},
+		{
+			name:   "unknown status name",
+			config: &config.Config{ExcludeStatuses: []string{"Closed"}},
+			errMsg: `unknown status "Closed"`,
+		},
+		{
+			name:   "unknown status ID",
+			config: &config.Config{IncludeStatuses: []string{"999"}},
+			errMsg: `unknown status "999"`,
+		},
+		{
+			name:   "nothing selected",
+			config: &config.Config{IncludeStatuses: []string{"完了"}, ExcludeStatuses: []string{"4"}},
+			errMsg: "no statuses selected",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			exp := NewExporter(&backlog.MockClient{}, tc.config)
+			ids, err := exp.resolveStatusIDs(statuses)
+
+			if tc.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+					t.Fatalf("expected error containing %q, got %v", tc.errMsg, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(ids, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package exporter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// runStream は課題を1ページずつ取得しながら NDJSON で書き出す（--format ndjson）
+// 取得した課題を保持しないため、課題数が多いプロジェクトでもメモリ使用量は増えない
+// 複数プロジェクトは順に取得し、--combined では1つのファイルに続けて書き出す
+func (e *Exporter) runStream(ctx context.Context, projectKeys []string) ([]string, error) {
+	where, err := parseWhere(e.config.Where)
+	if err != nil {
+		return nil, err
+	}
+	// 親課題が先に取得されるとは限らないため、親課題の項目は参照できない
+	if where != nil && where.parentRefs {
+		return nil, errors.New("--where cannot refer to parent fields with --format ndjson")
+	}
+
+	reports := [][]string{projectKeys}
+	if len(projectKeys) > 1 && !e.config.Combined {
+		reports = reports[:0]
+		for _, key := range projectKeys {
+			reports = append(reports, []string{key})
+		}
+	}
+
+	var (
+		paths []string
+		total backlog.ExportSummary
+	)
+	e.output.Printf("Streaming issues as NDJSON\n")
+	for _, keys := range reports {
+		path, summary, err := e.streamReport(ctx, keys, where)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+		total.Total += summary.Total
+		total.ParentIssues += summary.ParentIssues
+		total.ChildIssues += summary.ChildIssues
+	}
+
+	// サマリー表示
+	e.output.Printf("Summary:\n")
+	e.output.Printf("  Total issues: %d\n", total.Total)
+	e.output.Printf("  Parent issues: %d\n", total.ParentIssues)
+	e.output.Printf("  Child issues: %d\n\n", total.ChildIssues)
+
+	for _, path := range paths {
+		e.output.Printf("Output: %s\n", path)
+	}
+	e.output.Printf("Done!\n")
+
+	return paths, nil
+}
+
+// streamReport は keys のプロジェクトの課題を1つのレポートに書き出してパスを返す
+// レポートは最初のプロジェクトの情報を取得した後に作成する（ファイル名にプロジェクトキーを使うため）
+func (e *Exporter) streamReport(ctx context.Context, keys []string, where *whereCondition) (string, backlog.ExportSummary, error) {
+	var (
+		report  *reportWriter
+		summary backlog.ExportSummary
+	)
+	defer func() {
+		if report != nil {
+			report.close()
+		}
+	}()
+
+	// 複数プロジェクトをまとめる場合はどのプロジェクトで失敗したかを示す
+	wrap := func(key string, err error) error {
+		if len(keys) > 1 {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		return err
+	}
+
+	for _, key := range keys {
+		project, filter, err := e.prepareProject(ctx, key)
+		if err != nil {
+			return "", summary, wrap(key, err)
+		}
+		if report == nil {
+			name := project.ProjectKey
+			if len(keys) > 1 {
+				name = combinedReportName
+			}
+			if report, err = e.createReport(name); err != nil {
+				return "", summary, err
+			}
+		}
+		if err := e.streamProject(ctx, report, project, filter, where, &summary); err != nil {
+			return "", summary, wrap(key, err)
+		}
+		e.output.Printf("\n")
+	}
+
+	path := report.path
+	err := report.close()
+	report = nil
+	if err != nil {
+		return "", summary, err
+	}
+	return path, summary, nil
+}
+
+// streamProject は1つのプロジェクトの課題を1ページずつ取得して書き出し、件数を summary に加える
+// 親課題が取得条件に含まれるかは分からないため、親課題のある課題を子課題として数える
+func (e *Exporter) streamProject(ctx context.Context, report *reportWriter, project *backlog.Project, filter *issueFilter, where *whereCondition, summary *backlog.ExportSummary) error {
+	formatter := &NDJSONFormatter{}
+
+	return e.client.WalkIssues(ctx, filter.query(project.ID), func(issues []*backlog.Issue) error {
+		issues = where.filter(filter.apply(issues))
+
+		var comments map[int][]*backlog.Comment
+		if e.config.WithComments {
+			var err error
+			if comments, err = e.fetchComments(ctx, issues); err != nil {
+				return fmt.Errorf("failed to get comments: %w", err)
+			}
+		}
+		var attachments map[int][]*backlog.ExportedAttachment
+		if e.config.WithAttachments {
+			var err error
+			if attachments, err = e.downloadAttachments(ctx, issues); err != nil {
+				return fmt.Errorf("failed to export attachments: %w", err)
+			}
+		}
+
+		for _, issue := range issues {
+			hi := &backlog.HierarchicalIssue{
+				Issue:       issue,
+				Comments:    comments[issue.ID],
+				Attachments: attachments[issue.ID],
+			}
+			if err := formatter.writeIssue(report, project, hi); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+
+			summary.Total++
+			if issue.ParentIssueID != nil {
+				summary.ChildIssues++
+			} else {
+				summary.ParentIssues++
+			}
+		}
+
+		// 標準出力へのパイプでも取得したページから順に読めるよう、ページごとに書き出す
+		if err := report.Flush(); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}, e.printIssueProgress)
+}
+
+// reportWriter は書き出し中のレポート（ファイルまたは標準出力）
+type reportWriter struct {
+	*bufio.Writer
+	// file は標準出力の場合は nil
+	file *os.File
+	path string
+}
+
+// createReport はレポートのファイルを作成する（--output - では標準出力に書き出す）
+func (e *Exporter) createReport(name string) (*reportWriter, error) {
+	if e.config.Output == config.OutputStdout {
+		return &reportWriter{Writer: bufio.NewWriter(e.stdout), path: config.OutputStdout}, nil
+	}
+
+	path := e.reportPath(name)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	return &reportWriter{Writer: bufio.NewWriter(file), file: file, path: path}, nil
+}
+
+// close は残りを書き出してファイルを閉じる
+func (r *reportWriter) close() error {
+	err := r.Flush()
+	if r.file != nil {
+		if closeErr := r.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// decodeNDJSON はNDJSONの各行を読み込む
+func decodeNDJSON(t *testing.T, content string) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		var v map[string]any
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		lines = append(lines, v)
+	}
+	return lines
+}
+
+func TestNDJSONFormatter_Format(t *testing.T) {
+	output, err := (&NDJSONFormatter{}).Format(createTestNestedExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := decodeNDJSON(t, string(output))
+	var keys []string
+	for _, line := range lines {
+		keys = append(keys, line["issueKey"].(string))
+		if _, ok := line["children"]; ok {
+			t.Errorf("%s: children should not be written", line["issueKey"])
+		}
+		if line["project"] != "MYPROJ" {
+			t.Errorf("%s: unexpected project %v", line["issueKey"], line["project"])
+		}
+	}
+	// 親課題の後に子孫を深さ優先で出力する
+	want := "MYPROJ-100,MYPROJ-101,MYPROJ-102,MYPROJ-103,MYPROJ-104,MYPROJ-105,MYPROJ-200,MYPROJ-1,MYPROJ-2"
+	if strings.Join(keys, ",") != want {
+		t.Errorf("unexpected order: %v", keys)
+	}
+	if lines[4]["parentIssueId"] != float64(103) {
+		t.Errorf("unexpected parentIssueId: %v", lines[4]["parentIssueId"])
+	}
+	if lines[7]["contextOnly"] != true {
+		t.Error("context only parent should be marked")
+	}
+}
+
+func TestExporter_Run_NDJSON(t *testing.T) {
+	project, statuses, issues := createTestData()
+
+	var stdout bytes.Buffer
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		WalkIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, pageFn func(issues []*backlog.Issue) error, progressFn func(fetched, total int)) error {
+			// 処理済みの MYPROJ-101 は条件式で除外される
+			streamed := []int{1, 1, 2}
+			for i, issue := range issues {
+				if err := pageFn([]*backlog.Issue{issue}); err != nil {
+					return err
+				}
+				// 次のページを取得する前に書き出されている
+				if got := strings.Count(stdout.String(), "\n"); got != streamed[i] {
+					t.Errorf("page %d: expected streamed lines, got %d", i, got)
+				}
+			}
+			return nil
+		},
+		GetCommentsFunc: func(ctx context.Context, issueIDOrKey string) ([]*backlog.Comment, error) {
+			return []*backlog.Comment{{ID: 1, Content: issueIDOrKey + "へのコメント"}}, nil
+		},
+	}
+
+	cfg := &config.Config{
+		Project:      "MYPROJ",
+		Output:       config.OutputStdout,
+		Format:       config.FormatNDJSON,
+		Where:        `status != "処理済み"`,
+		WithComments: true,
+	}
+	output := &recordingOutput{}
+	exp := NewExporterWithOutput(mockClient, cfg, output)
+	exp.stdout = &stdout
+
+	outputPath, err := exp.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outputPath != config.OutputStdout {
+		t.Errorf("expected %q, got %q", config.OutputStdout, outputPath)
+	}
+
+	lines := decodeNDJSON(t, stdout.String())
+	if len(lines) != 2 || lines[0]["issueKey"] != "MYPROJ-100" || lines[1]["issueKey"] != "MYPROJ-200" {
+		t.Fatalf("unexpected lines:\n%s", stdout.String())
+	}
+	comments := lines[1]["comments"].([]any)
+	if comments[0].(map[string]any)["content"] != "MYPROJ-200へのコメント" {
+		t.Errorf("unexpected comments: %v", comments)
+	}
+	if !slices.Contains(output.lines, "  Total issues: 2") {
+		t.Errorf("unexpected summary: %v", output.lines)
+	}
+}
+
+func TestExporter_Run_NDJSON_Combined(t *testing.T) {
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return &backlog.Project{ID: len(projectIDOrKey), ProjectKey: projectIDOrKey}, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			return []*backlog.Issue{testIssue(query.ProjectID, "ISSUE-1", "課題", 0)}, nil
+		},
+	}
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		Projects:    []string{"ALPHA", "BETA"},
+		Combined:    true,
+		AllStatuses: true,
+		Output:      tmpDir,
+		Format:      config.FormatNDJSON,
+	}
+	paths, err := NewExporterWithOutput(mockClient, cfg, &testOutput{}).RunAll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 1 || !strings.HasPrefix(filepath.Base(paths[0]), "combined_tasks_") || filepath.Ext(paths[0]) != ".ndjson" {
+		t.Fatalf("unexpected paths: %v", paths)
+	}
+
+	content, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := decodeNDJSON(t, string(content))
+	if len(lines) != 2 || lines[0]["project"] != "ALPHA" || lines[1]["project"] != "BETA" {
+		t.Errorf("unexpected lines:\n%s", content)
+	}
+}
+
+func TestExporter_Run_NDJSON_ParentWhere(t *testing.T) {
+	cfg := &config.Config{Project: "MYPROJ", Format: config.FormatNDJSON, Where: `parent.status = "完了"`}
+	_, err := NewExporterWithOutput(&backlog.MockClient{}, cfg, &testOutput{}).Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "cannot refer to parent fields") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package exporter
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+//go:embed templates/txt.tmpl
+var txtBuiltinTemplate string
+
+//go:embed templates/markdown.tmpl
+var markdownBuiltinTemplate string
+
+// builtinTemplate は組み込みのテンプレート（TXT・Markdown形式と同じレイアウト）
+type builtinTemplate struct {
+	source    string
+	extension string
+}
+
+// builtinTemplates は組み込みテンプレートの一覧（名前 -> テンプレート）
+var builtinTemplates = map[string]builtinTemplate{
+	"txt":      {txtBuiltinTemplate, "txt"},
+	"markdown": {markdownBuiltinTemplate, "md"},
+}
+
+// BuiltinTemplateNames は組み込みテンプレートの名前の一覧を返す
+func BuiltinTemplateNames() []string {
+	names := make([]string, 0, len(builtinTemplates))
+	for name := range builtinTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinTemplate は組み込みテンプレートの内容を返す（カスタマイズの元として書き出す）
+func BuiltinTemplate(name string) (string, error) {
+	t, ok := builtinTemplates[name]
+	if !ok {
+		return "", fmt.Errorf("unknown template %q. Available: %s", name, strings.Join(BuiltinTemplateNames(), ", "))
+	}
+	return t.source, nil
+}
+
+// ValidateTemplate はテンプレートを読み込んで構文の誤りがあれば返す
+func ValidateTemplate(path string) error {
+	_, err := (&TemplateFormatter{Path: path}).parse()
+	return err
+}
+
+// ============================================
+// Template Formatter
+// ============================================
+
+// TemplateFormatter はテンプレートで backlog.ExportData を出力するフォーマッター
+// 拡張子が .html・.htm のテンプレート（例: report.html.tmpl）は html/template で値をエスケープし、
+// それ以外は text/template で出力する
+type TemplateFormatter struct {
+	// Path はテンプレートファイルのパスまたは組み込みテンプレートの名前
+	Path string
+}
+
+// templateExecutor は text/template と html/template に共通の実行処理
+type templateExecutor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// Extension はテンプレートの名前から .tmpl などを除いた拡張子を返す（例: weekly.md.tmpl -> md）
+// 拡張子がない場合は txt とする
+func (f *TemplateFormatter) Extension() string {
+	if t, ok := builtinTemplates[f.Path]; ok {
+		return t.extension
+	}
+	name := filepath.Base(f.Path)
+	for _, suffix := range []string{".tmpl", ".tpl", ".gotmpl"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	if ext := strings.TrimPrefix(filepath.Ext(name), "."); ext != "" {
+		return ext
+	}
+	return "txt"
+}
+
+func (f *TemplateFormatter) Format(data *backlog.ExportData) ([]byte, error) {
+	tmpl, err := f.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *TemplateFormatter) parse() (templateExecutor, error) {
+	source, err := f.source()
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(f.Path)
+	switch f.Extension() {
+	case "html", "htm":
+		tmpl, err := htmltemplate.New(name).Funcs(templateFuncs).Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		return tmpl, nil
+	default:
+		tmpl, err := template.New(name).Funcs(templateFuncs).Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		return tmpl, nil
+	}
+}
+
+func (f *TemplateFormatter) source() (string, error) {
+	if t, ok := builtinTemplates[f.Path]; ok {
+		return t.source, nil
+	}
+	content, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+	return string(content), nil
+}
+
+// templateIssue は walk で平坦化した課題ツリーの1件
+type templateIssue struct {
+	*backlog.HierarchicalIssue
+	// Parent は親課題（ルート課題の場合は nil）
+	Parent *backlog.Issue
+	// Depth は階層の深さ（ルート課題は0）
+	Depth int
+	// Last は兄弟の課題のうち最後のものかどうか
+	Last bool
+}
+
+// templateFuncs はテンプレートで使える関数
+var templateFuncs = template.FuncMap{
+	// 日付・日時
+	"date":       func(v any) string { return formatTemplateTime(v, "2006-01-02") },
+	"datetime":   func(v any) string { return formatTemplateTime(v, "2006-01-02 15:04:05") },
+	"formatTime": func(layout string, v any) string { return formatTemplateTime(v, layout) },
+
+	// 課題の項目（設定されていない場合は空文字列）
+	"statusName": func(issue *backlog.Issue) string {
+		if issue.Status != nil {
+			return issue.Status.Name
+		}
+		return ""
+	},
+	"statusColor": func(issue *backlog.Issue) string {
+		if issue.Status != nil && statusColorPattern.MatchString(issue.Status.Color) {
+			return issue.Status.Color
+		}
+		return defaultStatusColor
+	},
+	"priorityName": func(issue *backlog.Issue) string {
+		if issue.Priority != nil {
+			return issue.Priority.Name
+		}
+		return ""
+	},
+	"issueTypeName": func(issue *backlog.Issue) string {
+		if issue.IssueType != nil {
+			return issue.IssueType.Name
+		}
+		return ""
+	},
+	"userName":     csvUserName,
+	"value":        templateValue,
+	"attributes":   issueAttributes,
+	"customFields": nonEmptyCustomFields,
+	"customField":  findCustomFieldByName,
+
+	// 課題ツリー
+	"walk": walkTemplateIssues,
+	"dict": templateDict,
+
+	// 文字列
+	"indent":     func(depth int) string { return strings.Repeat("  ", max(depth, 0)) },
+	"repeat":     func(s string, n int) string { return strings.Repeat(s, max(n, 0)) },
+	"truncate":   truncateText,
+	"lines":      func(s string) []string { return strings.Split(strings.TrimRight(s, "\n"), "\n") },
+	"join":       strings.Join,
+	"heading":    (&MarkdownFormatter{}).heading,
+	"escapeCell": (&MarkdownFormatter{}).escapeCell,
+	"fileSize":   (&MarkdownFormatter{}).formatSize,
+
+	// 数値
+	"add": func(a, b int) int { return a + b },
+	"sub": func(a, b int) int { return a - b },
+}
+
+// formatTemplateTime は time.Time または日付の文字列（*string を含む）を layout の形式にする
+// 値が設定されていない場合は空文字列を返す
+func formatTemplateTime(v any, layout string) string {
+	switch t := v.(type) {
+	case time.Time:
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	case *time.Time:
+		if t == nil {
+			return ""
+		}
+		return formatTemplateTime(*t, layout)
+	case *string:
+		if t == nil {
+			return ""
+		}
+		return formatTemplateTime(*t, layout)
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			return parsed.Format(layout)
+		}
+		if parsed, err := time.Parse("2006-01-02", csvDate(&t)); err == nil {
+			return parsed.Format(layout)
+		}
+		return t
+	default:
+		return ""
+	}
+}
+
+// templateValue はポインターの値を返す（nil の場合は空文字列）
+func templateValue(v any) any {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return ""
+	}
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		return rv.Elem().Interface()
+	}
+	return v
+}
+
+// truncateText は文字列を n 文字までに切り詰める（切り詰めた場合は末尾に "…" を付ける）
+func truncateText(n int, s string) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	if n <= 0 {
+		return ""
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// templateDict は "キー", 値, ... の引数からマップを作成する（{{template}} に複数の値を渡すため）
+func templateDict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments")
+	}
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// walkTemplateIssues は課題ツリーを親→子の順に平坦化する（Depth で字下げできる）
+func walkTemplateIssues(issues []*backlog.HierarchicalIssue) []templateIssue {
+	var result []templateIssue
+	var walk func(issues []*backlog.HierarchicalIssue, parent *backlog.Issue, depth int)
+	walk = func(issues []*backlog.HierarchicalIssue, parent *backlog.Issue, depth int) {
+		for i, hi := range issues {
+			result = append(result, templateIssue{HierarchicalIssue: hi, Parent: parent, Depth: depth, Last: i == len(issues)-1})
+			walk(hi.Children, hi.Issue, depth+1)
+		}
+	}
+	walk(issues, nil, 0)
+	return result
+}
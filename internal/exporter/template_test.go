@@ -0,0 +1,163 @@
+package exporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+// createTestTemplateExportData はコメント・添付ファイル・カスタム属性・複数の子課題を含む出力データを作成する
+func createTestTemplateExportData() *backlog.ExportData {
+	data := createTestCustomFieldExportData()
+	parent := data.Issues[0]
+	parent.Issue.Milestone = []*backlog.Version{{ID: 1, Name: "v1.0"}}
+	parent.Comments = []*backlog.Comment{
+		{ID: 1, Content: "確認しました\n対応します\n", CreatedUser: &backlog.User{Name: "山田"}, Created: time.Date(2024, 11, 20, 9, 5, 0, 0, time.UTC)},
+	}
+	parent.Attachments = []*backlog.ExportedAttachment{
+		{Attachment: &backlog.Attachment{ID: 1, Name: "設計書.pdf", Size: 2048}, Path: "MYPROJ-100/attachments/設計書.pdf"},
+	}
+
+	memo := "1行目\n2行目"
+	child := parent.Children[0]
+	child.Issue.CustomFields = []*backlog.CustomField{{ID: 2, FieldTypeID: backlog.CustomFieldTextArea, Name: "備考", Text: &memo}}
+	child.Comments = []*backlog.Comment{{ID: 2, Content: "完了", Created: time.Date(2024, 11, 21, 10, 0, 0, 0, time.UTC)}}
+	parent.Children = append(parent.Children, &backlog.HierarchicalIssue{
+		Issue: &backlog.Issue{
+			ID:       102,
+			IssueKey: "MYPROJ-102",
+			Summary:  "子課題2",
+			Category: []*backlog.Category{{ID: 1, Name: "画面"}},
+			Created:  time.Date(2024, 11, 3, 10, 0, 0, 0, time.UTC),
+			Updated:  time.Date(2024, 11, 4, 10, 0, 0, 0, time.UTC),
+		},
+	})
+	data.Summary = backlog.ExportSummary{Total: 4, ParentIssues: 2, ChildIssues: 2}
+	return data
+}
+
+func TestTemplateFormatter_BuiltinMatchesFormatters(t *testing.T) {
+	grouped := createTestTemplateExportData()
+	grouped.GroupBy = []string{config.GroupByPriority, config.GroupByAssignee}
+	grouped.Groups = groupIssues(grouped.Issues, grouped.GroupBy)
+
+	datasets := map[string]*backlog.ExportData{
+		"issues":  createTestTemplateExportData(),
+		"grouped": grouped,
+		"nested":  createTestNestedExportData(),
+		"empty":   {Project: grouped.Project, ExportedAt: grouped.ExportedAt, Issues: []*backlog.HierarchicalIssue{}},
+	}
+	formatters := map[string]Formatter{
+		"txt":      &TXTFormatter{},
+		"markdown": &MarkdownFormatter{},
+	}
+
+	for name, formatter := range formatters {
+		for dataName, data := range datasets {
+			t.Run(name+"/"+dataName, func(t *testing.T) {
+				want, err := formatter.Format(data)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				got, err := (&TemplateFormatter{Path: name}).Format(data)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if string(got) != string(want) {
+					t.Errorf("built-in template differs from %s formatter\n--- template ---\n%s\n--- formatter ---\n%s", name, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestTemplateFormatter_File(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "weekly.md.tmpl")
+	content := `{{range walk .Issues}}{{indent .Depth}}- {{.Issue.IssueKey}} {{truncate 4 .Issue.Summary}} ` +
+		`{{date .Issue.DueDate}} {{statusColor .Issue}}{{with .Parent}} < {{.IssueKey}}{{end}}
+{{end}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFormatterFromConfig(&config.Config{Format: config.FormatTemplate, Template: path})
+	if f.Extension() != "md" {
+		t.Errorf("expected md extension, got %s", f.Extension())
+	}
+	output, err := f.Format(createTestExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "- MYPROJ-100 親課題 2024-12-01 #999999\n" +
+		"  - MYPROJ-101 子課題  #999999 < MYPROJ-100\n" +
+		"- MYPROJ-200 単独タ…  #999999\n"
+	if string(output) != want {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+}
+
+func TestTemplateFormatter_HTMLEscape(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.html.tmpl")
+	if err := os.WriteFile(path, []byte(`{{range .Issues}}<li>{{.Issue.Summary}}</li>{{end}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data := createTestExportData()
+	data.Issues[0].Issue.Summary = "<script>"
+
+	output, err := (&TemplateFormatter{Path: path}).Format(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(output), "<li>&lt;script&gt;</li>") {
+		t.Errorf("expected escaped summary, got %s", output)
+	}
+}
+
+func TestTemplateFormatter_Errors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.tmpl")
+	if err := os.WriteFile(invalid, []byte("{{range .Issues}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		message string
+	}{
+		{invalid, "invalid template"},
+		{filepath.Join(dir, "missing.tmpl"), "failed to read template"},
+	}
+	for _, tt := range tests {
+		if err := ValidateTemplate(tt.path); err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.path, tt.message, err)
+		}
+	}
+
+	if _, err := BuiltinTemplate("html"); err == nil || !strings.Contains(err.Error(), "Available: markdown, txt") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		n    int
+		s    string
+		want string
+	}{
+		{5, "課題の件名", "課題の件名"},
+		{4, "課題の件名", "課題の…"},
+		{0, "abc", ""},
+	}
+	for _, tt := range tests {
+		if got := truncateText(tt.n, tt.s); got != tt.want {
+			t.Errorf("truncateText(%d, %q) = %q, want %q", tt.n, tt.s, got, tt.want)
+		}
+	}
+}
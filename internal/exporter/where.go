@@ -0,0 +1,902 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+// --where の条件式
+//
+//	dueDate < today and estimatedHours == null and priority in ("高", "中")
+//
+// 課題の取得後、親子階層を構築する前に評価して、条件を満たす課題のみを残す
+// 演算子の優先順位は低い順に or、and、not、比較（== != < <= > >= in contains）、+ -
+
+// whereKind は条件式の値の型
+type whereKind int
+
+const (
+	whereNull whereKind = iota
+	whereString
+	whereNumber
+	whereDate
+	whereBool
+)
+
+func (k whereKind) String() string {
+	switch k {
+	case whereString:
+		return "string"
+	case whereNumber:
+		return "number"
+	case whereDate:
+		return "date"
+	case whereBool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+// whereValue は条件式の値
+// 複数の値を持つ項目（カテゴリーなど）は list に値を持ち、いずれかが条件を満たせば真とする
+type whereValue struct {
+	kind whereKind
+	str  string // 文字列、または日付（YYYY-MM-DD）
+	num  float64
+	b    bool
+	list []whereValue
+}
+
+var nullValue = whereValue{}
+
+func stringValue(s string) whereValue  { return whereValue{kind: whereString, str: s} }
+func numberValue(n float64) whereValue { return whereValue{kind: whereNumber, num: n} }
+func boolValue(b bool) whereValue      { return whereValue{kind: whereBool, b: b} }
+func dateValue(d string) whereValue    { return whereValue{kind: whereDate, str: d} }
+
+// values は比較対象の値の一覧を返す（単一の値の場合はその値のみ）
+func (v whereValue) values() []whereValue {
+	if v.list != nil {
+		return v.list
+	}
+	return []whereValue{v}
+}
+
+// whereField は条件式で参照できる課題の項目
+type whereField struct {
+	kind whereKind
+	get  func(issue *backlog.Issue) whereValue
+}
+
+// whereFields は条件式で参照できる項目（parent. を付けると親課題の項目を参照する）
+var whereFields = map[string]whereField{
+	"id":          {whereNumber, func(i *backlog.Issue) whereValue { return numberValue(float64(i.ID)) }},
+	"key":         {whereString, func(i *backlog.Issue) whereValue { return stringValue(i.IssueKey) }},
+	"keyId":       {whereNumber, func(i *backlog.Issue) whereValue { return numberValue(float64(i.KeyID)) }},
+	"summary":     {whereString, func(i *backlog.Issue) whereValue { return stringValue(i.Summary) }},
+	"description": {whereString, func(i *backlog.Issue) whereValue { return stringValue(i.Description) }},
+	"issueType": {whereString, func(i *backlog.Issue) whereValue {
+		return optionalString(i.IssueType != nil, func() string { return i.IssueType.Name })
+	}},
+	"status": {whereString, func(i *backlog.Issue) whereValue {
+		return optionalString(i.Status != nil, func() string { return i.Status.Name })
+	}},
+	"priority": {whereString, func(i *backlog.Issue) whereValue {
+		return optionalString(i.Priority != nil, func() string { return i.Priority.Name })
+	}},
+	"resolution": {whereString, func(i *backlog.Issue) whereValue {
+		return optionalString(i.Resolution != nil, func() string { return i.Resolution.Name })
+	}},
+	"assignee":       {whereString, func(i *backlog.Issue) whereValue { return userValue(i.Assignee) }},
+	"createdUser":    {whereString, func(i *backlog.Issue) whereValue { return userValue(i.CreatedUser) }},
+	"updatedUser":    {whereString, func(i *backlog.Issue) whereValue { return userValue(i.UpdatedUser) }},
+	"startDate":      {whereDate, func(i *backlog.Issue) whereValue { return optionalDate(csvDate(i.StartDate)) }},
+	"dueDate":        {whereDate, func(i *backlog.Issue) whereValue { return optionalDate(csvDate(i.DueDate)) }},
+	"created":        {whereDate, func(i *backlog.Issue) whereValue { return optionalDate(localDate(i.Created)) }},
+	"updated":        {whereDate, func(i *backlog.Issue) whereValue { return optionalDate(localDate(i.Updated)) }},
+	"estimatedHours": {whereNumber, func(i *backlog.Issue) whereValue { return optionalNumber(i.EstimatedHours) }},
+	"actualHours":    {whereNumber, func(i *backlog.Issue) whereValue { return optionalNumber(i.ActualHours) }},
+	"category": {whereString, func(i *backlog.Issue) whereValue {
+		return listValue(len(i.Category), func(n int) string { return i.Category[n].Name })
+	}},
+	"milestone": {whereString, func(i *backlog.Issue) whereValue {
+		return listValue(len(i.Milestone), func(n int) string { return i.Milestone[n].Name })
+	}},
+	"version": {whereString, func(i *backlog.Issue) whereValue {
+		return listValue(len(i.Versions), func(n int) string { return i.Versions[n].Name })
+	}},
+}
+
+// whereParentPrefix は親課題の項目を参照する接頭辞
+const whereParentPrefix = "parent."
+
+func optionalString(ok bool, s func() string) whereValue {
+	if !ok {
+		return nullValue
+	}
+	return stringValue(s())
+}
+
+func userValue(u *backlog.User) whereValue {
+	if u == nil {
+		return nullValue
+	}
+	return stringValue(u.Name)
+}
+
+func optionalDate(d string) whereValue {
+	if d == "" {
+		return nullValue
+	}
+	return dateValue(d)
+}
+
+func optionalNumber(n *float64) whereValue {
+	if n == nil {
+		return nullValue
+	}
+	return numberValue(*n)
+}
+
+// listValue は複数の値を持つ項目の値を作る（値がない場合は null）
+func listValue(n int, name func(int) string) whereValue {
+	if n == 0 {
+		return nullValue
+	}
+	list := make([]whereValue, n)
+	for i := range list {
+		list[i] = stringValue(name(i))
+	}
+	return whereValue{kind: whereString, list: list}
+}
+
+// whereFieldNames は参照できる項目名の一覧を返す
+func whereFieldNames() []string {
+	names := make([]string, 0, len(whereFields))
+	for name := range whereFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WhereError は条件式の誤りと、その位置（1始まりの文字数）を表す
+type WhereError struct {
+	Expr   string
+	Column int
+	Msg    string
+}
+
+func (e *WhereError) Error() string {
+	return fmt.Sprintf("invalid --where expression at column %d: %s\n  %s\n  %s^",
+		e.Column, e.Msg, e.Expr, strings.Repeat(" ", e.Column-1))
+}
+
+// ValidateWhere は条件式を解析して誤りがあれば返す
+func ValidateWhere(expr string) error {
+	_, err := parseWhere(expr)
+	return err
+}
+
+// whereCondition は解析済みの条件式
+type whereCondition struct {
+	root whereNode
+	// parentRefs は parent. で親課題の項目を参照しているかどうか
+	parentRefs bool
+}
+
+// whereEnv は条件式を評価する際の情報
+type whereEnv struct {
+	issue *backlog.Issue
+	// byID は親課題の参照に使う取得済みの課題
+	byID  map[int]*backlog.Issue
+	today string
+}
+
+// filter は条件を満たす課題のみを返す
+// 親課題の項目は issues に含まれる課題から参照する（含まれない場合は null）
+func (c *whereCondition) filter(issues []*backlog.Issue) []*backlog.Issue {
+	if c == nil {
+		return issues
+	}
+
+	env := &whereEnv{
+		byID:  make(map[int]*backlog.Issue, len(issues)),
+		today: time.Now().Format("2006-01-02"),
+	}
+	for _, issue := range issues {
+		env.byID[issue.ID] = issue
+	}
+
+	filtered := make([]*backlog.Issue, 0, len(issues))
+	for _, issue := range issues {
+		env.issue = issue
+		if v := c.root.eval(env); v.kind == whereBool && v.b {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// parseWhere は条件式を解析する（空の場合は nil）
+func parseWhere(expr string) (*whereCondition, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+
+	tokens, err := lexWhere(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &whereParser{expr: expr, tokens: tokens}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorAt(tok, "unexpected %s", tok)
+	}
+	if root.kind() != whereBool {
+		return nil, &WhereError{Expr: expr, Column: 1, Msg: "expression must be a condition (e.g. dueDate < today)"}
+	}
+
+	return &whereCondition{root: root, parentRefs: p.parentRefs}, nil
+}
+
+// --- 字句解析 ---
+
+type whereTokenKind int
+
+const (
+	tokEOF whereTokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type whereToken struct {
+	kind whereTokenKind
+	text string
+	col  int
+}
+
+func (t whereToken) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// keyword は識別子がキーワードかどうかを判定する（大文字小文字を区別しない）
+func (t whereToken) keyword(word string) bool {
+	return t.kind == tokIdent && strings.EqualFold(t.text, word)
+}
+
+func lexWhere(expr string) ([]whereToken, error) {
+	var tokens []whereToken
+	col := 1
+	for i := 0; i < len(expr); {
+		r, size := utf8.DecodeRuneInString(expr[i:])
+		start := col
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			col++
+			continue
+
+		case r == '"' || r == '\'':
+			var sb strings.Builder
+			j := i + size
+			c := col + 1
+			closed := false
+			for j < len(expr) {
+				ch, n := utf8.DecodeRuneInString(expr[j:])
+				j += n
+				c++
+				if ch == r {
+					closed = true
+					break
+				}
+				if ch == '\\' && j < len(expr) {
+					ch, n = utf8.DecodeRuneInString(expr[j:])
+					j += n
+					c++
+				}
+				sb.WriteRune(ch)
+			}
+			if !closed {
+				return nil, &WhereError{Expr: expr, Column: start, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, whereToken{tokString, sb.String(), start})
+			i, col = j, c
+			continue
+
+		case r >= '0' && r <= '9':
+			j := i
+			for j < len(expr) && (expr[j] >= '0' && expr[j] <= '9' || expr[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, whereToken{tokNumber, expr[i:j], start})
+			col += j - i
+			i = j
+			continue
+
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(expr) {
+				ch, n := utf8.DecodeRuneInString(expr[j:])
+				if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && ch != '_' && ch != '.' {
+					break
+				}
+				j += n
+				col++
+			}
+			tokens = append(tokens, whereToken{tokIdent, expr[i:j], start})
+			i = j
+			continue
+		}
+
+		switch r {
+		case '(':
+			tokens = append(tokens, whereToken{tokLParen, "(", start})
+		case ')':
+			tokens = append(tokens, whereToken{tokRParen, ")", start})
+		case ',':
+			tokens = append(tokens, whereToken{tokComma, ",", start})
+		case '+', '-':
+			tokens = append(tokens, whereToken{tokOperator, string(r), start})
+		case '=', '!', '<', '>':
+			op := string(r)
+			if i+1 < len(expr) && expr[i+1] == '=' {
+				op += "="
+			}
+			switch op {
+			case "!":
+				return nil, &WhereError{Expr: expr, Column: start, Msg: `unexpected "!" (use != or not)`}
+			case "=":
+				tokens = append(tokens, whereToken{tokOperator, "==", start})
+			default:
+				tokens = append(tokens, whereToken{tokOperator, op, start})
+			}
+			i += len(op)
+			col += len(op)
+			continue
+		default:
+			return nil, &WhereError{Expr: expr, Column: start, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+		i += size
+		col++
+	}
+	tokens = append(tokens, whereToken{kind: tokEOF, col: col})
+	return tokens, nil
+}
+
+// --- 構文解析 ---
+
+type whereParser struct {
+	expr       string
+	tokens     []whereToken
+	pos        int
+	parentRefs bool
+}
+
+func (p *whereParser) peek() whereToken {
+	return p.tokens[p.pos]
+}
+
+func (p *whereParser) next() whereToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *whereParser) errorAt(tok whereToken, format string, args ...interface{}) error {
+	return &WhereError{Expr: p.expr, Column: tok.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *whereParser) expect(kind whereTokenKind, text string) error {
+	if tok := p.peek(); tok.kind != kind {
+		return p.errorAt(tok, "expected %q, got %s", text, tok)
+	}
+	p.next()
+	return nil
+}
+
+func (p *whereParser) parseOr() (whereNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("or") {
+		op := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkBool(op, left, right); err != nil {
+			return nil, err
+		}
+		left = &whereLogical{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseAnd() (whereNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("and") {
+		op := p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.checkBool(op, left, right); err != nil {
+			return nil, err
+		}
+		left = &whereLogical{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *whereParser) checkBool(op whereToken, operands ...whereNode) error {
+	for _, n := range operands {
+		if n.kind() != whereBool {
+			return p.errorAt(op, "%s requires conditions on both sides", strings.ToLower(op.text))
+		}
+	}
+	return nil
+}
+
+func (p *whereParser) parseNot() (whereNode, error) {
+	if p.peek().keyword("not") {
+		op := p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if operand.kind() != whereBool {
+			return nil, p.errorAt(op, "not requires a condition")
+		}
+		return &whereNot{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *whereParser) parseComparison() (whereNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.kind == tokOperator && tok.text != "+" && tok.text != "-":
+		p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return p.newComparison(tok, tok.text, left, right)
+
+	case tok.keyword("contains"):
+		p.next()
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if left.kind() != whereString || right.kind() != whereString {
+			return nil, p.errorAt(tok, "contains requires strings on both sides")
+		}
+		return &whereContains{left: left, right: right}, nil
+
+	case tok.keyword("in"), tok.keyword("not") && p.tokens[p.pos+1].keyword("in"):
+		negate := tok.keyword("not")
+		p.next()
+		if negate {
+			p.next()
+		}
+		list, err := p.parseList(tok, left)
+		if err != nil {
+			return nil, err
+		}
+		var node whereNode = &whereIn{left: left, list: list}
+		if negate {
+			node = &whereNot{operand: node}
+		}
+		return node, nil
+	}
+
+	return left, nil
+}
+
+// parseList は in の右辺の値の一覧を解析する
+func (p *whereParser) parseList(op whereToken, left whereNode) ([]whereNode, error) {
+	if err := p.expect(tokLParen, "("); err != nil {
+		return nil, err
+	}
+	var list []whereNode
+	for {
+		tok := p.peek()
+		item, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		item, err = p.coerce(tok, left, item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+
+		if p.peek().kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(tokRParen, ")"); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (p *whereParser) newComparison(op whereToken, operator string, left, right whereNode) (whereNode, error) {
+	right, err := p.coerce(op, left, right)
+	if err != nil {
+		return nil, err
+	}
+	left, err = p.coerce(op, right, left)
+	if err != nil {
+		return nil, err
+	}
+
+	if operator == "==" || operator == "!=" {
+		return &whereCompare{op: operator, left: left, right: right}, nil
+	}
+	if left.kind() == whereNull || right.kind() == whereNull {
+		return nil, p.errorAt(op, "cannot compare with null using %s (use == null or != null)", operator)
+	}
+	if left.kind() == whereBool {
+		return nil, p.errorAt(op, "cannot compare conditions using %s", operator)
+	}
+	return &whereCompare{op: operator, left: left, right: right}, nil
+}
+
+// coerce は比較の相手に合わせて値の型を変換する
+// 日付の項目と比較する文字列は日付（YYYY-MM-DD）として解釈する
+func (p *whereParser) coerce(op whereToken, other, node whereNode) (whereNode, error) {
+	if node.kind() == whereNull || other.kind() == whereNull || node.kind() == other.kind() {
+		return node, nil
+	}
+	if lit, ok := node.(*whereLiteral); ok && lit.value.kind == whereString && other.kind() == whereDate {
+		if _, err := time.Parse("2006-01-02", lit.value.str); err != nil {
+			return nil, &WhereError{Expr: p.expr, Column: lit.col, Msg: fmt.Sprintf("invalid date %q (use YYYY-MM-DD)", lit.value.str)}
+		}
+		return &whereLiteral{value: dateValue(lit.value.str), col: lit.col}, nil
+	}
+	if lit, ok := other.(*whereLiteral); ok && lit.value.kind == whereString && node.kind() == whereDate {
+		// 相手側の文字列を日付に変換する
+		return node, nil
+	}
+	return nil, p.errorAt(op, "cannot compare %s with %s", node.kind(), other.kind())
+}
+
+func (p *whereParser) parseAdditive() (whereNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.kind != tokOperator || (tok.text != "+" && tok.text != "-") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case left.kind() == whereNumber && right.kind() == whereNumber,
+			left.kind() == whereDate && right.kind() == whereNumber:
+			left = &whereArith{op: tok.text, left: left, right: right}
+		default:
+			return nil, p.errorAt(tok, "cannot apply %s to %s and %s", tok.text, left.kind(), right.kind())
+		}
+	}
+}
+
+func (p *whereParser) parsePrimary() (whereNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+
+	case tokString:
+		return &whereLiteral{value: stringValue(tok.text), col: tok.col}, nil
+
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorAt(tok, "invalid number %s", tok)
+		}
+		return &whereLiteral{value: numberValue(n), col: tok.col}, nil
+
+	case tokOperator:
+		if tok.text == "-" && p.peek().kind == tokNumber {
+			num := p.next()
+			n, err := strconv.ParseFloat(num.text, 64)
+			if err != nil {
+				return nil, p.errorAt(num, "invalid number %s", num)
+			}
+			return &whereLiteral{value: numberValue(-n), col: tok.col}, nil
+		}
+
+	case tokIdent:
+		switch strings.ToLower(tok.text) {
+		case "null":
+			return &whereLiteral{value: nullValue, col: tok.col}, nil
+		case "true", "false":
+			return &whereLiteral{value: boolValue(strings.EqualFold(tok.text, "true")), col: tok.col}, nil
+		case "today":
+			return &whereToday{}, nil
+		case "and", "or", "not", "in", "contains":
+			return nil, p.errorAt(tok, "unexpected %s", tok)
+		}
+		return p.newField(tok)
+	}
+
+	return nil, p.errorAt(tok, "unexpected %s", tok)
+}
+
+func (p *whereParser) newField(tok whereToken) (whereNode, error) {
+	name, parent := strings.CutPrefix(tok.text, whereParentPrefix)
+	field, ok := whereFields[name]
+	if !ok {
+		return nil, p.errorAt(tok, "unknown field %q. Available fields: %s (prefix with %q for the parent issue)",
+			tok.text, strings.Join(whereFieldNames(), ", "), whereParentPrefix)
+	}
+	p.parentRefs = p.parentRefs || parent
+	return &whereFieldRef{field: field, parent: parent}, nil
+}
+
+// --- 評価 ---
+
+type whereNode interface {
+	kind() whereKind
+	eval(env *whereEnv) whereValue
+}
+
+type whereLiteral struct {
+	value whereValue
+	col   int
+}
+
+func (n *whereLiteral) kind() whereKind               { return n.value.kind }
+func (n *whereLiteral) eval(env *whereEnv) whereValue { return n.value }
+
+type whereToday struct{}
+
+func (n *whereToday) kind() whereKind               { return whereDate }
+func (n *whereToday) eval(env *whereEnv) whereValue { return dateValue(env.today) }
+
+type whereFieldRef struct {
+	field  whereField
+	parent bool
+}
+
+func (n *whereFieldRef) kind() whereKind { return n.field.kind }
+
+func (n *whereFieldRef) eval(env *whereEnv) whereValue {
+	issue := env.issue
+	if n.parent {
+		if issue.ParentIssueID == nil {
+			return nullValue
+		}
+		parent, ok := env.byID[*issue.ParentIssueID]
+		if !ok {
+			return nullValue
+		}
+		issue = parent
+	}
+	return n.field.get(issue)
+}
+
+type whereLogical struct {
+	and         bool
+	left, right whereNode
+}
+
+func (n *whereLogical) kind() whereKind { return whereBool }
+
+func (n *whereLogical) eval(env *whereEnv) whereValue {
+	left := n.left.eval(env).b
+	if n.and && !left {
+		return boolValue(false)
+	}
+	if !n.and && left {
+		return boolValue(true)
+	}
+	return boolValue(n.right.eval(env).b)
+}
+
+type whereNot struct {
+	operand whereNode
+}
+
+func (n *whereNot) kind() whereKind               { return whereBool }
+func (n *whereNot) eval(env *whereEnv) whereValue { return boolValue(!n.operand.eval(env).b) }
+
+type whereCompare struct {
+	op          string
+	left, right whereNode
+}
+
+func (n *whereCompare) kind() whereKind { return whereBool }
+
+// eval は比較を評価する
+// null は == null・!= null でのみ真偽が決まり、大小比較は常に偽になる
+func (n *whereCompare) eval(env *whereEnv) whereValue {
+	left, right := n.left.eval(env), n.right.eval(env)
+
+	if left.kind == whereNull || right.kind == whereNull {
+		equal := left.kind == right.kind
+		switch n.op {
+		case "==":
+			return boolValue(equal)
+		case "!=":
+			return boolValue(!equal)
+		default:
+			return boolValue(false)
+		}
+	}
+
+	if n.op == "!=" {
+		return boolValue(!anyMatch(left, right, func(c int) bool { return c == 0 }))
+	}
+	return boolValue(anyMatch(left, right, func(c int) bool {
+		switch n.op {
+		case "==":
+			return c == 0
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		default:
+			return c >= 0
+		}
+	}))
+}
+
+// anyMatch は複数の値のいずれかの組み合わせが比較の条件を満たすかどうかを判定する
+func anyMatch(left, right whereValue, ok func(int) bool) bool {
+	for _, l := range left.values() {
+		for _, r := range right.values() {
+			if ok(compareValues(l, r)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func compareValues(a, b whereValue) int {
+	switch a.kind {
+	case whereNumber:
+		switch {
+		case a.num < b.num:
+			return -1
+		case a.num > b.num:
+			return 1
+		}
+		return 0
+	case whereBool:
+		if a.b == b.b {
+			return 0
+		}
+		return 1
+	default:
+		return strings.Compare(a.str, b.str)
+	}
+}
+
+type whereIn struct {
+	left whereNode
+	list []whereNode
+}
+
+func (n *whereIn) kind() whereKind { return whereBool }
+
+func (n *whereIn) eval(env *whereEnv) whereValue {
+	left := n.left.eval(env)
+	for _, item := range n.list {
+		right := item.eval(env)
+		if left.kind == whereNull || right.kind == whereNull {
+			if left.kind == right.kind {
+				return boolValue(true)
+			}
+			continue
+		}
+		if anyMatch(left, right, func(c int) bool { return c == 0 }) {
+			return boolValue(true)
+		}
+	}
+	return boolValue(false)
+}
+
+// whereContains は文字列を含むかどうかを判定する（大文字小文字を区別しない）
+type whereContains struct {
+	left, right whereNode
+}
+
+func (n *whereContains) kind() whereKind { return whereBool }
+
+func (n *whereContains) eval(env *whereEnv) whereValue {
+	left, right := n.left.eval(env), n.right.eval(env)
+	if left.kind == whereNull || right.kind == whereNull {
+		return boolValue(false)
+	}
+	needle := strings.ToLower(right.str)
+	for _, v := range left.values() {
+		if strings.Contains(strings.ToLower(v.str), needle) {
+			return boolValue(true)
+		}
+	}
+	return boolValue(false)
+}
+
+// whereArith は数値の加減算と、日付に日数を足し引きする計算
+type whereArith struct {
+	op          string
+	left, right whereNode
+}
+
+func (n *whereArith) kind() whereKind { return n.left.kind() }
+
+func (n *whereArith) eval(env *whereEnv) whereValue {
+	left, right := n.left.eval(env), n.right.eval(env)
+	if left.kind == whereNull || right.kind == whereNull {
+		return nullValue
+	}
+	delta := right.num
+	if n.op == "-" {
+		delta = -delta
+	}
+	if left.kind == whereDate {
+		d, err := time.Parse("2006-01-02", left.str)
+		if err != nil {
+			return nullValue
+		}
+		return dateValue(d.AddDate(0, 0, int(delta)).Format("2006-01-02"))
+	}
+	return numberValue(left.num + delta)
+}
@@ -0,0 +1,169 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func createWhereTestIssues() []*backlog.Issue {
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	estimate := 3.0
+	parentID := 1
+
+	return []*backlog.Issue{
+		{
+			ID:        1,
+			IssueKey:  "MYPROJ-1",
+			Summary:   "ログイン画面",
+			Status:    &backlog.Status{ID: 4, Name: "完了"},
+			Priority:  &backlog.Priority{ID: 2, Name: "高"},
+			DueDate:   &yesterday,
+			Category:  []*backlog.Category{{ID: 1, Name: "フロントエンド"}, {ID: 2, Name: "認証"}},
+			Milestone: []*backlog.Version{{ID: 1, Name: "Sprint 12"}},
+		},
+		{
+			ID:            2,
+			IssueKey:      "MYPROJ-2",
+			Summary:       "ログインAPI",
+			Status:        &backlog.Status{ID: 2, Name: "処理中"},
+			Priority:      &backlog.Priority{ID: 3, Name: "中"},
+			Assignee:      &backlog.User{ID: 1, Name: "山田"},
+			DueDate:       &yesterday,
+			ParentIssueID: &parentID,
+		},
+		{
+			ID:             3,
+			IssueKey:       "MYPROJ-3",
+			Summary:        "ドキュメント",
+			Status:         &backlog.Status{ID: 1, Name: "未対応"},
+			Priority:       &backlog.Priority{ID: 4, Name: "低"},
+			DueDate:        &tomorrow,
+			EstimatedHours: &estimate,
+		},
+	}
+}
+
+func TestWhereCondition_Filter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`dueDate < today and estimatedHours == null and priority in ("高","中")`, "MYPROJ-1,MYPROJ-2"},
+		{`parent.status == "完了"`, "MYPROJ-2"},
+		{`parent.status == null`, "MYPROJ-1,MYPROJ-3"},
+		{`assignee != null or estimatedHours >= 3`, "MYPROJ-2,MYPROJ-3"},
+		{`not (summary contains "ログイン")`, "MYPROJ-3"},
+		{`category == "認証"`, "MYPROJ-1"},
+		{`category != "認証"`, "MYPROJ-2,MYPROJ-3"},
+		{`milestone == null`, "MYPROJ-2,MYPROJ-3"},
+		{`dueDate <= today + 1 and dueDate > today - 7`, "MYPROJ-1,MYPROJ-2,MYPROJ-3"},
+		{`dueDate >= "2000-01-01" AND status NOT IN ("完了", '処理中')`, "MYPROJ-3"},
+		{`keyId = 0 and estimatedHours + 1 > 3.5`, "MYPROJ-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cond, err := parseWhere(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var keys []string
+			for _, issue := range cond.filter(createWhereTestIssues()) {
+				keys = append(keys, issue.IssueKey)
+			}
+			if got := strings.Join(keys, ","); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseWhere_Errors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		column  int
+		message string
+	}{
+		{`dueDate < and`, 11, `unexpected "and"`},
+		{`priority in ("高", "中"`, 22, `expected ")"`},
+		{`duedate < today`, 1, `unknown field "duedate"`},
+		{`dueDate < "12/01"`, 11, `invalid date "12/01"`},
+		{`summary > 3`, 9, "cannot compare"},
+		{`estimatedHours < null`, 16, "cannot compare with null"},
+		{`summary == "abc`, 12, "unterminated string"},
+		{`summary`, 1, "must be a condition"},
+		{`status == "完了" and`, 19, "unexpected end of expression"},
+		{`status ! "完了"`, 8, `unexpected "!"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parseWhere(tt.expr)
+			var whereErr *WhereError
+			if !errors.As(err, &whereErr) {
+				t.Fatalf("expected WhereError, got %v", err)
+			}
+			if whereErr.Column != tt.column {
+				t.Errorf("expected column %d, got %d (%v)", tt.column, whereErr.Column, err)
+			}
+			if !strings.Contains(whereErr.Msg, tt.message) {
+				t.Errorf("expected message containing %q, got %q", tt.message, whereErr.Msg)
+			}
+		})
+	}
+}
+
+func TestWhereError_PointsAtColumn(t *testing.T) {
+	err := ValidateWhere(`dueDate < and`)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 3 || lines[2] != "  "+strings.Repeat(" ", 10)+"^" {
+		t.Errorf("caret should point at the offending column:\n%s", err)
+	}
+}
+
+func TestExporter_Run_Where(t *testing.T) {
+	project, statuses, issues := createTestData()
+	mockClient := &backlog.MockClient{
+		GetProjectFunc: func(ctx context.Context, projectIDOrKey string) (*backlog.Project, error) {
+			return project, nil
+		},
+		GetStatusesFunc: func(ctx context.Context, projectIDOrKey string) ([]*backlog.Status, error) {
+			return statuses, nil
+		},
+		GetIssuesFunc: func(ctx context.Context, query backlog.IssueQuery, progressFn func(fetched, total int)) ([]*backlog.Issue, error) {
+			return issues, nil
+		},
+	}
+
+	cfg := &config.Config{
+		Project: "MYPROJ",
+		Output:  t.TempDir(),
+		Format:  config.FormatJSON,
+		Where:   `parent.key == "MYPROJ-100" or assignee == null`,
+	}
+	exp := NewExporterWithOutput(mockClient, cfg, &testOutput{})
+	content, err := exp.Render(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result := string(content)
+	if strings.Contains(result, `"MYPROJ-100"`) {
+		t.Error("parent issue should be filtered out")
+	}
+	for _, key := range []string{"MYPROJ-101", "MYPROJ-200"} {
+		if !strings.Contains(result, key) {
+			t.Errorf("expected output to contain %s", key)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package exporter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/miyanaga/backlog-exporter/internal/backlog"
+)
+
+const (
+	xlsxSummarySheet = "サマリー"
+	xlsxIssuesSheet  = "課題一覧"
+	// xlsxMaxSheetName はExcelのシート名の最大文字数
+	xlsxMaxSheetName = 31
+	// xlsxMaxOutlineLevel はExcelのアウトラインの最大レベル
+	xlsxMaxOutlineLevel = 7
+)
+
+// xlsxColumns は課題シートの列見出し
+var xlsxColumns = []string{
+	"キー", "親課題キー", "件名", "種別", "状態", "優先度", "担当者",
+	"開始日", "期限日", "予定時間", "実績時間", "作成日", "更新日",
+}
+
+// xlsxDueDateColumn は期限超過の判定に使う期限日の列
+const xlsxDueDateColumn = "I"
+
+// ============================================
+// XLSX Formatter
+// ============================================
+
+// XLSXFormatter はExcelブック形式のフォーマッター
+// サマリーシートと課題シートを作成し、子課題はアウトライン（グループ化）で親課題の下にまとめる
+type XLSXFormatter struct{}
+
+func (f *XLSXFormatter) Extension() string {
+	return "xlsx"
+}
+
+func (f *XLSXFormatter) Format(data *backlog.ExportData) ([]byte, error) {
+	book := excelize.NewFile()
+	defer book.Close()
+
+	styles, err := f.newStyles(book)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := book.SetSheetName("Sheet1", xlsxSummarySheet); err != nil {
+		return nil, err
+	}
+	rows := [][]interface{}{
+		{"プロジェクト", fmt.Sprintf("%s - %s", data.Project.ProjectKey, data.Project.Name)},
+		{"取得日時", data.ExportedAt},
+		{"未完了タスク数", data.Summary.Total},
+		{"親課題", data.Summary.ParentIssues},
+		{"子課題", data.Summary.ChildIssues},
+	}
+	if err := f.writeSummary(book, styles, rows); err != nil {
+		return nil, err
+	}
+
+	if _, err := book.NewSheet(xlsxIssuesSheet); err != nil {
+		return nil, err
+	}
+	if err := f.writeIssues(book, styles, xlsxIssuesSheet, data.Issues); err != nil {
+		return nil, err
+	}
+
+	return f.save(book)
+}
+
+// FormatMulti は複数プロジェクトを1つのブックにまとめる（プロジェクトごとに課題シートを作成）
+func (f *XLSXFormatter) FormatMulti(data *backlog.MultiExportData) ([]byte, error) {
+	book := excelize.NewFile()
+	defer book.Close()
+
+	styles, err := f.newStyles(book)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := book.SetSheetName("Sheet1", xlsxSummarySheet); err != nil {
+		return nil, err
+	}
+	rows := [][]interface{}{
+		{"取得日時", data.ExportedAt},
+		{"未完了タスク数", data.Summary.Total},
+		{"親課題", data.Summary.ParentIssues},
+		{"子課題", data.Summary.ChildIssues},
+		{},
+		{"プロジェクト", "名前", "未完了タスク数", "親課題", "子課題"},
+	}
+	for _, p := range data.Projects {
+		rows = append(rows, []interface{}{
+			p.Project.ProjectKey, p.Project.Name, p.Summary.Total, p.Summary.ParentIssues, p.Summary.ChildIssues,
+		})
+	}
+	if err := f.writeSummary(book, styles, rows); err != nil {
+		return nil, err
+	}
+
+	for _, p := range data.Projects {
+		sheet := f.sheetName(p.Project.ProjectKey)
+		if _, err := book.NewSheet(sheet); err != nil {
+			return nil, err
+		}
+		if err := f.writeIssues(book, styles, sheet, p.Issues); err != nil {
+			return nil, err
+		}
+	}
+
+	return f.save(book)
+}
+
+// xlsxStyles はブック内で使うスタイルID
+type xlsxStyles struct {
+	header   int
+	date     int
+	datetime int
+	overdue  int
+	// context は取得条件外の親課題（--with-parents）の行の文字列の列
+	context int
+}
+
+func (f *XLSXFormatter) newStyles(book *excelize.File) (*xlsxStyles, error) {
+	var (
+		s   xlsxStyles
+		err error
+	)
+
+	s.header, err = book.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDEBF7"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dateFormat := "yyyy-mm-dd"
+	s.date, err = book.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return nil, err
+	}
+
+	datetimeFormat := "yyyy-mm-dd hh:mm"
+	s.datetime, err = book.NewStyle(&excelize.Style{CustomNumFmt: &datetimeFormat})
+	if err != nil {
+		return nil, err
+	}
+
+	s.context, err = book.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "999999", Italic: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	s.overdue, err = book.NewConditionalStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "9C0006"},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"FFC7CE"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// writeSummary はサマリーシートに行を書き込む
+func (f *XLSXFormatter) writeSummary(book *excelize.File, styles *xlsxStyles, rows [][]interface{}) error {
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		if err := book.SetSheetRow(xlsxSummarySheet, cell, &row); err != nil {
+			return err
+		}
+
+		for j, v := range row {
+			if _, ok := v.(time.Time); ok {
+				cell, _ := excelize.CoordinatesToCellName(j+1, i+1)
+				if err := book.SetCellStyle(xlsxSummarySheet, cell, cell, styles.datetime); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := book.SetCellStyle(xlsxSummarySheet, "A1", fmt.Sprintf("A%d", len(rows)), styles.header); err != nil {
+		return err
+	}
+	return book.SetColWidth(xlsxSummarySheet, "A", "E", 18)
+}
+
+// writeIssues は課題シートを作成する
+// 課題にカスタム属性がある場合は、固定の列に続けてカスタム属性ごとの列を追加する
+func (f *XLSXFormatter) writeIssues(book *excelize.File, styles *xlsxStyles, sheet string, issues []*backlog.HierarchicalIssue) error {
+	fields := customFieldColumns(issues)
+	lastCol, err := excelize.ColumnNumberToName(len(xlsxColumns) + len(fields))
+	if err != nil {
+		return err
+	}
+
+	// ヘッダー行
+	header := make([]interface{}, 0, len(xlsxColumns)+len(fields))
+	for _, c := range xlsxColumns {
+		header = append(header, c)
+	}
+	for _, c := range fields {
+		header = append(header, c.Name)
+	}
+	if err := book.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+	if err := book.SetCellStyle(sheet, "A1", lastCol+"1", styles.header); err != nil {
+		return err
+	}
+
+	// 課題行（親→子の順）
+	row := 2
+	var walk func(issues []*backlog.HierarchicalIssue, parentKey string, depth int) error
+	walk = func(issues []*backlog.HierarchicalIssue, parentKey string, depth int) error {
+		for _, hi := range issues {
+			if err := f.writeIssueRow(book, styles, sheet, row, hi, parentKey, fields); err != nil {
+				return err
+			}
+			if depth > 0 {
+				level := depth
+				if level > xlsxMaxOutlineLevel {
+					level = xlsxMaxOutlineLevel
+				}
+				if err := book.SetRowOutlineLevel(sheet, row, uint8(level)); err != nil {
+					return err
+				}
+			}
+			row++
+
+			if err := walk(hi.Children, hi.Issue.IssueKey, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(issues, "", 0); err != nil {
+		return err
+	}
+	lastRow := row - 1
+
+	// 親課題を子課題の上に置くため、アウトラインの集計行を上側にする
+	summaryBelow := false
+	if err := book.SetSheetProps(sheet, &excelize.SheetPropsOptions{OutlineSummaryBelow: &summaryBelow}); err != nil {
+		return err
+	}
+
+	// ヘッダー行を固定
+	if err := book.SetPanes(sheet, &excelize.Panes{
+		Freeze:      true,
+		YSplit:      1,
+		TopLeftCell: "A2",
+		ActivePane:  "bottomLeft",
+	}); err != nil {
+		return err
+	}
+
+	if err := book.AutoFilter(sheet, fmt.Sprintf("A1:%s%d", lastCol, lastRow), nil); err != nil {
+		return err
+	}
+
+	// 期限超過の課題を強調表示
+	if lastRow >= 2 {
+		rangeRef := fmt.Sprintf("A2:%s%d", lastCol, lastRow)
+		formula := fmt.Sprintf("AND($%s2<>\"\",$%s2<TODAY())", xlsxDueDateColumn, xlsxDueDateColumn)
+		if err := book.SetConditionalFormat(sheet, rangeRef, []excelize.ConditionalFormatOptions{
+			{Type: "formula", Criteria: formula, Format: &styles.overdue},
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := book.SetColWidth(sheet, "A", "B", 14); err != nil {
+		return err
+	}
+	if err := book.SetColWidth(sheet, "C", "C", 48); err != nil {
+		return err
+	}
+	return book.SetColWidth(sheet, "D", lastCol, 12)
+}
+
+// writeIssueRow は課題1件分の行を書き込む
+func (f *XLSXFormatter) writeIssueRow(book *excelize.File, styles *xlsxStyles, sheet string, row int, hi *backlog.HierarchicalIssue, parentKey string, fields []customFieldColumn) error {
+	issue := hi.Issue
+	values := []interface{}{
+		issue.IssueKey,
+		parentKey,
+		issue.Summary,
+		f.getIssueTypeName(issue),
+		f.getStatusName(issue),
+		f.getPriorityName(issue),
+		f.getUserName(issue.Assignee),
+		f.parseDate(issue.StartDate),
+		f.parseDate(issue.DueDate),
+		f.getHours(issue.EstimatedHours),
+		f.getHours(issue.ActualHours),
+		issue.Created,
+		issue.Updated,
+	}
+	for _, c := range fields {
+		values = append(values, f.getCustomFieldValue(findCustomFieldByName(issue, c.Name)))
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, row)
+	if err != nil {
+		return err
+	}
+	if err := book.SetSheetRow(sheet, cell, &values); err != nil {
+		return err
+	}
+
+	// 取得条件外の親課題は文字列の列（キー〜担当者）を灰色にする
+	if hi.ContextOnly {
+		if err := book.SetCellStyle(sheet, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), styles.context); err != nil {
+			return err
+		}
+	}
+
+	// 日付列（開始日・期限日）と日時列（作成日・更新日）の表示形式
+	if err := book.SetCellStyle(sheet, fmt.Sprintf("H%d", row), fmt.Sprintf("I%d", row), styles.date); err != nil {
+		return err
+	}
+	if err := book.SetCellStyle(sheet, fmt.Sprintf("L%d", row), fmt.Sprintf("M%d", row), styles.datetime); err != nil {
+		return err
+	}
+
+	// 日付のカスタム属性の表示形式
+	for i, c := range fields {
+		if c.Type != backlog.CustomFieldDate {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(len(xlsxColumns)+i+1, row)
+		if err != nil {
+			return err
+		}
+		if err := book.SetCellStyle(sheet, cell, cell, styles.date); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *XLSXFormatter) save(book *excelize.File) ([]byte, error) {
+	book.SetActiveSheet(0)
+
+	buf, err := book.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sheetName はシート名として使える文字列に変換する
+func (f *XLSXFormatter) sheetName(name string) string {
+	runes := []rune(name)
+	if len(runes) > xlsxMaxSheetName {
+		runes = runes[:xlsxMaxSheetName]
+	}
+	for i, r := range runes {
+		switch r {
+		case ':', '\\', '/', '?', '*', '[', ']':
+			runes[i] = '_'
+		}
+	}
+	return string(runes)
+}
+
+// parseDate はBacklogの日付文字列を日付セルの値に変換する（空の場合は空セル）
+func (f *XLSXFormatter) parseDate(d *string) interface{} {
+	if d == nil || len(*d) < len("2006-01-02") {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", (*d)[:len("2006-01-02")])
+	if err != nil {
+		return *d
+	}
+	return t
+}
+
+func (f *XLSXFormatter) getHours(h *float64) interface{} {
+	if h != nil {
+		return *h
+	}
+	return nil
+}
+
+// getCustomFieldValue はカスタム属性をセルの値に変換する（数値は数値、日付は日付のセルにする）
+func (f *XLSXFormatter) getCustomFieldValue(field *backlog.CustomField) interface{} {
+	switch {
+	case field == nil || field.IsEmpty():
+		return nil
+	case field.Number != nil:
+		return *field.Number
+	case field.Date != nil:
+		return f.parseDate(field.Date)
+	}
+	return field.String()
+}
+
+func (f *XLSXFormatter) getIssueTypeName(issue *backlog.Issue) string {
+	if issue.IssueType != nil {
+		return issue.IssueType.Name
+	}
+	return ""
+}
+
+func (f *XLSXFormatter) getStatusName(issue *backlog.Issue) string {
+	if issue.Status != nil {
+		return issue.Status.Name
+	}
+	return ""
+}
+
+func (f *XLSXFormatter) getPriorityName(issue *backlog.Issue) string {
+	if issue.Priority != nil {
+		return issue.Priority.Name
+	}
+	return ""
+}
+
+func (f *XLSXFormatter) getUserName(u *backlog.User) string {
+	if u != nil {
+		return u.Name
+	}
+	return ""
+}
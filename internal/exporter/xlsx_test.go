@@ -0,0 +1,102 @@
+package exporter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/miyanaga/backlog-exporter/internal/config"
+)
+
+func TestXLSXFormatter_Format(t *testing.T) {
+	f := NewFormatter(config.FormatXLSX)
+	if f.Extension() != "xlsx" {
+		t.Errorf("expected extension xlsx, got %s", f.Extension())
+	}
+
+	output, err := f.Format(createTestExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	book, err := excelize.OpenReader(bytes.NewReader(output))
+	if err != nil {
+		t.Fatalf("invalid workbook: %v", err)
+	}
+	defer book.Close()
+
+	sheets := book.GetSheetList()
+	if len(sheets) != 2 || sheets[0] != xlsxSummarySheet || sheets[1] != xlsxIssuesSheet {
+		t.Fatalf("unexpected sheets: %v", sheets)
+	}
+
+	// サマリー
+	total, _ := book.GetCellValue(xlsxSummarySheet, "B3")
+	if total != "3" {
+		t.Errorf("expected total 3 in summary, got %q", total)
+	}
+
+	// ヘッダーと親子関係
+	key, _ := book.GetCellValue(xlsxIssuesSheet, "A1")
+	if key != "キー" {
+		t.Errorf("unexpected header: %q", key)
+	}
+	childKey, _ := book.GetCellValue(xlsxIssuesSheet, "A3")
+	parentKey, _ := book.GetCellValue(xlsxIssuesSheet, "B3")
+	if childKey != "MYPROJ-101" || parentKey != "MYPROJ-100" {
+		t.Errorf("expected child row after parent, got %q (parent %q)", childKey, parentKey)
+	}
+
+	// 子課題はアウトラインレベル1
+	if level, _ := book.GetRowOutlineLevel(xlsxIssuesSheet, 3); level != 1 {
+		t.Errorf("expected child outline level 1, got %d", level)
+	}
+	if level, _ := book.GetRowOutlineLevel(xlsxIssuesSheet, 2); level != 0 {
+		t.Errorf("expected parent outline level 0, got %d", level)
+	}
+
+	// 期限日は日付型のセル
+	cellType, _ := book.GetCellType(xlsxIssuesSheet, "I2")
+	if cellType == excelize.CellTypeSharedString || cellType == excelize.CellTypeInlineString {
+		t.Errorf("due date should be stored as a date, got type %v", cellType)
+	}
+	dueDate, _ := book.GetCellValue(xlsxIssuesSheet, "I2")
+	if dueDate != "2024-12-01" {
+		t.Errorf("expected formatted due date 2024-12-01, got %q", dueDate)
+	}
+
+	// ヘッダー固定と期限超過の条件付き書式
+	panes, err := book.GetPanes(xlsxIssuesSheet)
+	if err != nil || !panes.Freeze || panes.YSplit != 1 {
+		t.Errorf("header row should be frozen: %+v (%v)", panes, err)
+	}
+	formats, _ := book.GetConditionalFormats(xlsxIssuesSheet)
+	if len(formats) == 0 {
+		t.Error("overdue conditional format should be set")
+	}
+}
+
+func TestXLSXFormatter_FormatMulti(t *testing.T) {
+	output, err := (&XLSXFormatter{}).FormatMulti(createTestMultiExportData())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	book, err := excelize.OpenReader(bytes.NewReader(output))
+	if err != nil {
+		t.Fatalf("invalid workbook: %v", err)
+	}
+	defer book.Close()
+
+	sheets := book.GetSheetList()
+	expected := []string{xlsxSummarySheet, "MYPROJ", "OTHER"}
+	if len(sheets) != len(expected) {
+		t.Fatalf("expected sheets %v, got %v", expected, sheets)
+	}
+	for i := range expected {
+		if sheets[i] != expected[i] {
+			t.Errorf("expected sheet %d to be %s, got %s", i, expected[i], sheets[i])
+		}
+	}
+}